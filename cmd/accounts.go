@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/wux1an/wxapkg/util"
+)
+
+// scanAccounts scans the mini programs in the applet roots of the accounts, every mini program is saved to
+// '<account>/<wxid>/', or '<wxid>/' for the shared roots. The broken mini programs are skipped with warnings
+// so that the others are still unpacked.
+func scanAccounts(roots []util.AppletRoot, layout string, filter util.VersionFilter) ([]packageGroup, error) {
+	var groups []packageGroup
+	for _, root := range roots {
+		entries, err := os.ReadDir(root.Path)
+		if err != nil {
+			return nil, util.WithExitCode(util.ExitIO, err)
+		}
+
+		// the package paths are labeled by the account too, like '<account>/Applet/wx.../12/__APP__.wxapkg'
+		var base = filepath.Dir(root.Path)
+		if root.Account != "" {
+			base = filepath.Dir(base)
+		}
+
+		var count = 0
+		for _, e := range entries {
+			if !e.IsDir() || !regAppId.MatchString(e.Name()) {
+				continue
+			}
+
+			found, err := scanVersions(filepath.Join(root.Path, e.Name()), layout, filter)
+			if err != nil {
+				color.Yellow(util.T("[!] skip '%s': %v\n"), filepath.Join(root.Path, e.Name()), err)
+				continue
+			}
+			for _, group := range found {
+				group.output = path.Join(root.Account, e.Name(), group.output)
+				group.base = base
+				groups = append(groups, group)
+			}
+			count++
+		}
+
+		if root.Account == "" {
+			color.Cyan(util.T("[+] found %d mini programs in '%s'\n"), count, root.Path)
+		} else {
+			color.Cyan(util.T("[+] found %d mini programs of the account '%s' in '%s'\n"), count, root.Account, root.Path)
+		}
+	}
+
+	return groups, nil
+}
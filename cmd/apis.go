@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var apisCmd = &cobra.Command{
+	Use:     "apis",
+	Short:   util.T("Map the pages of the unpacked mini program to the wx APIs and urls they use"),
+	Example: "  " + programName + " apis -o unpack",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		for _, dir := range dirs {
+			pages, err := pageApis(dir)
+			util.Fatal(err)
+
+			var path = filepath.Join(dir, "apis.json")
+			util.Fatal(util.SaveJson(path, pages))
+
+			for _, p := range pages {
+				color.Yellow("[+] %s\n", p.Page)
+				color.Cyan(util.T("  - apis: %s\n"), strings.Join(p.Apis, ", "))
+				color.Cyan(util.T("  - urls: %s\n"), strings.Join(p.Urls, ", "))
+			}
+			color.Cyan(util.T("[+] %d pages saved to '%s'\n"), len(pages), path)
+		}
+	},
+}
+
+type pageApi struct {
+	Page string   `json:"page"`
+	Apis []string `json:"apis"`
+	Urls []string `json:"urls"`
+}
+
+// pageApis collects the APIs and urls used by every page, including the modules it requires.
+func pageApis(dir string) ([]pageApi, error) {
+	modules, err := util.LoadModules(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := util.ReadAppConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var byName = make(map[string]util.Module)
+	for _, m := range modules {
+		byName[m.Name] = m
+	}
+
+	var result []pageApi
+	for _, m := range modules {
+		if !config.IsPage(m.Name) {
+			continue
+		}
+
+		var page = pageApi{Page: strings.TrimSuffix(m.Name, ".js")}
+		var visited = make(map[string]bool)
+		var visit func(name string)
+		visit = func(name string) {
+			dep, ok := byName[name]
+			if visited[name] || !ok {
+				return
+			}
+			visited[name] = true
+
+			page.Apis = append(page.Apis, util.FindApis(dep.Code)...)
+			page.Urls = append(page.Urls, util.FindUrls(dep.Code)...)
+			for _, r := range dep.Requires() {
+				visit(r)
+			}
+		}
+		visit(m.Name)
+
+		page.Apis = util.UniqueSorted(page.Apis)
+		page.Urls = util.UniqueSorted(page.Urls)
+		result = append(result, page)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Page < result[j].Page
+	})
+
+	return result, nil
+}
+
+func init() {
+	RootCmd.AddCommand(apisCmd)
+
+	apisCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var auditCmd = &cobra.Command{
+	Use:     "audit",
+	Short:   util.T("Check the unpacked mini programs for the risky code and the leaked credentials, like eval and the app secrets"),
+	Example: "  " + programName + " audit -o unpack",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		var total = 0
+		for _, dir := range dirs {
+			findings, err := auditDir(dir)
+			util.Fatal(err)
+
+			var path = filepath.Join(dir, "findings.json")
+			util.Fatal(util.SaveJson(path, findings))
+
+			color.Yellow("[+] %s\n", dir)
+			var credentials = 0
+			for _, f := range findings {
+				if f.Category == util.CategoryCredential {
+					credentials++
+				}
+			}
+			if credentials > 0 {
+				color.Red(util.T("[!] %d credentials found, check them first\n"), credentials)
+			}
+			for _, f := range findings {
+				var printf = color.Cyan
+				if f.Severity == util.SeverityHigh {
+					printf = color.Red
+				}
+				printf("  - %-6s %-19s %s:%d\n", f.Severity, f.Rule, f.Path, f.Line)
+				color.White("      %s\n", f.Text)
+			}
+			color.Cyan(util.T("[+] %d findings saved to '%s'\n"), len(findings), path)
+			total += len(findings)
+		}
+
+		color.Cyan(util.T("[+] %d findings in %d unpacked folders\n"), total, len(dirs))
+	},
+}
+
+// the severities from high to low
+var severityOrder = map[string]int{util.SeverityHigh: 0, util.SeverityMedium: 1, util.SeverityLow: 2}
+
+// auditDir checks the files in the unpacked folder, the findings are sorted by the severities and the locations,
+// the credentials come first.
+func auditDir(dir string) ([]util.Finding, error) {
+	manifest, err := util.ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = []util.Finding{}
+	for _, f := range manifest.Files {
+		// the known libraries are not the code of the mini program
+		if f.Library != "" {
+			continue
+		}
+
+		var ext = strings.ToLower(filepath.Ext(f.Path))
+		if ext != ".js" && ext != ".wxs" && ext != ".html" && ext != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, util.FindCredentials(f.Path, data)...)
+		if ext == ".json" && util.IsConfigFile(f.Path) {
+			result = append(result, util.FindConfigCredentials(f.Path, data)...)
+		} else if ext != ".json" {
+			result = append(result, util.FindDynamicCode(f.Path, data)...)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		var a, b = result[i], result[j]
+		if (a.Category == util.CategoryCredential) != (b.Category == util.CategoryCredential) {
+			return a.Category == util.CategoryCredential
+		}
+		if a.Severity != b.Severity {
+			return severityOrder[a.Severity] < severityOrder[b.Severity]
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+
+	return result, nil
+}
+
+func init() {
+	RootCmd.AddCommand(auditCmd)
+
+	auditCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+}
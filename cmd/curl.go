@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var curlCmd = &cobra.Command{
+	Use:     "curl",
+	Short:   util.T("Generate the shell script of the curl commands of the requests found in the unpacked mini program"),
+	Example: "  " + programName + " curl -o unpack --base-url https://api.example.com",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		baseUrl, _ := cmd.Flags().GetString("base-url")
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		for _, dir := range dirs {
+			modules, err := util.LoadModules(dir)
+			util.Fatal(err)
+
+			var script = util.NewCurlScript(baseUrl)
+			for _, m := range modules {
+				for _, call := range util.FindRequests(m.Code) {
+					script.AddRequest(call, fmt.Sprintf("%s:%d", m.Name, call.Line))
+				}
+			}
+
+			var path = filepath.Join(dir, "curl.sh")
+			util.Fatal(os.WriteFile(path, script.Script(), 0700))
+			color.Cyan(util.T("[+] %d curl commands of '%s' saved to '%s'\n"), script.Len(), dir, path)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(curlCmd)
+
+	curlCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	curlCmd.Flags().String("base-url", "https://example.com", util.T("the base url of the requests whose host is dynamic, like 'https://api.example.com'"))
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var diffdirCmd = &cobra.Command{
+	Use:     "diffdir <old> <new>",
+	Short:   util.T("Compare two unpacked folders, show the added, removed and modified files"),
+	Example: "  " + programName + " diffdir unpack/wx1234567890abcdef/1 unpack/wx1234567890abcdef/2",
+	Args:    cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		stat, _ := cmd.Flags().GetBool("stat")
+
+		var oldDir, newDir = args[0], args[1]
+		oldFiles, err := listFiles(oldDir)
+		util.Fatal(err)
+		newFiles, err := listFiles(newDir)
+		util.Fatal(err)
+
+		var added, removed, modified []string
+		for name := range newFiles {
+			if _, ok := oldFiles[name]; !ok {
+				added = append(added, name)
+			}
+		}
+		for name := range oldFiles {
+			if _, ok := newFiles[name]; !ok {
+				removed = append(removed, name)
+				continue
+			}
+
+			same, err := sameFile(filepath.Join(oldDir, oldFiles[name]), filepath.Join(newDir, newFiles[name]))
+			util.Fatal(err)
+			if !same {
+				modified = append(modified, name)
+			}
+		}
+		sort.Strings(added)
+		sort.Strings(removed)
+		sort.Strings(modified)
+
+		for _, name := range added {
+			color.Green("A %s", name)
+		}
+		for _, name := range removed {
+			color.Red("D %s", name)
+		}
+		for _, name := range modified {
+			color.Yellow("M %s", name)
+		}
+
+		if !stat {
+			for _, name := range modified {
+				oldData, err := os.ReadFile(filepath.Join(oldDir, oldFiles[name]))
+				util.Fatal(err)
+				newData, err := os.ReadFile(filepath.Join(newDir, newFiles[name]))
+				util.Fatal(err)
+
+				if !util.IsText(oldData) || !util.IsText(newData) {
+					fmt.Printf(util.T("Binary files a/%[1]s and b/%[1]s differ\n"), name)
+					continue
+				}
+				fmt.Print(util.UnifiedDiff("a/"+name, "b/"+name, oldData, newData))
+			}
+		}
+
+		color.Cyan(util.T("[+] %d added, %d removed, %d modified\n"), len(added), len(removed), len(modified))
+	},
+}
+
+// listFiles lists the files in the unpacked folder, slash path -> os path relative to dir.
+// The manifest, the completion marker and the lock file are excluded because they always differ.
+func listFiles(dir string) (map[string]string, error) {
+	paths, err := util.GetDirAllFilePaths(dir, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	var files = make(map[string]string, len(paths))
+	for _, path := range paths {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil, err
+		}
+		if rel == util.ManifestName || rel == util.MarkerName || rel == util.LockName || rel == util.IndexName ||
+			rel == util.AppInfoName || rel == util.StateName || rel == util.RunName {
+			continue
+		}
+		files[filepath.ToSlash(rel)] = rel
+	}
+
+	return files, nil
+}
+
+func sameFile(a, b string) (bool, error) {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	if infoA.Size() != infoB.Size() {
+		return false, nil
+	}
+
+	dataA, err := os.ReadFile(a)
+	if err != nil {
+		return false, err
+	}
+	dataB, err := os.ReadFile(b)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(dataA, dataB), nil
+}
+
+func init() {
+	RootCmd.AddCommand(diffdirCmd)
+
+	diffdirCmd.Flags().Bool("stat", false, util.T("only list the changed files, without the text diffs"))
+}
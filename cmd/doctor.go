@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+	"github.com/wux1an/wxapkg/wxapkg"
+)
+
+// the packages sampled to check the decryption profile
+const doctorSamples = 20
+
+var doctorCmd = &cobra.Command{
+	Use:     "doctor",
+	Short:   util.T("Check the environment, like the WeChat client, the applet folders, the permissions, the disk space and the decryption profile"),
+	Example: "  " + programName + " doctor -o unpack",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		var d doctor
+		var client, found = d.checkClient()
+		var packages = d.checkRoots()
+		d.checkOutput(output, packages)
+		d.checkProfiles(client, found, packages)
+
+		if d.problems > 0 {
+			util.Fatal(fmt.Errorf(util.T("%d problems found, see the advice above"), d.problems))
+		}
+		color.Cyan(util.T("[+] no problem found\n"))
+	},
+}
+
+// doctor prints the results of the checks, the problems come with the advice.
+type doctor struct {
+	problems int
+}
+
+func (d *doctor) ok(format string, a ...interface{}) {
+	color.Green("[+] "+format+"\n", a...)
+}
+
+func (d *doctor) warn(advice, format string, a ...interface{}) {
+	color.Yellow("[!] "+format+"\n", a...)
+	color.White("    -> %s\n", advice)
+}
+
+func (d *doctor) fail(advice, format string, a ...interface{}) {
+	d.problems++
+	color.Red("[-] "+format+"\n", a...)
+	color.White("    -> %s\n", advice)
+}
+
+func (d *doctor) checkClient() (util.WechatClient, bool) {
+	client, found := util.FindWechatClient()
+	switch {
+	case !found:
+		d.warn(util.T("install the WeChat client, or copy the wxapkg files from another machine and unpack them with '-r'"),
+			util.T("the WeChat client is not found on %s"), runtime.GOOS)
+	case client.Version == "":
+		d.warn(util.T("the decryption profile is detected from the packages, set '--profile' if it fails"),
+			util.T("the WeChat client is found at '%s', but the version is unknown"), client.Path)
+	default:
+		d.ok(util.T("the WeChat client %s is found at '%s'"), client.Version, client.Path)
+	}
+
+	return client, found
+}
+
+// checkRoots checks the applet folders can be read, returns the wxapkg files in them.
+func (d *doctor) checkRoots() []string {
+	var roots = util.FindAccountAppletRoots()
+	if len(roots) == 0 {
+		d.fail(util.T("open a mini program in WeChat so that it's downloaded, or set the applet folder by '-r' when unpacking"),
+			util.T("no applet folder is found, the default one is '%s'"), util.DefaultAppletRoot())
+		return nil
+	}
+
+	var packages []string
+	for _, root := range roots {
+		entries, err := os.ReadDir(root.Path)
+		if err != nil {
+			d.fail(util.T("run as the user of WeChat, or grant the read permission of the folder"),
+				util.T("the applet folder '%s' can't be read: %v"), root.Path, err)
+			continue
+		}
+
+		var apps = 0
+		for _, e := range entries {
+			if !e.IsDir() || !regAppId.MatchString(e.Name()) {
+				continue
+			}
+			apps++
+			files, _ := filepath.Glob(filepath.Join(root.Path, e.Name(), "*", "*.wxapkg"))
+			packages = append(packages, files...)
+		}
+		if root.Account != "" {
+			d.ok(util.T("the applet folder '%s' of the account '%s' has %d mini programs"), root.Path, root.Account, apps)
+		} else {
+			d.ok(util.T("the applet folder '%s' has %d mini programs"), root.Path, apps)
+		}
+	}
+
+	var unreadable = 0
+	for _, p := range packages {
+		f, err := util.OpenSharedFile(p)
+		if err != nil {
+			unreadable++
+			continue
+		}
+		_ = f.Close()
+	}
+	if unreadable > 0 {
+		d.fail(util.T("run as the user of WeChat, or close WeChat if the files are locked"),
+			util.T("%d of %d wxapkg files can't be read"), unreadable, len(packages))
+	} else if len(packages) > 0 {
+		d.ok(util.T("all %d wxapkg files can be read"), len(packages))
+	}
+
+	return packages
+}
+
+// checkOutput checks the output path is writable and has the space for the packages, the unpacked files
+// are about 3 times the size of the packages when beautified.
+func (d *doctor) checkOutput(output string, packages []string) {
+	// the nearest existing folder, the output path is not created by the check
+	var dir, _ = filepath.Abs(output)
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			break
+		}
+		if parent := filepath.Dir(dir); parent != dir {
+			dir = parent
+		} else {
+			break
+		}
+	}
+
+	f, err := os.CreateTemp(dir, ".wxapkg-doctor-*")
+	if err != nil {
+		d.fail(util.T("choose another output path by '-o', or grant the write permission of the folder"),
+			util.T("the output path '%s' can't be written: %v"), output, err)
+		return
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+	d.ok(util.T("the output path '%s' can be written"), output)
+
+	var need int64
+	for _, p := range packages {
+		if info, err := os.Stat(p); err == nil {
+			need += info.Size() * 3
+		}
+	}
+	free, err := util.DiskFree(dir)
+	switch {
+	case err != nil:
+		d.warn(util.T("make sure the disk has enough space"), util.T("the free space of '%s' is unknown: %v"), dir, err)
+	case int64(free) < need:
+		d.fail(util.T("free up the disk, or choose another output path by '-o'"),
+			util.T("%s free on the disk of the output path, but about %s is needed to unpack all the mini programs"), formatSize(int64(free)), formatSize(need))
+	default:
+		d.ok(util.T("%s free on the disk of the output path"), formatSize(int64(free)))
+	}
+}
+
+// checkProfiles decrypts the heads of the sampled packages to check the decryption profile matches the client.
+func (d *doctor) checkProfiles(client util.WechatClient, found bool, packages []string) {
+	if found && client.Version != "" {
+		var expected = "mac-3.8"
+		if runtime.GOOS == "windows" {
+			expected = "windows-3.x"
+			if client.Major() >= 4 {
+				expected = "windows-4.x"
+			}
+		}
+		if _, err := wxapkg.LookupProfile(expected); err != nil || client.Major() > 4 {
+			d.warn(util.T("the format may be changed, try '--decryptor' or update wxapkg if the packages fail to decrypt"),
+				util.T("no known decryption profile for the WeChat client %s"), client.Version)
+		} else {
+			d.ok(util.T("the WeChat client %s uses the decryption profile '%s'"), client.Version, expected)
+		}
+	}
+
+	if len(packages) > doctorSamples {
+		packages = packages[len(packages)-doctorSamples:] // the latest mini programs are usually listed last
+	}
+
+	var failed []string
+	var profiles = make(map[string][]string) // the profile name -> the decrypted packages
+	for _, p := range packages {
+		f, err := util.OpenSharedFile(p)
+		if err != nil {
+			continue
+		}
+		info, err := f.Stat()
+		if err != nil {
+			_ = f.Close()
+			continue
+		}
+
+		var head = make([]byte, wxapkg.EncryptedHeadSize)
+		n, _ := f.ReadAt(head, 0)
+		var profile = packageProfile(head[:n])
+		if _, err := profile.NewDecrypter(f, info.Size(), regAppId.FindString(p)); err != nil ||
+			(!profile.Encrypted() && (n == 0 || head[0] != 0xBE)) {
+			failed = append(failed, p)
+		} else {
+			profiles[profile.Name] = append(profiles[profile.Name], p)
+		}
+		_ = f.Close()
+	}
+
+	if len(failed) > 0 {
+		d.fail(util.T("set the profile by '--profile', or decrypt them with the external command by '--decryptor'"),
+			util.T("%d of %d sampled wxapkg files can't be decrypted with the known profiles, like '%s'"), len(failed), len(packages), failed[0])
+	}
+	if len(profiles) > 0 {
+		var names []string
+		for _, name := range sortedKeys(profiles) {
+			names = append(names, fmt.Sprintf("%s (%d)", name, len(profiles[name])))
+		}
+		d.ok(util.T("%d sampled wxapkg files are decrypted with the profiles %s"), len(packages)-len(failed), strings.Join(names, ", "))
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+}
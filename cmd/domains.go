@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var domainsCmd = &cobra.Command{
+	Use:     "domains",
+	Short:   util.T("List the domains in the unpacked mini programs and report the ones not in the allowlist"),
+	Example: "  " + programName + " domains -o unpack --allowlist domains.txt",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		allowlist, _ := cmd.Flags().GetString("allowlist")
+
+		var list *util.DomainAllowlist
+		if allowlist != "" {
+			var err error
+			list, err = util.ReadDomainAllowlist(allowlist)
+			util.Fatal(err)
+		}
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		var total = 0
+		for _, dir := range dirs {
+			domains, err := findDomains(dir)
+			util.Fatal(err)
+
+			var report = domainReport{Domains: domains}
+			if list != nil {
+				report.Unlisted = []string{}
+				for _, d := range domains {
+					if !list.Allowed(d.Domain) {
+						report.Unlisted = append(report.Unlisted, d.Domain)
+					}
+				}
+			}
+
+			var path = filepath.Join(dir, "domains.json")
+			util.Fatal(util.SaveJson(path, report))
+
+			color.Yellow("[+] %s\n", dir)
+			for _, d := range domains {
+				if list != nil && !list.Allowed(d.Domain) {
+					color.Red("  ! %-40s %-15s %s\n", d.Domain, strings.Join(d.Kinds, ","), strings.Join(d.Files, ", "))
+				} else {
+					color.White("  - %-40s %-15s %s\n", d.Domain, strings.Join(d.Kinds, ","), strings.Join(d.Files, ", "))
+				}
+			}
+			if list != nil {
+				color.Cyan(util.T("[+] %d domains, %d not in the allowlist, saved to '%s'\n"), len(domains), len(report.Unlisted), path)
+			} else {
+				color.Cyan(util.T("[+] %d domains saved to '%s'\n"), len(domains), path)
+			}
+			total += len(report.Unlisted)
+		}
+
+		if list != nil {
+			color.Cyan(util.T("[+] %d domains not in the allowlist in %d unpacked folders\n"), total, len(dirs))
+		}
+	},
+}
+
+type domainReport struct {
+	Domains  []domainUse `json:"domains"`
+	Unlisted []string    `json:"unlisted,omitempty"` // the domains not in the allowlist
+}
+
+// domainUse is a domain found in the code, with the kinds like 'request' and 'socket' by the url schemes.
+type domainUse struct {
+	Domain string   `json:"domain"`
+	Kinds  []string `json:"kinds"`
+	Files  []string `json:"files"`
+}
+
+// findDomains returns the domains of the urls in the text files of the unpacked folder, sorted by the domains.
+func findDomains(dir string) ([]domainUse, error) {
+	manifest, err := util.ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var uses = make(map[string]*domainUse)
+	for _, f := range manifest.Files {
+		switch filepath.Ext(f.Path) {
+		case ".js", ".json", ".html", ".wxml", ".wxs":
+		default:
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range util.FindUrls(data) {
+			u, err := url.Parse(s)
+			if err != nil || u.Host == "" {
+				continue
+			}
+
+			var host = strings.ToLower(u.Host)
+			var use = uses[host]
+			if use == nil {
+				use = &domainUse{Domain: host}
+				uses[host] = use
+			}
+			use.Kinds = append(use.Kinds, util.DomainKind(u.Scheme))
+			use.Files = append(use.Files, f.Path)
+		}
+	}
+
+	var result = make([]domainUse, 0, len(uses))
+	for _, use := range uses {
+		use.Kinds = util.UniqueSorted(use.Kinds)
+		use.Files = util.UniqueSorted(use.Files)
+		result = append(result, *use)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Domain < result[j].Domain
+	})
+
+	return result, nil
+}
+
+func init() {
+	RootCmd.AddCommand(domainsCmd)
+
+	domainsCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	domainsCmd.Flags().String("allowlist", "", util.T("the file of the approved domains, one per line, like 'api.example.com', 'wss://ws.example.com' or '*.example.com'"))
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var duplicatesCmd = &cobra.Command{
+	Use:     "duplicates",
+	Short:   util.T("Report the files identical across the main package and the subpackages, like the shared utils copied into every package"),
+	Example: "  " + programName + " duplicates -o unpack",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		top, _ := cmd.Flags().GetInt("top")
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		for _, dir := range dirs {
+			manifest, err := util.ReadManifest(dir)
+			util.Fatal(err)
+
+			var report = findDuplicates(manifest)
+			var path = filepath.Join(dir, "duplicates.json")
+			util.Fatal(util.SaveJson(path, report))
+
+			color.Yellow("[+] %s\n", dir)
+			for i, group := range report.Groups {
+				if top > 0 && i >= top {
+					color.White(util.T("  ... %d more\n"), len(report.Groups)-top)
+					break
+				}
+				color.Cyan(util.T("  - %s x%d in %d packages, %s wasted\n"), group.Files[0].Path, len(group.Files), group.Packages, formatSize(group.Wasted))
+				for _, f := range group.Files {
+					color.White("      %s  %s\n", f.Package, f.Path)
+				}
+			}
+			color.Cyan(util.T("[+] %d files are duplicated across the packages, %s wasted, saved to '%s'\n"), len(report.Groups), formatSize(report.Wasted), path)
+		}
+	},
+}
+
+// duplicateReport is the files identical across the packages of an unpacked folder.
+type duplicateReport struct {
+	Wasted int64            `json:"wasted"` // the bytes of the extra copies
+	Groups []duplicateGroup `json:"groups"`
+}
+
+type duplicateGroup struct {
+	Sha256   string          `json:"sha256"`
+	Size     int64           `json:"size"`
+	Packages int             `json:"packages"`
+	Wasted   int64           `json:"wasted"`
+	Files    []duplicateFile `json:"files"`
+}
+
+type duplicateFile struct {
+	Package string `json:"package"`
+	Path    string `json:"path"`
+}
+
+// findDuplicates groups the files by the checksums, only the groups in more than one package are reported,
+// the copies in the same package are out of scope. The groups wasting more bytes come first.
+func findDuplicates(manifest *util.Manifest) duplicateReport {
+	var groups = make(map[string]*duplicateGroup)
+	var packages = make(map[string]map[string]bool) // sha256 -> packages
+	var order []string
+	for _, f := range manifest.Files {
+		if f.Sha256 == "" || f.Size == 0 {
+			continue
+		}
+		if groups[f.Sha256] == nil {
+			groups[f.Sha256] = &duplicateGroup{Sha256: f.Sha256, Size: int64(f.Size)}
+			packages[f.Sha256] = make(map[string]bool)
+			order = append(order, f.Sha256)
+		}
+		groups[f.Sha256].Files = append(groups[f.Sha256].Files, duplicateFile{Package: f.Package, Path: f.Path})
+		packages[f.Sha256][f.Package] = true
+	}
+
+	var report = duplicateReport{Groups: []duplicateGroup{}}
+	for _, sum := range order {
+		var group = groups[sum]
+		if len(packages[sum]) < 2 {
+			continue
+		}
+		group.Packages = len(packages[sum])
+		group.Wasted = group.Size * int64(len(group.Files)-1)
+		report.Wasted += group.Wasted
+		report.Groups = append(report.Groups, *group)
+	}
+	sort.SliceStable(report.Groups, func(i, j int) bool {
+		return report.Groups[i].Wasted > report.Groups[j].Wasted
+	})
+
+	return report
+}
+
+func init() {
+	RootCmd.AddCommand(duplicatesCmd)
+
+	duplicatesCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	duplicatesCmd.Flags().IntP("top", "t", 20, util.T("the number of the duplicate groups to show, 0 for all"))
+}
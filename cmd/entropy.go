@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+// the formats compressed already, their entropy is always high
+var compressedExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".mp3": true, ".mp4": true,
+	".m4a": true, ".aac": true, ".woff": true, ".woff2": true, ".ttf": true, ".otf": true, ".zip": true, ".gz": true,
+}
+
+var entropyCmd = &cobra.Command{
+	Use:     "entropy",
+	Short:   util.T("Find the high entropy regions in the unpacked files, like the embedded encrypted data or packed code"),
+	Example: "  " + programName + " entropy -o unpack --window 256",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		window, _ := cmd.Flags().GetInt("window")
+		textThreshold, _ := cmd.Flags().GetFloat64("text-threshold")
+		binaryThreshold, _ := cmd.Flags().GetFloat64("binary-threshold")
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		for _, dir := range dirs {
+			manifest, err := util.ReadManifest(dir)
+			util.Fatal(err)
+
+			var result = []entropyFile{}
+			for _, f := range manifest.Files {
+				if compressedExtensions[strings.ToLower(filepath.Ext(f.Path))] {
+					continue
+				}
+				data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+				util.Fatal(err)
+
+				var file = entropyFile{Path: f.Path, Text: util.IsTextFile(f.Path, data), Entropy: util.Entropy(data)}
+				var threshold = binaryThreshold
+				if file.Text {
+					threshold = textThreshold
+				}
+				for _, region := range util.HighEntropyRegions(data, window, threshold) {
+					var r = entropyRegion{EntropyRegion: region}
+					if file.Text {
+						r.Line = bytes.Count(data[:region.Start], []byte("\n")) + 1
+					}
+					file.Regions = append(file.Regions, r)
+				}
+				if len(file.Regions) > 0 {
+					result = append(result, file)
+				}
+			}
+
+			var path = filepath.Join(dir, "entropy.json")
+			util.Fatal(util.SaveJson(path, result))
+
+			color.Yellow("[+] %s\n", dir)
+			for _, f := range result {
+				color.Cyan(util.T("  - %s  entropy %.2f, %d high entropy regions\n"), f.Path, f.Entropy, len(f.Regions))
+				for _, r := range f.Regions {
+					if f.Text {
+						color.White(util.T("      line %d, 0x%x-0x%x, %d bytes, entropy %.2f\n"), r.Line, r.Start, r.End, r.End-r.Start, r.Entropy)
+					} else {
+						color.White(util.T("      0x%x-0x%x, %d bytes, entropy %.2f\n"), r.Start, r.End, r.End-r.Start, r.Entropy)
+					}
+				}
+			}
+			color.Cyan(util.T("[+] %d files with high entropy regions saved to '%s'\n"), len(result), path)
+		}
+	},
+}
+
+type entropyFile struct {
+	Path    string          `json:"path"`
+	Text    bool            `json:"text"`
+	Entropy float64         `json:"entropy"`
+	Regions []entropyRegion `json:"regions"`
+}
+
+type entropyRegion struct {
+	util.EntropyRegion
+	Line int `json:"line,omitempty"` // the line of the start in the text files
+}
+
+func init() {
+	RootCmd.AddCommand(entropyCmd)
+
+	entropyCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	entropyCmd.Flags().Int("window", 256, util.T("the size of the sliding window in bytes"))
+	entropyCmd.Flags().Float64("text-threshold", 5.5, util.T("the entropy threshold of the text files in bits per byte, the base64 of the random data is about 6"))
+	entropyCmd.Flags().Float64("binary-threshold", 7.0, util.T("the entropy threshold of the binary files in bits per byte"))
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var graphCmd = &cobra.Command{
+	Use:     "graph",
+	Short:   util.T("Export the module dependency graph of the unpacked mini program"),
+	Example: "  " + programName + " graph -o unpack -f dot",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		format, _ := cmd.Flags().GetString("format")
+
+		if format != "dot" && format != "json" {
+			util.Fatal(fmt.Errorf(util.T("unknown graph format '%s', only 'dot' and 'json' are supported"), format))
+		}
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		for _, dir := range dirs {
+			graph, err := newModuleGraph(dir)
+			util.Fatal(err)
+
+			var path = filepath.Join(dir, "graph."+format)
+			var data []byte
+			if format == "dot" {
+				data = graph.dot()
+			} else {
+				data, _ = json.MarshalIndent(graph, "", "  ")
+			}
+			util.Fatal(os.WriteFile(path, data, 0600))
+
+			color.Cyan(util.T("[+] %d modules, %d dependencies saved to '%s'\n"), len(graph.Nodes), len(graph.Edges), path)
+		}
+	},
+}
+
+type graphNode struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"` // page, component or module
+}
+
+type graphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type moduleGraph struct {
+	Nodes []graphNode `json:"nodes"`
+	Edges []graphEdge `json:"edges"`
+}
+
+func newModuleGraph(dir string) (*moduleGraph, error) {
+	modules, err := util.LoadModules(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := util.ReadAppConfig(dir)
+	if err != nil {
+		config = &util.AppConfig{} // subpackage only
+	}
+
+	var graph = &moduleGraph{}
+	for _, m := range modules {
+		var kind = "module"
+		if config.IsPage(m.Name) {
+			kind = "page"
+		} else if strings.Contains(m.Name, "components/") {
+			kind = "component"
+		}
+		graph.Nodes = append(graph.Nodes, graphNode{Name: m.Name, Kind: kind})
+
+		for _, dep := range m.Requires() {
+			graph.Edges = append(graph.Edges, graphEdge{From: m.Name, To: dep})
+		}
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].Name < graph.Nodes[j].Name
+	})
+
+	return graph, nil
+}
+
+func (g *moduleGraph) dot() []byte {
+	var shapes = map[string]string{
+		"page":      "box",
+		"component": "component",
+		"module":    "ellipse",
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph modules {\n  rankdir=LR;\n")
+	for _, n := range g.Nodes {
+		b.WriteString(fmt.Sprintf("  %q [shape=%s];\n", n.Name, shapes[n.Kind]))
+	}
+	for _, e := range g.Edges {
+		b.WriteString(fmt.Sprintf("  %q -> %q;\n", e.From, e.To))
+	}
+	b.WriteString("}\n")
+
+	return []byte(b.String())
+}
+
+func init() {
+	RootCmd.AddCommand(graphCmd)
+
+	graphCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	graphCmd.Flags().StringP("format", "f", "dot", util.T("the graph format, 'dot' or 'json'"))
+}
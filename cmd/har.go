@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var harCmd = &cobra.Command{
+	Use:     "har",
+	Short:   util.T("Export the requests found in the unpacked mini program as a HAR file for Burp, ZAP or Insomnia"),
+	Example: "  " + programName + " har -o unpack --base-url https://api.example.com",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		baseUrl, _ := cmd.Flags().GetString("base-url")
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		for _, dir := range dirs {
+			modules, err := util.LoadModules(dir)
+			util.Fatal(err)
+
+			var har = util.NewHar("wxapkg")
+			for _, m := range modules {
+				var calls = util.FindRequests(m.Code)
+				util.SortRequests(calls)
+				for _, call := range calls {
+					har.AddRequest(call, baseUrl, fmt.Sprintf("%s:%d", m.Name, call.Line))
+				}
+			}
+
+			var path = filepath.Join(dir, "requests.har")
+			util.Fatal(util.SaveJson(path, har))
+			color.Cyan(util.T("[+] %d requests of '%s' saved to '%s'\n"), len(har.Log.Entries), dir, path)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(harCmd)
+
+	harCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	harCmd.Flags().String("base-url", "https://example.com", util.T("the base url of the requests whose host is dynamic, like 'https://api.example.com'"))
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+const (
+	hotspotSortComplexity = "complexity"
+	hotspotSortSize       = "size"
+	hotspotSortStatements = "statements"
+)
+
+var hotspotsCmd = &cobra.Command{
+	Use:     "hotspots",
+	Short:   util.T("Report the largest and most complex modules, which deserve the manual review first"),
+	Example: "  " + programName + " hotspots -o unpack --top 20 --sort complexity",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		top, _ := cmd.Flags().GetInt("top")
+		by, _ := cmd.Flags().GetString("sort")
+		if by != hotspotSortComplexity && by != hotspotSortSize && by != hotspotSortStatements {
+			util.Fatal(fmt.Errorf(util.T("invalid sort '%s', 'complexity', 'size' or 'statements' expected"), by))
+		}
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		for _, dir := range dirs {
+			manifest, err := util.ReadManifest(dir)
+			util.Fatal(err)
+			modules, err := util.LoadModules(dir)
+			util.Fatal(err)
+
+			// the known libraries are not the code of the mini program
+			var libraries = make(map[string]bool)
+			for _, f := range manifest.Files {
+				if f.Library != "" {
+					libraries[f.Path] = true
+					libraries[strings.TrimPrefix(f.Name, "/")] = true
+				}
+			}
+
+			var result = []hotspot{}
+			for _, m := range modules {
+				if libraries[m.Name] {
+					continue
+				}
+				result = append(result, hotspot{Module: m.Name, Line: m.Line, Size: len(m.Code), CodeComplexity: util.Complexity(m.Code)})
+			}
+			sort.SliceStable(result, func(i, j int) bool {
+				var a, b = result[i], result[j]
+				switch by {
+				case hotspotSortSize:
+					return a.Size > b.Size
+				case hotspotSortStatements:
+					return a.Statements > b.Statements
+				default:
+					return a.Cyclomatic > b.Cyclomatic || a.Cyclomatic == b.Cyclomatic && a.Size > b.Size
+				}
+			})
+
+			var path = filepath.Join(dir, "hotspots.json")
+			util.Fatal(util.SaveJson(path, result))
+
+			color.Yellow("[+] %s\n", dir)
+			color.Cyan("  %10s %10s %10s %10s  %s\n", util.T("complexity"), util.T("statements"), util.T("functions"), util.T("size"), util.T("module"))
+			for i, h := range result {
+				if top > 0 && i >= top {
+					break
+				}
+				color.Cyan("  %10d %10d %10d %10s  %s\n", h.Cyclomatic, h.Statements, h.Functions, formatSize(int64(h.Size)), h.Module)
+			}
+			color.Cyan(util.T("[+] %d modules saved to '%s'\n"), len(result), path)
+		}
+	},
+}
+
+// hotspot is a module with its size and complexity.
+type hotspot struct {
+	Module string `json:"module"`
+	Line   int    `json:"line,omitempty"` // the line of the define in the bundled file
+	Size   int    `json:"size"`
+	util.CodeComplexity
+}
+
+func init() {
+	RootCmd.AddCommand(hotspotsCmd)
+
+	hotspotsCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	hotspotsCmd.Flags().IntP("top", "t", 20, util.T("the number of the modules to show, 0 for all"))
+	hotspotsCmd.Flags().String("sort", hotspotSortComplexity, util.T("sort the modules by 'complexity', 'size' or 'statements'"))
+}
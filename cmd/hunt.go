@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var huntCmd = &cobra.Command{
+	Use:     "hunt <regex>",
+	Short:   util.T("Find in which versions of the mini programs the regex first appears or disappears"),
+	Example: "  " + programName + " hunt -o unpack --wxid wx1234567890abcdef 'api\\.example\\.com/v2'",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		wxid, _ := cmd.Flags().GetString("wxid")
+		ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+
+		var expr = args[0]
+		if ignoreCase {
+			expr = "(?i)" + expr
+		}
+		reg, err := regexp.Compile(expr)
+		if err != nil {
+			util.Fatal(fmt.Errorf(util.T("invalid regex '%s': %v"), args[0], err))
+		}
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		var apps = make(map[string][]huntVersion)
+		var scanned = make(map[string]huntMatch) // sha256 -> the matches in the file, the same files are scanned once
+		for _, dir := range dirs {
+			manifest, err := util.ReadManifest(dir)
+			util.Fatal(err)
+			if wxid != "" && manifest.Wxid != wxid {
+				continue
+			}
+
+			version, err := huntDir(dir, manifest, reg, scanned)
+			util.Fatal(err)
+			apps[manifest.Wxid] = append(apps[manifest.Wxid], version)
+		}
+		if len(apps) == 0 && wxid != "" {
+			util.Fatal(util.WithExitCode(util.ExitNoPackage, fmt.Errorf(util.T("no unpacked folder of '%s' found in '%s'"), wxid, output)))
+		} else if len(apps) == 0 {
+			util.Fatal(util.WithExitCode(util.ExitNoPackage, fmt.Errorf(util.T("no unpacked folder found in '%s'"), output)))
+		}
+
+		var wxids = make([]string, 0, len(apps))
+		for id := range apps {
+			wxids = append(wxids, id)
+		}
+		sort.Strings(wxids)
+
+		for _, id := range wxids {
+			var versions = apps[id]
+			sort.SliceStable(versions, func(i, j int) bool {
+				return versions[i].time.Before(versions[j].time)
+			})
+
+			color.Yellow("[+] %s\n", id)
+			var present = false
+			for _, v := range versions {
+				var found = v.matches > 0
+				switch {
+				case found && !present:
+					color.Green(util.T("  + %s  appeared, %d matches, first at %s:%d\n"), v.name, v.matches, v.path, v.line)
+				case !found && present:
+					color.Red(util.T("  - %s  disappeared\n"), v.name)
+				case found:
+					color.Cyan(util.T("    %s  %d matches\n"), v.name, v.matches)
+				default:
+					color.White(util.T("    %s  not found\n"), v.name)
+				}
+				present = found
+			}
+		}
+	},
+}
+
+// huntVersion is the matches of the regex in an unpacked folder.
+type huntVersion struct {
+	name    string
+	time    time.Time
+	matches int
+	path    string // the first file matched
+	line    int
+}
+
+type huntMatch struct {
+	count int
+	line  int // the line of the first match
+}
+
+// huntDir counts the matches of the regex in the text files of the unpacked folder.
+func huntDir(dir string, manifest *util.Manifest, reg *regexp.Regexp, scanned map[string]huntMatch) (huntVersion, error) {
+	var v = huntVersion{name: filepath.Base(dir), time: manifest.Time}
+
+	// the manifests saved by the old versions have no time
+	if v.time.IsZero() {
+		info, err := os.Stat(filepath.Join(dir, util.ManifestName))
+		if err != nil {
+			return v, err
+		}
+		v.time = info.ModTime()
+	}
+
+	var files = manifest.Files
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	for _, f := range files {
+		m, ok := scanned[f.Sha256]
+		if !ok || f.Sha256 == "" {
+			data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+			if err != nil {
+				return v, err
+			}
+			if util.IsTextFile(f.Path, data) {
+				var locs = reg.FindAllIndex(data, -1)
+				m.count = len(locs)
+				if len(locs) > 0 {
+					m.line = bytes.Count(data[:locs[0][0]], []byte("\n")) + 1
+				}
+			}
+			scanned[f.Sha256] = m
+		}
+
+		if m.count > 0 && v.matches == 0 {
+			v.path, v.line = f.Path, m.line
+		}
+		v.matches += m.count
+	}
+
+	return v, nil
+}
+
+func init() {
+	RootCmd.AddCommand(huntCmd)
+
+	huntCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	huntCmd.Flags().String("wxid", "", util.T("only hunt in the versions of the mini program, all the mini programs by default"))
+	huntCmd.Flags().BoolP("ignore-case", "i", false, util.T("match the regex case-insensitively"))
+}
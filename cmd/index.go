@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var indexCmd = &cobra.Command{
+	Use:     "index",
+	Short:   util.T("Build the full-text index of the unpacked mini programs for the search command"),
+	Example: "  " + programName + " index -o unpack",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		index, err := util.BuildIndex(output)
+		util.Fatal(err)
+		util.Fatal(index.Save(output))
+
+		color.Cyan(util.T("[+] %d files and %d terms indexed to '%s'\n"), len(index.Docs), len(index.Terms), filepath.Join(output, util.IndexName))
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(indexCmd)
+
+	indexCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/wux1an/wxapkg/util"
+)
+
+// the output layouts of the unpacked files
+const (
+	layoutMerged  = "merged"  // all the packages of a version are merged into one folder
+	layoutPackage = "package" // every package has its own folder named after its role, e.g. 'main/', 'sub_pages_shop/'
+	layoutFlat    = "flat"    // the packages of the only version are merged into the output path directly
+)
+
+// versionNames returns the names of the version folders in dirs, the versions can't share the output path
+// in the flat layout.
+func versionNames(dirs []os.DirEntry) []string {
+	var names []string
+	for _, d := range dirs {
+		if d.IsDir() {
+			names = append(names, d.Name())
+		}
+	}
+
+	return names
+}
+
+// packageRoles names the folders of the wxapkg files of a version after their roles, wxapkg path -> folder.
+// The main package has the 'app-config.json', and the subpackages are matched with the subpackage roots
+// in it. The file name is used if the role is unknown, e.g. the package can't be decrypted.
+func packageRoles(wxid string, files []string) map[string]string {
+	var names = make(map[string][]string, len(files))
+	var roots []string
+	var main string
+	for _, file := range files {
+		pkg, closer, err := openPackage(wxid, file, true)
+		if err != nil {
+			continue
+		}
+		_, entries, err := readIndex(pkg)
+		if err != nil {
+			_ = closer.Close()
+			continue
+		}
+
+		for _, e := range entries {
+			names[file] = append(names[file], e.Name)
+			if e.Name != "/"+util.AppConfigName || main != "" {
+				continue
+			}
+
+			main = file
+			var data = make([]byte, e.Size)
+			if _, err := pkg.ReadAt(data, int64(e.Offset)); err != nil {
+				continue
+			}
+			var config util.AppConfig
+			if util.UnmarshalJson(data, &config) == nil {
+				for _, sub := range config.SubPackages {
+					roots = append(roots, strings.Trim(sub.Root, "/"))
+				}
+			}
+		}
+		_ = closer.Close()
+	}
+
+	var roles = make(map[string]string, len(files))
+	for _, file := range files {
+		var root = packageRoot(names[file], roots)
+		switch {
+		case file == main:
+			roles[file] = "main"
+		case root != "":
+			roles[file] = "sub_" + strings.ReplaceAll(root, "/", "_")
+		default:
+			roles[file] = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		}
+	}
+
+	return roles
+}
+
+// packageRoot returns the subpackage root containing all the files, the common folder of the files
+// is used if no root matches.
+func packageRoot(names []string, roots []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	var contains = func(root string) bool {
+		for _, name := range names {
+			if !strings.HasPrefix(strings.TrimPrefix(name, "/"), root+"/") {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, root := range roots {
+		if root != "" && contains(root) {
+			return root
+		}
+	}
+
+	var common = path.Dir(strings.TrimPrefix(names[0], "/"))
+	for common != "." && !contains(common) {
+		common = path.Dir(common)
+	}
+	if common == "." {
+		return ""
+	}
+
+	return common
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var locCmd = &cobra.Command{
+	Use:     "loc",
+	Short:   util.T("Count the blank, comment and code lines of the unpacked files by the languages, like cloc"),
+	Example: "  " + programName + " loc -o unpack",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		libraries, _ := cmd.Flags().GetBool("libraries")
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		for _, dir := range dirs {
+			manifest, err := util.ReadManifest(dir)
+			util.Fatal(err)
+
+			var report = locReport{Languages: []util.LineCount{}, Total: util.LineCount{Language: "SUM"}}
+			var counts = make(map[string]*util.LineCount)
+			for _, f := range manifest.Files {
+				if f.Library != "" && !libraries {
+					continue
+				}
+				data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+				util.Fatal(err)
+
+				count, ok := util.CountLines(f.Path, data)
+				if !ok {
+					continue
+				}
+				if counts[count.Language] == nil {
+					counts[count.Language] = &util.LineCount{Language: count.Language}
+				}
+				counts[count.Language].Add(count)
+				report.Total.Add(count)
+			}
+			for _, count := range counts {
+				report.Languages = append(report.Languages, *count)
+			}
+			sort.Slice(report.Languages, func(i, j int) bool {
+				if report.Languages[i].Code != report.Languages[j].Code {
+					return report.Languages[i].Code > report.Languages[j].Code
+				}
+				return report.Languages[i].Language < report.Languages[j].Language
+			})
+
+			var path = filepath.Join(dir, "loc.json")
+			util.Fatal(util.SaveJson(path, report))
+
+			color.Yellow("[+] %s\n", dir)
+			color.Cyan("  %-12s %8s %8s %8s %8s\n", util.T("language"), util.T("files"), util.T("blank"), util.T("comment"), util.T("code"))
+			for _, c := range append(report.Languages, report.Total) {
+				color.Cyan("  %-12s %8d %8d %8d %8d\n", c.Language, c.Files, c.Blank, c.Comment, c.Code)
+			}
+			color.Cyan(util.T("[+] the line counts saved to '%s'\n"), path)
+		}
+	},
+}
+
+// locReport is the line counts of an unpacked folder.
+type locReport struct {
+	Languages []util.LineCount `json:"languages"`
+	Total     util.LineCount   `json:"total"`
+}
+
+func init() {
+	RootCmd.AddCommand(locCmd)
+
+	locCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	locCmd.Flags().Bool("libraries", false, util.T("count the known libraries and the shared vendor chunks too"))
+}
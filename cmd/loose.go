@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/wux1an/wxapkg/util"
+)
+
+// readWxidMap reads the csv file mapping the wxapkg file names to the wxids, file name -> wxid.
+// The rows without a wxid, like the header, are ignored.
+func readWxidMap(name string) (map[string]string, error) {
+	var wxids = make(map[string]string)
+	if name == "" {
+		return wxids, nil
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, util.WithExitCode(util.ExitIO, err)
+	}
+	defer f.Close()
+
+	var r = csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf(util.T("invalid wxid map '%s': %v"), name, err)
+		}
+		if len(record) < 2 || !regAppId.MatchString(record[1]) {
+			continue
+		}
+		wxids[filepath.Base(strings.TrimSpace(record[0]))] = regAppId.FindString(record[1])
+	}
+
+	return wxids, nil
+}
+
+// groupLooseFiles groups the loose wxapkg files harvested from anywhere by the wxids, which are found
+// in the wxid map, the file names or the packages. Every mini program is saved to the folder named after
+// its wxid.
+func groupLooseFiles(files []string, wxids map[string]string) []packageGroup {
+	var groups = make(map[string]*packageGroup)
+	for _, file := range files {
+		var wxid = wxids[filepath.Base(file)]
+		if wxid == "" {
+			wxid = regAppId.FindString(filepath.Base(file))
+		}
+		if wxid == "" {
+			wxid = recoverWxids([]string{file})
+		}
+		if wxid == "" {
+			color.Yellow(util.T("[!] skip '%s', no wxid found in the file name, the wxid map or the package\n"), file)
+			continue
+		}
+
+		if groups[wxid] == nil {
+			groups[wxid] = &packageGroup{wxid: wxid, output: wxid}
+		}
+		groups[wxid].files = append(groups[wxid].files, file)
+	}
+
+	var result = make([]packageGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].wxid < result[j].wxid
+	})
+
+	return result
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var mapLocalCmd = &cobra.Command{
+	Use:   "maplocal",
+	Short: util.T("Generate the whistle or mitmproxy map local rules serving the unpacked files in place of the remote ones"),
+	Example: "  " + programName + " maplocal -o unpack --format whistle\n" +
+		"  " + programName + " maplocal -o unpack --format mitmproxy --url-prefix https://cdn.example.com/app",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		format, _ := cmd.Flags().GetString("format")
+		prefix, _ := cmd.Flags().GetString("url-prefix")
+
+		var name string
+		switch format {
+		case util.MapLocalWhistle:
+			name = "maplocal.whistle.txt"
+		case util.MapLocalMitmproxy:
+			name = "config.yaml"
+		default:
+			util.Fatal(fmt.Errorf(util.T("invalid map local format '%s', 'whistle' or 'mitmproxy' expected"), format))
+		}
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		for _, dir := range dirs {
+			rules, err := findMapLocalRules(dir, strings.TrimSuffix(prefix, "/"))
+			util.Fatal(err)
+
+			data, err := util.FormatMapLocalRules(rules, format)
+			util.Fatal(err)
+
+			var path = filepath.Join(dir, "maplocal", name)
+			util.Fatal(os.MkdirAll(filepath.Dir(path), os.ModePerm))
+			util.Fatal(os.WriteFile(path, data, 0600))
+			color.Cyan(util.T("[+] %d map local rules of '%s' saved to '%s'\n"), len(rules), dir, path)
+		}
+	},
+}
+
+// findMapLocalRules maps the urls in the code whose paths end with the paths of the unpacked files, like the
+// images of the package also uploaded to the cdn, and all the files under the url prefix if it's set.
+func findMapLocalRules(dir, prefix string) ([]util.MapLocalRule, error) {
+	manifest, err := util.ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var byBase = make(map[string][]string) // the base name -> the slash paths
+	var urls []string
+	for _, f := range manifest.Files {
+		byBase[filepath.Base(f.Path)] = append(byBase[filepath.Base(f.Path)], f.Path)
+
+		switch filepath.Ext(f.Path) {
+		case ".js", ".json", ".html", ".wxml", ".wxs", ".wxss", ".css":
+		default:
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, util.FindUrls(data)...)
+	}
+
+	var rules []util.MapLocalRule
+	var seen = make(map[string]bool)
+	var add = func(u, p string) {
+		if !seen[u] {
+			seen[u] = true
+			rules = append(rules, util.MapLocalRule{Url: u, Path: filepath.Join(abs, filepath.FromSlash(p))})
+		}
+	}
+
+	for _, s := range util.UniqueSorted(urls) {
+		u, err := url.Parse(s)
+		if err != nil || u.Host == "" {
+			continue
+		}
+
+		// the longest path matches
+		var match = ""
+		for _, p := range byBase[filepath.Base(u.Path)] {
+			if strings.HasSuffix(u.Path, "/"+p) && len(p) > len(match) {
+				match = p
+			}
+		}
+		if match != "" {
+			add(u.Scheme+"://"+u.Host+u.Path, match)
+		}
+	}
+	if prefix != "" {
+		for _, f := range manifest.Files {
+			add(prefix+"/"+f.Path, f.Path)
+		}
+	}
+	util.SortMapLocalRules(rules)
+
+	return rules, nil
+}
+
+func init() {
+	RootCmd.AddCommand(mapLocalCmd)
+
+	mapLocalCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	mapLocalCmd.Flags().String("format", util.MapLocalWhistle, util.T("the format of the rules, 'whistle' or 'mitmproxy'"))
+	mapLocalCmd.Flags().String("url-prefix", "", util.T("map all the unpacked files under the url prefix, like 'https://cdn.example.com/app'"))
+}
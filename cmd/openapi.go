@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var openapiCmd = &cobra.Command{
+	Use:     "openapi",
+	Short:   util.T("Generate the draft OpenAPI document from the wx.request calls of the unpacked mini program"),
+	Example: "  " + programName + " openapi -o unpack",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		for _, dir := range dirs {
+			manifest, err := util.ReadManifest(dir)
+			util.Fatal(err)
+			modules, err := util.LoadModules(dir)
+			util.Fatal(err)
+
+			var doc = util.NewOpenApi(manifest.Wxid, filepath.Base(dir),
+				util.T("generated from the wx.request calls statically, the dynamic parts are named after the expressions"))
+			var count = 0
+			for _, m := range modules {
+				for _, call := range util.FindRequests(m.Code) {
+					doc.AddRequest(call, fmt.Sprintf("%s:%d", m.Name, call.Line))
+					count++
+				}
+			}
+
+			var path = filepath.Join(dir, "openapi.json")
+			util.Fatal(util.SaveJson(path, doc))
+			color.Cyan(util.T("[+] %d request calls of '%s' mapped to %d paths, saved to '%s'\n"), count, dir, len(doc.Paths), path)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(openapiCmd)
+
+	openapiCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+// the keys of the template ids in the ext configs, like 'templateId' and 'template_id'
+var regTemplateKey = regexp.MustCompile(`(?i)^(?:tpl|template)_?id$`)
+
+var platformCmd = &cobra.Command{
+	Use:     "platform",
+	Short:   util.T("Show the mini programs built by the third-party platform, with their templates and tenants"),
+	Example: "  " + programName + " platform -o unpack",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		var report = platformReport{Apps: []platformApp{}, Templates: map[string][]string{}, SharedCode: map[string][]string{}}
+		for _, dir := range dirs {
+			app, ok, err := readPlatformApp(dir)
+			util.Fatal(err)
+			if !ok {
+				continue
+			}
+			report.Apps = append(report.Apps, app)
+
+			color.Yellow(util.T("[+] %s is built by the third-party platform\n"), dir)
+			color.Cyan(util.T("  - extAppid: %s\n"), app.ExtAppid)
+			if len(app.TemplateIds) > 0 {
+				color.Cyan(util.T("  - template ids: %s\n"), strings.Join(app.TemplateIds, ", "))
+			}
+			if len(app.ExtKeys) > 0 {
+				color.Cyan(util.T("  - ext config: %s\n"), strings.Join(app.ExtKeys, ", "))
+			}
+
+			var tenant = app.tenant()
+			for _, id := range app.TemplateIds {
+				report.Templates[id] = appendUnique(report.Templates[id], tenant)
+			}
+			if app.CodeSha256 != "" {
+				report.SharedCode[app.CodeSha256] = appendUnique(report.SharedCode[app.CodeSha256], tenant)
+			}
+		}
+
+		for _, id := range sortedKeys(report.Templates) {
+			color.Green(util.T("[+] template %s is used by %s\n"), id, strings.Join(report.Templates[id], ", "))
+		}
+		// the tenants of the same template share the code, even if the template id is not in the config
+		for _, sum := range sortedKeys(report.SharedCode) {
+			if tenants := report.SharedCode[sum]; len(tenants) > 1 {
+				color.Green(util.T("[+] the same code %s is used by %s\n"), sum[:12], strings.Join(tenants, ", "))
+			}
+		}
+
+		var path = filepath.Join(output, "platform.json")
+		util.Fatal(util.SaveJson(path, report))
+		color.Cyan(util.T("[+] %d mini programs built by the third-party platform saved to '%s'\n"), len(report.Apps), path)
+	},
+}
+
+type platformReport struct {
+	Apps       []platformApp       `json:"apps"`
+	Templates  map[string][]string `json:"templates"`   // template id -> tenants
+	SharedCode map[string][]string `json:"shared_code"` // sha256 of the app service -> tenants
+}
+
+// platformApp is the mini program built by the third-party platform from a template.
+type platformApp struct {
+	Wxid        string   `json:"wxid"`
+	Path        string   `json:"path"`
+	ExtAppid    string   `json:"ext_appid"` // the tenant the ext config is for
+	TemplateIds []string `json:"template_ids"`
+	ExtKeys     []string `json:"ext_keys"`
+	CodeSha256  string   `json:"code_sha256"` // of the 'app-service.js' in the main package
+}
+
+// tenant returns the tenant appid, the wxid if the ext appid is not set.
+func (a platformApp) tenant() string {
+	if a.ExtAppid != "" {
+		return a.ExtAppid
+	}
+	return a.Wxid
+}
+
+// readPlatformApp reads the ext config merged into the app config of the unpacked folder, it returns false
+// if the mini program is not built by the third-party platform.
+func readPlatformApp(dir string) (platformApp, bool, error) {
+	var app = platformApp{Path: dir, TemplateIds: []string{}, ExtKeys: []string{}}
+
+	manifest, err := util.ReadManifest(dir)
+	if err != nil {
+		return app, false, err
+	}
+	app.Wxid = manifest.Wxid
+
+	config, err := util.ReadAppConfig(dir)
+	if os.IsNotExist(err) {
+		return app, false, nil
+	}
+	if err != nil {
+		return app, false, err
+	}
+	if config.ExtAppid == "" && len(config.Ext) == 0 {
+		return app, false, nil
+	}
+
+	app.ExtAppid = config.ExtAppid
+	for key := range config.Ext {
+		app.ExtKeys = append(app.ExtKeys, key)
+	}
+	sort.Strings(app.ExtKeys)
+	app.TemplateIds = findTemplateIds(config.Ext)
+
+	for _, f := range manifest.Files {
+		if f.Path == "app-service.js" {
+			app.CodeSha256 = f.Sha256
+		}
+	}
+
+	return app, true, nil
+}
+
+// findTemplateIds finds the values of the template id keys in the ext config.
+func findTemplateIds(v interface{}) []string {
+	var result []string
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if !regTemplateKey.MatchString(key) {
+				result = append(result, findTemplateIds(value)...)
+				continue
+			}
+			switch value := value.(type) {
+			case string:
+				result = append(result, value)
+			case json.Number: // the numbers are kept by util.UnmarshalJson
+				result = append(result, value.String())
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			result = append(result, findTemplateIds(item)...)
+		}
+	}
+
+	return util.UniqueSorted(result)
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, item := range list {
+		if item == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+func sortedKeys(m map[string][]string) []string {
+	var keys = make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func init() {
+	RootCmd.AddCommand(platformCmd)
+
+	platformCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+}
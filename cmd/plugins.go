@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wux1an/wxapkg/util"
+)
+
+// pluginLink is a plugin used by the host mini program, resolved to the unpacked code of the plugin.
+type pluginLink struct {
+	Alias    string `json:"alias"`
+	Provider string `json:"provider"`
+	Version  string `json:"version"`
+	Source   string `json:"source"` // the unpacked folder of the plugin, empty if it's not unpacked
+	Path     string `json:"path"`   // the folder of the plugin code in the host project, like 'plugins/myPlugin'
+	Main     string `json:"main,omitempty"`
+	// 'plugin://myPlugin/list' -> 'plugins/myPlugin/components/list/list', the pages and the components
+	References map[string]string `json:"references"`
+}
+
+// pluginConfig is the 'plugin.json' of the plugin.
+type pluginConfig struct {
+	PublicComponents map[string]string `json:"publicComponents"`
+	Pages            map[string]string `json:"pages"`
+	Main             string            `json:"main"`
+}
+
+// latestUnpackedDirs returns the latest unpacked folder of every mini program or plugin, wxid -> folder.
+func latestUnpackedDirs(dirs []string) (map[string]string, error) {
+	var result = make(map[string]string)
+	var latest = make(map[string]*util.Manifest)
+	for _, dir := range dirs {
+		manifest, err := util.ReadManifest(dir)
+		if err != nil {
+			return nil, err
+		}
+		if m := latest[manifest.Wxid]; m == nil || m.Time.Before(manifest.Time) {
+			latest[manifest.Wxid] = manifest
+			result[manifest.Wxid] = dir
+		}
+	}
+
+	return result, nil
+}
+
+// restorePlugins copies the code of the plugins used by the host mini program in dir to 'plugins/<alias>/',
+// if the plugins are unpacked as well, and saves the map of the 'plugin://' references to 'plugins.json'.
+func restorePlugins(dir string, config *util.AppConfig, unpacked map[string]string) ([]pluginLink, error) {
+	var plugins = config.AllPlugins()
+	var aliases = make([]string, 0, len(plugins))
+	for alias := range plugins {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	var links = make([]pluginLink, 0, len(aliases))
+	for _, alias := range aliases {
+		var p = plugins[alias]
+		var link = pluginLink{Alias: alias, Provider: p.Provider, Version: p.Version, References: map[string]string{}}
+		if source, ok := unpacked[p.Provider]; ok && p.Provider != "" {
+			link.Source = source
+			link.Path = path.Join("plugins", alias)
+			if err := copyPlugin(source, filepath.Join(dir, filepath.FromSlash(link.Path)), &link); err != nil {
+				return links, err
+			}
+		}
+
+		// the references not in 'plugin.json' are kept unresolved
+		for _, ref := range pluginReferences(config, alias) {
+			if _, ok := link.References[ref]; !ok {
+				link.References[ref] = ""
+			}
+		}
+		links = append(links, link)
+	}
+
+	data, _ := util.MarshalJson(links)
+	return links, os.WriteFile(filepath.Join(dir, "plugins.json"), data, 0600)
+}
+
+// copyPlugin copies the unpacked plugin to the folder target, and resolves the public components, the pages
+// and the main module declared by its 'plugin.json'.
+func copyPlugin(source, target string, link *pluginLink) error {
+	manifest, err := util.ReadManifest(source)
+	if err != nil {
+		return err
+	}
+
+	// the code of the plugin may be placed under '__plugin__/<appid>/' in the package
+	var root = ""
+	if _, err := os.Stat(filepath.Join(source, "__plugin__", link.Provider)); err == nil {
+		root = path.Join("__plugin__", link.Provider) + "/"
+	}
+
+	for _, f := range manifest.Files {
+		if !strings.HasPrefix(f.Path, root) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(source, filepath.FromSlash(f.Path)))
+		if err != nil {
+			return err
+		}
+		if _, err := util.WriteFileIfNotExist(filepath.Join(target, filepath.FromSlash(strings.TrimPrefix(f.Path, root))), data); err != nil {
+			return err
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(target, "plugin.json"))
+	if err != nil {
+		return nil // no public components or pages
+	}
+	var config pluginConfig
+	if err := util.UnmarshalJson(data, &config); err != nil {
+		return nil
+	}
+
+	var resolve = func(p string) string {
+		return path.Join(link.Path, strings.TrimPrefix(strings.TrimSuffix(p, ".js"), "/"))
+	}
+	for name, p := range config.PublicComponents {
+		link.References["plugin://"+link.Alias+"/"+name] = resolve(p)
+	}
+	for name, p := range config.Pages {
+		link.References["plugin://"+link.Alias+"/"+name] = resolve(p)
+	}
+	if config.Main != "" {
+		link.Main = resolve(config.Main) + ".js"
+	}
+
+	return nil
+}
+
+// pluginReferences returns the 'plugin://<alias>/...' components used by the pages and the components.
+func pluginReferences(config *util.AppConfig, alias string) []string {
+	var result []string
+	var collect = func(window map[string]interface{}) {
+		components, _ := window["usingComponents"].(map[string]interface{})
+		for _, p := range components {
+			if s, ok := p.(string); ok && strings.HasPrefix(s, "plugin://"+alias+"/") {
+				result = append(result, s)
+			}
+		}
+	}
+
+	collect(config.Global.Window)
+	for _, page := range config.Page {
+		collect(page.Window)
+	}
+
+	return util.UniqueSorted(result)
+}
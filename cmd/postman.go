@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var postmanCmd = &cobra.Command{
+	Use:     "postman",
+	Short:   util.T("Export the requests found in the unpacked mini program as a Postman collection grouped by the modules"),
+	Example: "  " + programName + " postman -o unpack --base-url https://api.example.com",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		baseUrl, _ := cmd.Flags().GetString("base-url")
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		for _, dir := range dirs {
+			manifest, err := util.ReadManifest(dir)
+			util.Fatal(err)
+			modules, err := util.LoadModules(dir)
+			util.Fatal(err)
+
+			var collection = util.NewPostmanCollection(manifest.Wxid+" "+filepath.Base(dir),
+				util.T("generated from the wx.request calls statically, the dynamic parts are named after the expressions"), baseUrl)
+			var count = 0
+			for _, m := range modules {
+				for _, call := range util.FindRequests(m.Code) {
+					collection.AddRequest(call, m.Name, fmt.Sprintf("%s:%d", m.Name, call.Line))
+					count++
+				}
+			}
+
+			var path = filepath.Join(dir, "postman_collection.json")
+			util.Fatal(util.SaveJson(path, collection))
+			color.Cyan(util.T("[+] %d requests of '%s' in %d modules saved to '%s'\n"), count, dir, len(collection.Item), path)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(postmanCmd)
+
+	postmanCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	postmanCmd.Flags().String("base-url", "https://example.com", util.T("the base url of the requests whose host is dynamic, like 'https://api.example.com'"))
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/wux1an/wxapkg/util"
+)
+
+const progressBarWidth = 20
+
+// the progress modes
+const (
+	progressBar   = "bar"   // one line updated in place with '\r'
+	progressPlain = "plain" // one line every interval, for the CI logs
+	progressNone  = "none"
+)
+
+// the default intervals of the progress modes
+var progressIntervals = map[string]time.Duration{
+	progressBar:   100 * time.Millisecond,
+	progressPlain: 5 * time.Second,
+}
+
+// unpackProgress shows the overall progress of the packages and the file progress of the package
+// updated lately in one line.
+type unpackProgress struct {
+	locker sync.Mutex
+
+	packages     int
+	donePackages int
+	bytes        int64
+	doneBytes    int64
+
+	current   string // the package updated lately
+	files     int
+	doneFiles int
+
+	mode       string
+	interval   time.Duration // the minimum interval between the file progress updates
+	lastRender time.Time
+	lastLen    int // the length of the last rendered line
+}
+
+// newUnpackProgress returns the progress in the mode, the default interval of the mode is used if
+// interval is 0.
+func newUnpackProgress(packages int, bytes int64, mode string, interval time.Duration) *unpackProgress {
+	if interval <= 0 {
+		interval = progressIntervals[mode]
+	}
+
+	return &unpackProgress{packages: packages, bytes: bytes, mode: mode, interval: interval}
+}
+
+// fileDone updates the file progress of the package.
+func (p *unpackProgress) fileDone(pkg string, done, total int) {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	p.current, p.doneFiles, p.files = pkg, done, total
+	if time.Since(p.lastRender) >= p.interval {
+		p.render()
+	}
+}
+
+// packageDone counts the package of the given size as done, and prints the message above the progress.
+func (p *unpackProgress) packageDone(size int64, print func()) {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	p.donePackages++
+	p.doneBytes += size
+	p.clear()
+	print()
+	if p.mode == progressBar || time.Since(p.lastRender) >= p.interval {
+		p.render()
+	}
+}
+
+// finish clears the progress line.
+func (p *unpackProgress) finish() {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	p.clear()
+}
+
+func (p *unpackProgress) clear() {
+	if p.mode != progressBar {
+		return
+	}
+	fmt.Print("\r" + strings.Repeat(" ", p.lastLen) + "\r")
+	p.lastLen = 0
+}
+
+func (p *unpackProgress) render() {
+	if p.mode == progressNone {
+		return
+	}
+	p.lastRender = time.Now()
+
+	var filled = progressBarWidth
+	if p.bytes > 0 {
+		filled = int(int64(progressBarWidth) * p.doneBytes / p.bytes)
+	}
+	var bar = strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+
+	var line = fmt.Sprintf("[%s] "+util.T("%d/%d packages, %s/%s"), bar, p.donePackages, p.packages, formatSize(p.doneBytes), formatSize(p.bytes))
+	if p.current != "" {
+		line += fmt.Sprintf(" | %s %d/%d", filepath.Base(p.current), p.doneFiles, p.files)
+	}
+
+	if p.mode == progressPlain {
+		_, _ = color.New().Println(color.GreenString(line))
+		return
+	}
+
+	// overwrite the rest of the last line
+	var width = displayWidth(line)
+	if width < p.lastLen {
+		line += strings.Repeat(" ", p.lastLen-width)
+	}
+	_, _ = color.New().Print(color.GreenString("\r" + line))
+	p.lastLen = width
+}
+
+// displayWidth returns the width of s in the terminal, the CJK characters are 2 columns wide.
+func displayWidth(s string) int {
+	var width = 0
+	for _, r := range s {
+		if r >= 0x2E80 {
+			width += 2
+		} else {
+			width++
+		}
+	}
+
+	return width
+}
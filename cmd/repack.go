@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+	"github.com/wux1an/wxapkg/wxapkg"
+)
+
+var repackCmd = &cobra.Command{
+	Use:   "repack",
+	Short: util.T("Rebuild the wxapkg file from the modified unpacked folder, keeping the entry order and the layout of the original"),
+	Example: "  " + programName + " repack -i unpack/wx1234567890abcdef/12 -o out.wxapkg --like __APP__.wxapkg\n" +
+		"  " + programName + " repack -i modified -o out.wxapkg --like \"D:\\WeChat Files\\Applet\\wx1234567890abcdef\\12\\__APP__.wxapkg\"",
+	Run: func(cmd *cobra.Command, args []string) {
+		input, _ := cmd.Flags().GetString("input")
+		output, _ := cmd.Flags().GetString("output")
+		like, _ := cmd.Flags().GetString("like")
+		wxid, _ := cmd.Flags().GetString("wxid")
+		plain, _ := cmd.Flags().GetBool("plain")
+
+		if wxid == "" {
+			wxid = regAppId.FindString(like)
+		}
+
+		packer, changed, reformatted, err := repackDir(input, like, wxid)
+		util.Fatal(err)
+		if len(reformatted) > 0 {
+			color.Yellow(util.T("[!] %d modified files were beautified, normalized or transformed when unpacking, they are packed as they are: %s\n"), len(reformatted), strings.Join(reformatted, ", "))
+		}
+
+		var buf bytes.Buffer
+		_, err = packer.WriteTo(&buf)
+		util.Fatal(err)
+
+		// encrypted in the profile of the original, so that it can be put back to the applet folder
+		var data = buf.Bytes()
+		var profile = repackProfile(like)
+		if !plain && profile.Encrypted() {
+			if wxid == "" {
+				util.Fatal(errors.New(util.T("the wxid is required to encrypt the repacked file, set it by '--wxid' or '--plain' to skip the encryption")))
+			}
+			data, err = profile.Encrypt(wxid, data)
+			util.Fatal(err)
+		}
+		util.Fatal(util.WithExitCode(util.ExitIO, os.WriteFile(output, data, 0600)))
+
+		entries, _ := packer.Entries()
+		if !plain && profile.Encrypted() {
+			color.Cyan(util.T("[+] %d entries, %d changed, repacked and encrypted with the profile '%s' to '%s' (%s)\n"), len(entries), changed, profile.Name, output, formatSize(int64(len(data))))
+		} else {
+			color.Cyan(util.T("[+] %d entries, %d changed, repacked to '%s' (%s)\n"), len(entries), changed, output, formatSize(int64(len(data))))
+		}
+	},
+}
+
+// repackDir packs the files of the unpacked folder dir in the entry order and the layout of the original
+// wxapkg file, returns the packer, the count of the changed entries and the modified files which were
+// reformatted when unpacking. The files are found by the manifest of the folder, or by the entry names if
+// there is no manifest, the files unchanged since unpacking keep the original bodies.
+func repackDir(dir, original, wxid string) (*wxapkg.Packer, int, []string, error) {
+	pkg, closer, err := openPackage(wxid, original, true)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	defer closer.Close()
+
+	header, entries, err := readIndex(pkg)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	// the entry name -> the unpacked file of the original package
+	var files = make(map[string]util.ManifestFile)
+	if manifest, err := util.ReadManifest(dir); err == nil {
+		for _, f := range manifest.Files {
+			if path.Base(f.Package) != filepath.Base(original) {
+				continue
+			}
+			var name = f.Name
+			if f.Original != "" {
+				name = f.Original
+			}
+			files[name] = f
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, 0, nil, err
+	}
+
+	// the bodies in the order of the offsets, the entries sharing a body keep sharing it if unchanged
+	var order = make([]int, len(entries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return entries[order[i]].Offset < entries[order[j]].Offset
+	})
+
+	var packer = &wxapkg.Packer{Info1: header.Info1}
+	var refs = make([]int, len(entries))
+	var shared = make(map[[2]uint32]int) // the original offset and size -> the body
+	var bodies [][]byte
+	var missing, reformatted []string
+	var changed = 0
+	for _, i := range order {
+		var e = entries[i]
+		if e.IsPlaceholder() {
+			refs[i] = packer.AddBody(nil)
+			bodies = append(bodies, nil)
+			continue
+		}
+		data, unchanged, restorable, err := repackFile(dir, e.Name, files)
+		if os.IsNotExist(err) {
+			missing = append(missing, e.Name)
+			continue
+		}
+		if err != nil {
+			return nil, 0, nil, err
+		}
+
+		var old = make([]byte, e.Size)
+		if _, err := pkg.ReadAt(old, int64(e.Offset)); err != nil && e.Size > 0 {
+			return nil, 0, nil, util.WithExitCode(util.ExitInvalidPackage, fmt.Errorf("%s: %w", e.Name, err))
+		}
+		if unchanged {
+			data = old
+		} else if !restorable {
+			reformatted = append(reformatted, e.Name)
+		}
+		if !bytes.Equal(old, data) {
+			changed++
+		}
+
+		var key = [2]uint32{e.Offset, e.Size}
+		if id, ok := shared[key]; ok && bytes.Equal(bodies[id], data) {
+			refs[i] = id
+			continue
+		}
+		refs[i] = packer.AddBody(data)
+		bodies = append(bodies, data)
+		shared[key] = refs[i]
+	}
+	if len(missing) > 0 {
+		return nil, 0, nil, util.WithExitCode(util.ExitIO, fmt.Errorf(util.T("%d files of the original package are missing in '%s': %s"), len(missing), dir, strings.Join(missing, ", ")))
+	}
+
+	for i, e := range entries {
+		packer.AddEntry(e.Name, refs[i])
+	}
+
+	return packer, changed, reformatted, nil
+}
+
+// repackProfile returns the profile of the original wxapkg file, or the one selected by '--profile'.
+func repackProfile(original string) *wxapkg.Profile {
+	var head = make([]byte, wxapkg.EncryptedHeadSize)
+	if f, err := util.OpenSharedFile(original); err == nil {
+		n, _ := f.ReadAt(head, 0)
+		head = head[:n]
+		_ = f.Close()
+	}
+
+	return packageProfile(head)
+}
+
+// repackFile reads the file of the entry, the charset and the BOM recorded in the manifest are restored. The
+// file is unchanged if it's not modified since unpacking, so the original body is kept, and it's not
+// restorable if it was reformatted or transformed when unpacking, see util.ManifestFile.Restorable.
+func repackFile(dir, name string, files map[string]util.ManifestFile) (data []byte, unchanged, restorable bool, err error) {
+	f, ok := files[name]
+	if !ok {
+		data, err = os.ReadFile(filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(name, "/"))))
+		return data, false, true, err
+	}
+
+	if data, err = os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path))); err != nil {
+		return nil, false, false, err
+	}
+	if util.Sha256(data) == f.Sha256 {
+		return data, true, f.Restorable(), nil
+	}
+
+	if f.Transcoded {
+		if data, err = util.FromUtf8(data, f.Charset); err != nil {
+			return nil, false, false, fmt.Errorf("%s: %w", f.Path, err)
+		}
+	}
+	// the BOM may be stripped or added by '--bom'
+	if util.IsTextFile(f.Path, data) {
+		if data, _ = util.TrimBom(data); f.Bom {
+			data = util.AddBom(data)
+		}
+	}
+
+	return data, false, f.Restorable(), nil
+}
+
+func init() {
+	RootCmd.AddCommand(repackCmd)
+
+	repackCmd.Flags().StringP("input", "i", "", util.T("the modified unpacked folder"))
+	repackCmd.Flags().StringP("output", "o", "", util.T("the repacked wxapkg file"))
+	repackCmd.Flags().String("like", "", util.T("the original wxapkg file whose entry order and layout are kept"))
+	repackCmd.Flags().String("wxid", "", util.T("the wxid to decrypt the original wxapkg file, detected from the path by default"))
+	repackCmd.Flags().Bool("plain", false, util.T("write the decrypted wxapkg file even if the original is encrypted"))
+	_ = repackCmd.MarkFlagRequired("input")
+	_ = repackCmd.MarkFlagRequired("output")
+	_ = repackCmd.MarkFlagRequired("like")
+}
@@ -0,0 +1,441 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:     "restore",
+	Short:   util.T("Restore the source structure of the unpacked mini program"),
+	Example: "  " + programName + " restore -o unpack",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		openDevtools, _ := cmd.Flags().GetBool("open-devtools")
+		devtoolsCli, _ := cmd.Flags().GetString("devtools-cli")
+
+		beautifyExts, err := beautifyExtensions(cmd)
+		util.Fatal(err)
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+		unpacked, err := latestUnpackedDirs(dirs)
+		util.Fatal(err)
+
+		for _, dir := range dirs {
+			config, err := util.ReadAppConfig(dir)
+			if err != nil {
+				color.Yellow(util.T("[!] skip '%s': %v\n"), dir, err)
+				continue
+			}
+
+			// the client only downloads the subpackages when they are opened
+			missing, err := missingSubPackages(dir, config)
+			util.Fatal(err)
+			for _, sub := range config.SubPackages {
+				if missing[sub.Root] {
+					color.Yellow(util.T("[!] subpackage '%s' is not downloaded, %d routes are unavailable: %s\n"), sub.Root, len(sub.Pages), strings.Join(subPackageRoutes(sub), ", "))
+				}
+			}
+
+			modules, err := restoreModules(dir, beautifyExts[".js"])
+			util.Fatal(err)
+			color.Cyan(util.T("[+] %d modules restored from the bundles in '%s'\n"), modules, dir)
+
+			components, err := restoreComponents(dir, config, missing)
+			util.Fatal(err)
+			color.Cyan(util.T("[+] %d components restored in '%s'\n"), components, dir)
+
+			configs, err := restoreConfigs(dir, config, missing)
+			util.Fatal(err)
+			color.Cyan(util.T("[+] 'app.json' and %d page configs restored in '%s'\n"), configs, dir)
+
+			plugins, err := restorePlugins(dir, config, unpacked)
+			util.Fatal(err)
+			for _, p := range plugins {
+				if p.Source == "" {
+					color.Yellow(util.T("[!] plugin '%s' (%s) is not unpacked, the references to it are not resolved\n"), p.Alias, p.Provider)
+				} else {
+					color.Cyan(util.T("[+] plugin '%s' copied from '%s' to '%s'\n"), p.Alias, p.Source, p.Path)
+				}
+			}
+
+			manifest, err := util.ReadManifest(dir)
+			util.Fatal(err)
+			projects, err := restoreProjectFiles(dir, manifest.Wxid, manifest.Wxid+"-"+filepath.Base(dir))
+			util.Fatal(err)
+			color.Cyan(util.T("[+] %d project files created in '%s'\n"), projects, dir)
+
+			for _, sub := range config.SubPackages {
+				if !sub.Independent || missing[sub.Root] {
+					continue
+				}
+				target, err := restoreIndependent(dir, config, sub, manifest.Wxid)
+				util.Fatal(err)
+				color.Cyan(util.T("[+] independent subpackage '%s' restored as the project '%s'\n"), sub.Root, target)
+			}
+
+			if openDevtools {
+				color.Cyan(util.T("[+] open '%s' in wechat devtools\n"), dir)
+				util.Fatal(util.OpenInDevtools(devtoolsCli, dir))
+			}
+		}
+	},
+}
+
+// restoreModules writes every module defined in the bundles to its own file.
+func restoreModules(dir string, beautify bool) (int, error) {
+	modules, err := util.LoadModules(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var count = 0
+	for _, m := range modules {
+		var code = m.Code
+		if beautify {
+			code = util.PrettyJavaScript(code)
+		}
+		written, err := util.WriteFileIfNotExist(filepath.Join(dir, filepath.FromSlash(m.Name)), code)
+		if err != nil {
+			return count, err
+		}
+		if written {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// missingSubPackages returns the roots of the subpackages not unpacked, which are not downloaded by the client.
+func missingSubPackages(dir string, config *util.AppConfig) (map[string]bool, error) {
+	manifest, err := util.ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing = make(map[string]bool)
+	for _, sub := range config.SubPackages {
+		missing[sub.Root] = true
+	}
+	for _, f := range manifest.Files {
+		if sub := config.SubPackageOf(f.Path); sub != nil {
+			delete(missing, sub.Root)
+		}
+	}
+
+	return missing, nil
+}
+
+// subPackageRoutes returns the routes of the pages in the subpackage, like 'sub/shop/list'.
+func subPackageRoutes(sub util.SubPackage) []string {
+	var routes = make([]string, 0, len(sub.Pages))
+	for _, page := range sub.Pages {
+		if strings.HasPrefix(page, strings.TrimSuffix(sub.Root, "/")+"/") {
+			routes = append(routes, page)
+		} else {
+			routes = append(routes, path.Join(sub.Root, page))
+		}
+	}
+
+	return routes
+}
+
+// restoreComponents makes sure every custom component declared by the pages has
+// its js, json, wxml and wxss files, the missing template and style are left empty.
+// The pages and the components in the missing subpackages are skipped.
+func restoreComponents(dir string, config *util.AppConfig, missing map[string]bool) (int, error) {
+	var components = make(map[string]bool)
+	var queue = append([]string{}, config.Pages...)
+	for len(queue) > 0 {
+		var page = queue[0]
+		queue = queue[1:]
+		if sub := config.SubPackageOf(page); sub != nil && missing[sub.Root] {
+			continue
+		}
+
+		for _, p := range config.UsingComponents(page) {
+			var component = resolveComponent(dir, page, p)
+			if component == "" || components[component] {
+				continue
+			}
+			components[component] = true
+			queue = append(queue, component) // components can use components
+		}
+	}
+
+	var names = make([]string, 0, len(components))
+	for c := range components {
+		names = append(names, c)
+	}
+	sort.Strings(names)
+
+	for _, c := range names {
+		var base = filepath.Join(dir, filepath.FromSlash(c))
+
+		var jsonConfig = map[string]interface{}{"component": true}
+		if using := config.Page[c+".html"].Window["usingComponents"]; using != nil {
+			jsonConfig["usingComponents"] = using
+		}
+		data, _ := util.MarshalJson(jsonConfig)
+
+		var files = map[string][]byte{
+			".js":   []byte("Component({})\n"), // the module is not found in the bundles
+			".json": data,
+			".wxml": nil,
+			".wxss": nil,
+		}
+		for ext, content := range files {
+			if _, err := util.WriteFileIfNotExist(base+ext, content); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return len(names), nil
+}
+
+// the keys only used by the compiled 'app-config.json'
+var compiledConfigKeys = []string{"page", "global", "ext", "extAppid"}
+
+// restoreConfigs splits the merged 'app-config.json' to 'app.json' and the page configs, the missing
+// subpackages are marked with '"missing": true' in 'app.json'.
+func restoreConfigs(dir string, config *util.AppConfig, missing map[string]bool) (int, error) {
+	data, err := os.ReadFile(filepath.Join(dir, util.AppConfigName))
+	if err != nil {
+		return 0, err
+	}
+
+	var app map[string]interface{}
+	if err := util.UnmarshalJson(data, &app); err != nil {
+		return 0, err
+	}
+
+	for _, key := range compiledConfigKeys {
+		delete(app, key)
+	}
+
+	var window = make(map[string]interface{})
+	for k, v := range config.Global.Window {
+		window[k] = v
+	}
+	if using, ok := window["usingComponents"]; ok {
+		app["usingComponents"] = using
+		delete(window, "usingComponents")
+	}
+	if len(window) > 0 {
+		app["window"] = window
+	}
+
+	if config.EntryPagePath != "" {
+		app["entryPagePath"] = config.EntryPage()
+	}
+
+	// the pages of the subpackages are declared in 'subPackages'
+	var pages = make([]string, 0, len(config.Pages))
+	for _, page := range config.Pages {
+		if config.SubPackageOf(page) == nil {
+			pages = append(pages, page)
+		}
+	}
+	app["pages"] = pages
+
+	if subs, ok := app["subPackages"].([]interface{}); ok {
+		for _, sub := range subs {
+			if sub, ok := sub.(map[string]interface{}); ok && missing[fmt.Sprint(sub["root"])] {
+				sub["missing"] = true
+			}
+		}
+	}
+
+	data, _ = util.MarshalJson(app)
+	if _, err := util.WriteFileIfNotExist(filepath.Join(dir, "app.json"), data); err != nil {
+		return 0, err
+	}
+
+	var count = 0
+	for name, page := range config.Page {
+		if page.Window == nil {
+			continue
+		}
+		if sub := config.SubPackageOf(strings.TrimSuffix(name, ".html")); sub != nil && missing[sub.Root] {
+			continue
+		}
+
+		data, _ := util.MarshalJson(page.Window)
+		var path = filepath.Join(dir, filepath.FromSlash(strings.TrimSuffix(name, ".html"))+".json")
+		written, err := util.WriteFileIfNotExist(path, data)
+		if err != nil {
+			return count, err
+		}
+		if written {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+const eslintrc = `module.exports = {
+  root: true,
+  env: {
+    es6: true,
+    browser: true,
+    node: true,
+  },
+  parserOptions: {
+    ecmaVersion: 2018,
+    sourceType: 'module',
+  },
+  globals: {
+    wx: true,
+    App: true,
+    Page: true,
+    Component: true,
+    Behavior: true,
+    getApp: true,
+    getCurrentPages: true,
+    requirePlugin: true,
+    requireMiniProgram: true,
+  },
+  rules: {},
+}
+`
+
+// restoreProjectFiles creates the files the wechat devtools expects when importing the project.
+func restoreProjectFiles(dir, wxid, name string) (int, error) {
+	var setting = map[string]interface{}{
+		"es6":          true,
+		"postcss":      false,
+		"minified":     false,
+		"urlCheck":     false,
+		"checkSiteMap": false,
+	}
+
+	var files = map[string]interface{}{
+		"project.config.json": map[string]interface{}{
+			"appid":       wxid,
+			"projectname": name,
+			"compileType": "miniprogram",
+			"setting":     setting,
+		},
+		"project.private.config.json": map[string]interface{}{
+			"projectname": name,
+			"setting":     map[string]interface{}{"urlCheck": false},
+		},
+		"sitemap.json": map[string]interface{}{
+			"rules": []map[string]string{{"action": "allow", "page": "*"}},
+		},
+	}
+
+	var count = 0
+	var write = func(name string, data []byte) error {
+		written, err := util.WriteFileIfNotExist(filepath.Join(dir, name), data)
+		if written {
+			count++
+		}
+		return err
+	}
+
+	for name, content := range files {
+		data, _ := util.MarshalJson(content)
+		if err := write(name, data); err != nil {
+			return count, err
+		}
+	}
+
+	return count, write(".eslintrc.js", []byte(eslintrc))
+}
+
+// restoreIndependent copies the independent subpackage, which runs without the main package, from the
+// restored folder dir to the standalone project next to it, like '<dir>-sub_shop', with its own 'app.json'.
+func restoreIndependent(dir string, config *util.AppConfig, sub util.SubPackage, wxid string) (string, error) {
+	var root = strings.Trim(sub.Root, "/")
+	var target = dir + "-" + strings.ReplaceAll(root, "/", "_")
+
+	var source = filepath.Join(dir, filepath.FromSlash(root))
+	err := filepath.WalkDir(source, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		_, err = util.WriteFileIfNotExist(filepath.Join(target, rel), data)
+		return err
+	})
+	if err != nil {
+		return target, err
+	}
+
+	var app = map[string]interface{}{"pages": subPackageRoutes(sub)}
+	if len(config.Global.Window) > 0 {
+		var window = make(map[string]interface{})
+		for k, v := range config.Global.Window {
+			if k != "usingComponents" { // the global components are in the main package
+				window[k] = v
+			}
+		}
+		app["window"] = window
+	}
+	if len(sub.Plugins) > 0 {
+		app["plugins"] = sub.Plugins
+	}
+	data, _ := util.MarshalJson(app)
+	if _, err := util.WriteFileIfNotExist(filepath.Join(target, "app.json"), data); err != nil {
+		return target, err
+	}
+	if _, err := util.WriteFileIfNotExist(filepath.Join(target, "app.js"), []byte("App({})\n")); err != nil {
+		return target, err
+	}
+
+	_, err = restoreProjectFiles(target, wxid, wxid+"-"+filepath.Base(target))
+	return target, err
+}
+
+// resolveComponent resolves the component path declared by the page, returns "" for plugins.
+func resolveComponent(dir, page, p string) string {
+	if strings.HasPrefix(p, "plugin://") || strings.HasPrefix(p, "plugin-private://") {
+		return ""
+	}
+
+	var candidates []string
+	if strings.HasPrefix(p, "/") {
+		candidates = []string{p}
+	} else if strings.HasPrefix(p, ".") {
+		candidates = []string{path.Join(path.Dir(page), p)}
+	} else { // relative path or npm package
+		candidates = []string{path.Join(path.Dir(page), p), path.Join("miniprogram_npm", p), p}
+	}
+
+	for i, c := range candidates {
+		candidates[i] = strings.TrimPrefix(path.Clean("/"+c), "/")
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(candidates[i])+".js")); err == nil {
+			return candidates[i]
+		}
+	}
+
+	return candidates[0]
+}
+
+func init() {
+	RootCmd.AddCommand(restoreCmd)
+
+	restoreCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	restoreCmd.Flags().Bool("open-devtools", false, util.T("open the restored project in wechat devtools"))
+	restoreCmd.Flags().String("devtools-cli", "", util.T("the path of wechat devtools cli, detected if empty"))
+}
@@ -1,26 +1,70 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+	"github.com/wux1an/wxapkg/wxapkg"
 )
 
 var RootCmd = &cobra.Command{
 	Use:   "wxapkg",
-	Short: "A tool to scan and decrypt wechat mini program",
+	Short: util.T("A tool to scan and decrypt wechat mini program"),
 	CompletionOptions: cobra.CompletionOptions{
 		DisableDefaultCmd: true,
 	},
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		jsonIndent, _ := cmd.Flags().GetInt("json-indent")
+		jsonSortKeys, _ := cmd.Flags().GetBool("json-sort-keys")
+
+		util.JsonOptions.Indent = strings.Repeat(" ", jsonIndent)
+		util.JsonOptions.SortKeys = jsonSortKeys
+
+		decryptor, _ = cmd.Flags().GetString("decryptor")
+		if profile, _ := cmd.Flags().GetString("profile"); profile != "auto" {
+			var err error
+			cryptoProfile, err = wxapkg.LookupProfile(profile)
+			if err != nil {
+				util.Fatal(fmt.Errorf(util.T("unknown profile '%s', 'auto' or %s expected"), profile, profileNames()))
+			}
+		}
+	},
+}
+
+// profileNames returns the names and the aliases of the known profiles, like "'windows-3.x', 'mac-3.8'".
+func profileNames() string {
+	var names []string
+	for _, p := range wxapkg.Profiles {
+		names = append(names, "'"+p.Name+"'")
+	}
+	var aliases []string
+	for alias := range wxapkg.ProfileAliases {
+		aliases = append(aliases, "'"+alias+"'")
+	}
+	sort.Strings(aliases)
+	names = append(names, aliases...)
+
+	return strings.Join(names, ", ")
 }
 
 func Execute() {
 	err := RootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(util.ExitFailure)
 	}
 }
 
 func init() {
-	RootCmd.PersistentFlags().Bool("disable-beautify", false, "disable js,html,json beautify")
+	RootCmd.PersistentFlags().Bool("disable-beautify", false, util.T("disable js,html,json beautify"))
+	RootCmd.PersistentFlags().StringSlice("beautify-only", nil, util.T("only beautify the given types, like 'js,json'"))
+	RootCmd.PersistentFlags().StringSlice("no-beautify", nil, util.T("do not beautify the given types, like 'html'"))
+	RootCmd.PersistentFlags().Int("json-indent", 2, util.T("the indent width of the beautified json"))
+	RootCmd.PersistentFlags().Bool("json-sort-keys", false, util.T("sort the keys of the beautified json, for the stable diffs"))
+	RootCmd.PersistentFlags().String("profile", "auto", util.T("the decryption profile of the WeChat build, 'auto' detects it from the packages, or ")+profileNames())
+	RootCmd.PersistentFlags().String("decryptor", "", util.T("the external command to decrypt the packages in the unknown formats, it reads the encrypted data from stdin and writes the decrypted data to stdout, '{wxid}' in it is replaced with the wxid"))
+	RootCmd.PersistentFlags().String("lang", util.Lang, util.T("the language of the messages, 'en' or 'zh'"))
 }
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var routesCmd = &cobra.Command{
+	Use:   "routes",
+	Short: util.T("Generate the route map of the unpacked mini program from 'app-config.json'"),
+	Example: "  " + programName + " routes -o unpack -f md\n" +
+		"  " + programName + " routes -o unpack -f mermaid",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		format, _ := cmd.Flags().GetString("format")
+
+		if format != "json" && format != "md" && format != "mermaid" {
+			util.Fatal(fmt.Errorf(util.T("unknown routes format '%s', only 'json', 'md' and 'mermaid' are supported"), format))
+		}
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		for _, dir := range dirs {
+			config, err := util.ReadAppConfig(dir)
+			if err != nil {
+				color.Yellow(util.T("[!] skip '%s': %v\n"), dir, err)
+				continue
+			}
+
+			modules, err := util.LoadModules(dir)
+			util.Fatal(err)
+
+			var r = newRouteMap(config)
+			r.Navigations = findNavigations(modules)
+			var path = filepath.Join(dir, "routes."+format)
+			switch format {
+			case "json":
+				util.Fatal(util.SaveJson(path, r))
+			case "md":
+				util.Fatal(os.WriteFile(path, r.markdown(), 0600))
+			case "mermaid":
+				path = filepath.Join(dir, "routes.mmd")
+				util.Fatal(os.WriteFile(path, r.mermaid(), 0600))
+			}
+
+			color.Cyan(util.T("[+] %d pages, %d tabs, %d subpackages, %d navigations saved to '%s'\n"), len(r.Pages), len(r.Tabs), len(r.SubPackages), len(r.Navigations), path)
+		}
+	},
+}
+
+type routeSubPackage struct {
+	Root  string   `json:"root"`
+	Entry string   `json:"entry"`
+	Pages []string `json:"pages"`
+}
+
+// routeNavigation is a navigation in the code, like `wx.navigateTo({url: '/pages/logs/logs'})`.
+type routeNavigation struct {
+	From   string `json:"from"` // the module without '.js'
+	To     string `json:"to"`   // the page without the query
+	Method string `json:"method"`
+}
+
+type routeMap struct {
+	Entry       string            `json:"entry"`
+	Pages       []string          `json:"pages"`
+	Tabs        []util.TabBarItem `json:"tabs"`
+	SubPackages []routeSubPackage `json:"subPackages"`
+	Navigations []routeNavigation `json:"navigations"`
+}
+
+var regNavigate = regexp.MustCompile(`\bwx\.(navigateTo|redirectTo|reLaunch|switchTab)\(\s*\{[^}]*?\burl\s*:\s*["'` + "`" + `]([^"'` + "`" + `]+)`)
+
+// findNavigations finds the navigations with the literal urls in the modules.
+func findNavigations(modules []util.Module) []routeNavigation {
+	var result = []routeNavigation{}
+	var seen = make(map[routeNavigation]bool)
+	for _, m := range modules {
+		var from = strings.TrimSuffix(m.Name, ".js")
+		for _, match := range regNavigate.FindAllSubmatch(m.Code, -1) {
+			var url = string(match[2])
+			if i := strings.IndexAny(url, "?$#"); i >= 0 { // the query and the template literal
+				url = url[:i]
+			}
+			if url == "" {
+				continue
+			}
+			if !strings.HasPrefix(url, "/") {
+				url = path.Join(path.Dir(from), url)
+			}
+
+			var n = routeNavigation{From: from, To: strings.TrimPrefix(path.Clean("/"+url), "/"), Method: string(match[1])}
+			if !seen[n] {
+				seen[n] = true
+				result = append(result, n)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].From != result[j].From {
+			return result[i].From < result[j].From
+		}
+		return result[i].To < result[j].To
+	})
+
+	return result
+}
+
+func newRouteMap(config *util.AppConfig) *routeMap {
+	var r = &routeMap{
+		Entry: config.EntryPage(),
+		Pages: config.Pages,
+		Tabs:  config.TabBar.List,
+	}
+
+	for _, sub := range config.SubPackages {
+		var s = routeSubPackage{Root: sub.Root}
+		for _, p := range sub.Pages {
+			s.Pages = append(s.Pages, path.Join(sub.Root, p))
+		}
+		if len(s.Pages) > 0 {
+			s.Entry = s.Pages[0]
+		}
+		r.SubPackages = append(r.SubPackages, s)
+	}
+
+	return r
+}
+
+func (r *routeMap) markdown() []byte {
+	var b strings.Builder
+
+	b.WriteString("# Routes\n\n")
+	b.WriteString(fmt.Sprintf("Entry: `%s`\n\n", r.Entry))
+
+	b.WriteString("## Pages\n\n")
+	for _, p := range r.Pages {
+		b.WriteString(fmt.Sprintf("- `%s`\n", p))
+	}
+
+	if len(r.Tabs) > 0 {
+		b.WriteString("\n## TabBar\n\n| Text | Page |\n| --- | --- |\n")
+		for _, t := range r.Tabs {
+			b.WriteString(fmt.Sprintf("| %s | `%s` |\n", t.Text, t.PagePath))
+		}
+	}
+
+	for _, s := range r.SubPackages {
+		b.WriteString(fmt.Sprintf("\n## SubPackage `%s`\n\nEntry: `%s`\n\n", s.Root, s.Entry))
+		for _, p := range s.Pages {
+			b.WriteString(fmt.Sprintf("- `%s`\n", p))
+		}
+	}
+
+	if len(r.Navigations) > 0 {
+		b.WriteString("\n## Navigation\n\n| From | To | Method |\n| --- | --- | --- |\n")
+		for _, n := range r.Navigations {
+			b.WriteString(fmt.Sprintf("| `%s` | `%s` | %s |\n", n.From, n.To, n.Method))
+		}
+		b.WriteString("\n```mermaid\n")
+		b.Write(r.mermaid())
+		b.WriteString("```\n")
+	}
+
+	return []byte(b.String())
+}
+
+// mermaid returns the mermaid flowchart of the navigations, the tabs and the entry page.
+func (r *routeMap) mermaid() []byte {
+	var b strings.Builder
+	var ids = make(map[string]string)
+	var node = func(page string) string {
+		if id, ok := ids[page]; ok {
+			return id
+		}
+		var id = fmt.Sprintf("p%d", len(ids))
+		ids[page] = id
+		b.WriteString(fmt.Sprintf("  %s[\"%s\"]\n", id, page))
+		return id
+	}
+
+	b.WriteString("graph LR\n")
+	if r.Entry != "" {
+		b.WriteString(fmt.Sprintf("  entry((entry)) --> %s\n", node(r.Entry)))
+	}
+	for _, t := range r.Tabs {
+		var page = strings.TrimSuffix(t.PagePath, ".html")
+		b.WriteString(fmt.Sprintf("  tabbar{{tabBar}} -.->|%s| %s\n", strings.ReplaceAll(t.Text, "|", " "), node(page)))
+	}
+	for _, n := range r.Navigations {
+		var from, to = node(n.From), node(n.To)
+		b.WriteString(fmt.Sprintf("  %s -->|%s| %s\n", from, n.Method, to))
+	}
+
+	return []byte(b.String())
+}
+
+func init() {
+	RootCmd.AddCommand(routesCmd)
+
+	routesCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	routesCmd.Flags().StringP("format", "f", "json", util.T("the routes format, 'json', 'md' or 'mermaid'"))
+}
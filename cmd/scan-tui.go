@@ -40,9 +40,9 @@ func newScanTui(wxidInfo []util.WxidInfo) *scanTui {
 
 	var title = color.New(color.FgMagenta, color.Bold).Sprint
 	columns := []table.Column{
-		{Title: title("Name"), Width: 20},
-		{Title: title("Developer"), Width: 30},
-		{Title: title("Description"), Width: 40},
+		{Title: title(util.T("Name")), Width: 20},
+		{Title: title(util.T("Developer")), Width: 30},
+		{Title: title(util.T("Description")), Width: 40},
 	}
 	prog.Width = 0
 	for _, c := range columns {
@@ -152,19 +152,19 @@ func (s *scanTui) renderHelp() string {
 	return help.New().ShortHelpView([]key.Binding{
 		key.NewBinding(
 			key.WithKeys("enter"),
-			key.WithHelp("enter", "unpack"),
+			key.WithHelp("enter", util.T("unpack")),
 		),
 		key.NewBinding(
 			key.WithKeys("up", "k"),
-			key.WithHelp("↑/k", "move up"),
+			key.WithHelp("↑/k", util.T("move up")),
 		),
 		key.NewBinding(
 			key.WithKeys("down", "j"),
-			key.WithHelp("↓/j", "move down"),
+			key.WithHelp("↓/j", util.T("move down")),
 		),
 		key.NewBinding(
 			key.WithKeys("q", "ctrl+c"),
-			key.WithHelp("q", "exit"),
+			key.WithHelp("q", util.T("exit")),
 		),
 	})
 }
@@ -8,13 +8,11 @@ import (
 	"github.com/wux1an/wxapkg/util"
 	"os"
 	"path/filepath"
-	"regexp"
-	"runtime"
 )
 
 var scanCmd = &cobra.Command{
 	Use:     "scan",
-	Short:   "Scan the wechat mini program",
+	Short:   util.T("Scan the wechat mini program"),
 	Example: "  " + programName + " scan -r \"D:\\WeChat Files\\Applet\\wx12345678901234\"",
 	Run: func(cmd *cobra.Command, args []string) {
 		root, err := cmd.Flags().GetString("root")
@@ -22,8 +20,9 @@ var scanCmd = &cobra.Command{
 			color.Red("%v", err)
 			return
 		}
-
-		var regAppId = regexp.MustCompile(`(wx[0-9a-f]{16})`)
+		if root == "" {
+			root = util.DefaultAppletRoot()
+		}
 
 		var files []os.DirEntry
 		if files, err = os.ReadDir(root); err != nil {
@@ -70,12 +69,5 @@ var scanCmd = &cobra.Command{
 func init() {
 	RootCmd.AddCommand(scanCmd)
 
-	var homeDir, _ = os.UserHomeDir()
-	var defaultRoot = filepath.Join(homeDir, "Documents/WeChat Files/Applet")
-
-	if runtime.GOOS == "darwin" {
-		defaultRoot = filepath.Join(homeDir, "Library/Containers/com.tencent.xinWeChat/Data/.wxapplet/packages/")
-	}
-
-	scanCmd.Flags().StringP("root", "r", defaultRoot, "the mini app path")
+	scanCmd.Flags().StringP("root", "r", "", util.T("the mini app path, discovered if empty"))
 }
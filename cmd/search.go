@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+// the maximum length of the matched line shown
+const searchSnippetLength = 160
+
+var searchCmd = &cobra.Command{
+	Use:     "search <query>",
+	Short:   util.T("Search the unpacked mini programs with the index built by the index command"),
+	Example: "  " + programName + " search -o unpack api.example.com",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		index, err := util.ReadIndex(output)
+		if os.IsNotExist(err) {
+			util.Fatal(fmt.Errorf(util.T("no index in '%s', run '%s index -o %s' first"), output, programName, output))
+		}
+		util.Fatal(err)
+
+		var query = strings.ToLower(args[0])
+		var matches = 0
+		var apps = make(map[string]bool)
+	docs:
+		for _, doc := range index.Candidates(query) {
+			var first = doc.Locations[0]
+			data, err := os.ReadFile(filepath.Join(output, filepath.FromSlash(first.Dir), filepath.FromSlash(first.Path)))
+			if err != nil {
+				color.Yellow(util.T("[!] the index is outdated, run the index command again: %v\n"), err)
+				continue
+			}
+
+			var lines = searchLines(data, query)
+			if len(lines) == 0 {
+				continue
+			}
+			for _, location := range doc.Locations {
+				apps[location.Wxid] = true
+				for _, line := range lines {
+					if matches >= limit {
+						break docs
+					}
+					matches++
+					color.Cyan("%s  %s:%d\n", location.Wxid, path.Join(location.Dir, location.Path), line.number)
+					fmt.Printf("    %s\n", line.text)
+				}
+			}
+		}
+
+		color.Cyan(util.T("[+] %d matches in %d mini programs\n"), matches, len(apps))
+	},
+}
+
+type searchLine struct {
+	number int
+	text   string
+}
+
+// searchLines returns the lines containing the lower case query, the long lines are cut around the query.
+func searchLines(data []byte, query string) []searchLine {
+	var result []searchLine
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		var pos = bytes.Index(bytes.ToLower(line), []byte(query))
+		if pos < 0 {
+			continue
+		}
+
+		var text = strings.TrimSpace(string(line))
+		if len(line) > searchSnippetLength {
+			var start = pos - searchSnippetLength/2
+			if start < 0 {
+				start = 0
+			}
+			var end = start + searchSnippetLength
+			if end > len(line) {
+				end = len(line)
+			}
+			text = strings.ToValidUTF8("..."+string(line[start:end])+"...", "")
+		}
+		result = append(result, searchLine{number: i + 1, text: text})
+	}
+
+	return result
+}
+
+func init() {
+	RootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	searchCmd.Flags().Int("limit", 100, util.T("the maximum number of the matched lines"))
+}
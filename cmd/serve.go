@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var serveCmd = &cobra.Command{
+	Use:     "serve",
+	Short:   util.T("Serve the unpacked mini programs as a html site with the rough previews of the pages"),
+	Example: "  " + programName + " serve -o unpack --addr 127.0.0.1:8080",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		addr, _ := cmd.Flags().GetString("addr")
+		noPreview, _ := cmd.Flags().GetBool("no-preview")
+
+		// the site is exported to a temporary folder, which is removed when the server stops
+		site, err := os.MkdirTemp("", "wxapkg-serve-")
+		util.Fatal(util.WithExitCode(util.ExitIO, err))
+		util.OnFatal(func() { _ = os.RemoveAll(site) })
+
+		var signals = make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-signals
+			_ = os.RemoveAll(site)
+			os.Exit(util.ExitOK)
+		}()
+
+		exportSite(output, site, siteOptions{rewriteAssets: true, preview: !noPreview})
+		color.Cyan(util.T("[+] serving the site at 'http://%s/', press Ctrl+C to stop\n"), addr)
+		util.Fatal(http.ListenAndServe(addr, http.FileServer(http.Dir(site))))
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	serveCmd.Flags().String("addr", "127.0.0.1:8080", util.T("the address to listen on"))
+	serveCmd.Flags().Bool("no-preview", false, util.T("do not render the rough previews of the pages"))
+}
@@ -0,0 +1,410 @@
+package cmd
+
+import (
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var siteCmd = &cobra.Command{
+	Use:     "site",
+	Short:   util.T("Export the unpacked mini programs as a static html site"),
+	Example: "  " + programName + " site -o unpack --site site",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		site, _ := cmd.Flags().GetString("site")
+		rewriteAssets, _ := cmd.Flags().GetBool("rewrite-assets")
+		preview, _ := cmd.Flags().GetBool("preview")
+
+		exportSite(output, site, siteOptions{rewriteAssets: rewriteAssets, preview: preview})
+	},
+}
+
+// exportSite exports the unpacked folders in output as the static html site to the folder site.
+func exportSite(output, site string, options siteOptions) {
+	dirs, err := util.FindUnpackedDirs(output)
+	util.Fatal(err)
+
+	var apps []siteApp
+	for _, dir := range dirs {
+		manifest, err := util.ReadManifest(dir)
+		util.Fatal(err)
+
+		rel, err := filepath.Rel(output, dir)
+		util.Fatal(err)
+		if rel == "." {
+			rel = manifest.Wxid
+		}
+		var app = siteApp{
+			Wxid:    manifest.Wxid,
+			Version: filepath.Base(dir),
+			Time:    manifest.Time.Format("2006-01-02 15:04"),
+			Link:    filepath.ToSlash(rel) + "/index.html",
+			Files:   len(manifest.Files),
+		}
+		for _, f := range manifest.Files {
+			app.size += int64(f.Size)
+		}
+		app.Size = formatSize(app.size)
+
+		result, err := exportSiteApp(dir, filepath.Join(site, rel), app, manifest, options)
+		util.Fatal(err)
+		apps = append(apps, app)
+		color.Cyan(util.T("[+] %d files of '%s' exported\n"), app.Files, dir)
+		if options.rewriteAssets {
+			color.Cyan(util.T("[+] %d asset references in the styles and the templates rewritten to the local files\n"), result.rewritten)
+		}
+		if options.preview {
+			color.Cyan(util.T("[+] %d of %d pages previewed, the others have no wxml\n"), len(result.previews), result.pages)
+		}
+	}
+
+	sort.SliceStable(apps, func(i, j int) bool {
+		return apps[i].Wxid < apps[j].Wxid
+	})
+	var index = filepath.Join(site, "index.html")
+	util.Fatal(writeSitePage(index, siteIndexTemplate, map[string]interface{}{"Css": siteCss, "Apps": apps}))
+	color.Cyan(util.T("[+] the site of %d unpacked folders saved to '%s'\n"), len(apps), index)
+}
+
+// siteApp is an unpacked folder in the site.
+type siteApp struct {
+	Wxid    string
+	Version string
+	Time    string
+	Link    string // the index page relative to the site
+	Files   int
+	Size    string
+
+	size int64
+}
+
+// siteOptions are the optional parts of the exported site.
+type siteOptions struct {
+	rewriteAssets bool // rewrite the asset references in the raw styles and templates to the raw files
+	preview       bool // render the rough previews of the pages with the wxml
+}
+
+// siteResult is the summary of an exported folder.
+type siteResult struct {
+	rewritten int // the rewritten asset references
+	pages     int
+	previews  []sitePreview
+}
+
+// sitePreview is the rough preview of a page, see util.PreviewRenderer.
+type sitePreview struct {
+	Page string
+	Link string // the preview relative to the index
+}
+
+// siteNode is a file or folder in the file tree of the unpacked folder.
+type siteNode struct {
+	Name     string
+	Link     string // the page of the file relative to the index, empty for the folders
+	Children []*siteNode
+
+	children map[string]*siteNode
+}
+
+func (n *siteNode) add(p, link string) {
+	var parts = strings.SplitN(p, "/", 2)
+	if n.children == nil {
+		n.children = make(map[string]*siteNode)
+	}
+	var child = n.children[parts[0]]
+	if child == nil {
+		child = &siteNode{Name: parts[0]}
+		n.children[parts[0]] = child
+		n.Children = append(n.Children, child)
+	}
+
+	if len(parts) == 1 {
+		child.Link = link
+	} else {
+		child.add(parts[1], link)
+	}
+}
+
+// sort sorts the folders before the files, then by the names.
+func (n *siteNode) sort() {
+	sort.Slice(n.Children, func(i, j int) bool {
+		var a, b = n.Children[i], n.Children[j]
+		if (a.Link == "") != (b.Link == "") {
+			return a.Link == ""
+		}
+		return a.Name < b.Name
+	})
+	for _, child := range n.Children {
+		child.sort()
+	}
+}
+
+// siteKeyFile is the key file shown in the index page, the app config is embedded and the modules of
+// the app service link to their lines.
+type siteKeyFile struct {
+	Path    string
+	Link    string
+	Lines   []template.HTML
+	Modules []siteModule
+}
+
+type siteModule struct {
+	Name string
+	Line int
+}
+
+var siteImageExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".svg": true, ".bmp": true,
+}
+
+// exportSiteApp exports the unpacked folder dir to the folder of the site, the index page with the file
+// tree, the pages of the files and the raw files under 'raw/'. The asset references in the raw styles and
+// templates are rewritten to the raw files, and the pages are previewed under 'preview/', by the options.
+func exportSiteApp(dir, siteDir string, app siteApp, manifest *util.Manifest, options siteOptions) (siteResult, error) {
+	var result siteResult
+	var tree = &siteNode{}
+	var keys []siteKeyFile
+	var paths = make([]string, 0, len(manifest.Files))
+	for _, f := range manifest.Files {
+		paths = append(paths, f.Path)
+	}
+	var resolver = util.NewAssetResolver(paths)
+	for _, f := range manifest.Files {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+		if err != nil {
+			return result, err
+		}
+
+		var rawData = data
+		if options.rewriteAssets {
+			var n int
+			rawData, n = util.RewriteAssetRefs(f.Path, data, resolver)
+			result.rewritten += n
+		}
+		var raw = filepath.Join(siteDir, "raw", filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(raw), os.ModePerm); err != nil {
+			return result, err
+		}
+		if err := os.WriteFile(raw, rawData, 0600); err != nil {
+			return result, err
+		}
+
+		// the pages are in 'files/', the same depth as the raw files in 'raw/'
+		var up = strings.Repeat("../", strings.Count(f.Path, "/")+1)
+		var page = map[string]interface{}{
+			"Css":   siteCss,
+			"App":   app,
+			"Path":  f.Path,
+			"Size":  formatSize(int64(f.Size)),
+			"Index": up + "index.html",
+			"Raw":   up + "raw/" + f.Path,
+		}
+		var ext = strings.ToLower(path.Ext(f.Path))
+		if siteImageExtensions[ext] {
+			page["Image"] = true
+		} else if util.IsTextFile(f.Path, data) {
+			page["Lines"] = util.HighlightLines(f.Path, data)
+		}
+
+		if err := writeSitePage(filepath.Join(siteDir, "files", filepath.FromSlash(f.Path)+".html"), siteFileTemplate, page); err != nil {
+			return result, err
+		}
+		tree.add(f.Path, "files/"+f.Path+".html")
+
+		switch path.Base(f.Path) {
+		case "app-config.json":
+			keys = append(keys, siteKeyFile{Path: f.Path, Link: "files/" + f.Path + ".html", Lines: util.HighlightLines(f.Path, data)})
+		case "app-service.js":
+			var key = siteKeyFile{Path: f.Path, Link: "files/" + f.Path + ".html"}
+			for _, m := range util.SplitModules(data) {
+				key.Modules = append(key.Modules, siteModule{Name: m.Name, Line: m.Line})
+			}
+			keys = append(keys, key)
+		}
+	}
+	tree.sort()
+
+	if options.preview {
+		if err := exportSitePreviews(dir, siteDir, resolver, &result); err != nil {
+			return result, err
+		}
+	}
+
+	return result, writeSitePage(filepath.Join(siteDir, "index.html"), siteAppTemplate, map[string]interface{}{
+		"Css":      siteCss,
+		"App":      app,
+		"Packages": manifest.Packages,
+		"Tree":     tree.Children,
+		"Keys":     keys,
+		"Previews": result.previews,
+		"Site":     strings.Repeat("../", strings.Count(app.Link, "/")) + "index.html",
+	})
+}
+
+// exportSitePreviews renders the pages of the app config with the wxml to 'preview/<page>.html', the assets
+// link to the raw files.
+func exportSitePreviews(dir, siteDir string, resolver *util.AssetResolver, result *siteResult) error {
+	config, err := util.ReadAppConfig(dir)
+	if err != nil {
+		return err
+	}
+
+	var renderer = &util.PreviewRenderer{
+		ReadFile: func(name string) ([]byte, error) {
+			return os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		},
+		Components: func(from string) map[string]string {
+			var using = config.UsingComponents(from)
+			if data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(from)+".json")); err == nil {
+				var component struct {
+					UsingComponents map[string]string `json:"usingComponents"`
+				}
+				if util.UnmarshalJson(data, &component) == nil {
+					for tag, p := range component.UsingComponents {
+						using[tag] = p
+					}
+				}
+			}
+			var result = make(map[string]string, len(using))
+			for tag, p := range using {
+				result[tag] = resolveComponent(dir, from, p)
+			}
+			return result
+		},
+	}
+
+	for _, page := range config.Pages {
+		result.pages++
+		var link = "preview/" + page + ".html"
+		var up = strings.Repeat("../", strings.Count(link, "/"))
+		renderer.Asset = func(from, ref string) string {
+			if target := resolver.Resolve(from, ref); target != "" {
+				return up + "raw/" + target
+			}
+			return ""
+		}
+
+		data, err := renderer.Render(page)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		var name = filepath.Join(siteDir, filepath.FromSlash(link))
+		if err := os.MkdirAll(filepath.Dir(name), os.ModePerm); err != nil {
+			return err
+		}
+		if err := os.WriteFile(name, data, 0600); err != nil {
+			return err
+		}
+		result.previews = append(result.previews, sitePreview{Page: page, Link: link})
+	}
+
+	return nil
+}
+
+func writeSitePage(name string, t *template.Template, data interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(name), os.ModePerm); err != nil {
+		return err
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return t.Execute(f, data)
+}
+
+const siteCss = template.CSS(`body{font-family:-apple-system,"Segoe UI",sans-serif;margin:2em;color:#222}` +
+	`a{color:#0366d6;text-decoration:none}table{border-collapse:collapse}td,th{padding:4px 12px;text-align:left}` +
+	`tr:nth-child(even){background:#f6f8fa}ul.tree{list-style:none;padding-left:1.2em}summary{cursor:pointer}` +
+	`pre{background:#f6f8fa;padding:1em;overflow:auto;font-size:13px;line-height:1.5}img{max-width:100%}` +
+	`.l{display:block}.l:target{background:#fff8c5}.ln{display:inline-block;width:4em;margin-right:1em;` +
+	`text-align:right;color:#999;user-select:none}` +
+	util.HighlightCss)
+
+// siteCodeTemplate shows the highlighted lines with the line numbers, the line n has the anchor
+// '<prefix>L<n>' so that the findings can link to it.
+const siteCodeTemplate = `{{define "code"}}<pre><code>{{$prefix := .Prefix}}{{range $i, $line := .Lines}}` +
+	`<span class="l" id="{{$prefix}}L{{inc $i}}"><a class="ln" href="#{{$prefix}}L{{inc $i}}">{{inc $i}}</a>{{$line}}</span>` +
+	`{{end}}</code></pre>{{end}}`
+
+var siteFuncs = template.FuncMap{
+	"size": formatSize,
+	"inc": func(i int) int {
+		return i + 1
+	},
+	"code": func(prefix string, lines []template.HTML) map[string]interface{} {
+		return map[string]interface{}{"Prefix": prefix, "Lines": lines}
+	},
+}
+
+var siteIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>wxapkg</title><style>{{.Css}}</style></head>
+<body>
+<h1>wxapkg</h1>
+<table>
+<tr><th>wxid</th><th>version</th><th>time</th><th>files</th><th>size</th></tr>
+{{range .Apps}}<tr><td><a href="{{.Link}}">{{.Wxid}}</a></td><td>{{.Version}}</td><td>{{.Time}}</td><td>{{.Files}}</td><td>{{.Size}}</td></tr>
+{{end}}</table>
+</body></html>
+`))
+
+var siteAppTemplate = template.Must(template.New("app").Funcs(siteFuncs).Parse(siteCodeTemplate + `{{define "tree"}}<ul class="tree">
+{{range .}}{{if .Link}}<li><a href="{{.Link}}">{{.Name}}</a></li>
+{{else}}<li><details><summary>{{.Name}}/</summary>{{template "tree" .Children}}</details></li>
+{{end}}{{end}}</ul>{{end}}<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.App.Wxid}} {{.App.Version}}</title><style>{{.Css}}</style></head>
+<body>
+<p><a href="{{.Site}}">wxapkg</a></p>
+<h1>{{.App.Wxid}} {{.App.Version}}</h1>
+<p>{{.App.Time}}, {{.App.Files}} files, {{.App.Size}}</p>
+<table>
+<tr><th>package</th><th>files</th><th>size</th></tr>
+{{range .Packages}}<tr><td>{{.Package}}</td><td>{{.Files}}</td><td>{{size .Size}}</td></tr>
+{{end}}</table>
+{{if .Keys}}<h2>key files</h2>
+{{range .Keys}}<h3><a href="{{.Link}}">{{.Path}}</a></h3>
+{{if .Lines}}{{template "code" code (printf "%s-" .Path) .Lines}}
+{{end}}{{if .Modules}}<ul>
+{{$link := .Link}}{{range .Modules}}<li><a href="{{$link}}#L{{.Line}}">{{.Name}}</a> L{{.Line}}</li>
+{{end}}</ul>
+{{end}}{{end}}{{end}}{{if .Previews}}<h2>page previews</h2>
+<ul>
+{{range .Previews}}<li><a href="{{.Link}}">{{.Page}}</a></li>
+{{end}}</ul>
+{{end}}<h2>files</h2>
+{{template "tree" .Tree}}
+</body></html>
+`))
+
+var siteFileTemplate = template.Must(template.New("file").Funcs(siteFuncs).Parse(siteCodeTemplate + `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Path}}</title><style>{{.Css}}</style></head>
+<body>
+<p><a href="{{.Index}}">{{.App.Wxid}} {{.App.Version}}</a></p>
+<h1>{{.Path}}</h1>
+<p>{{.Size}}, <a href="{{.Raw}}">raw</a></p>
+{{if .Image}}<img src="{{.Raw}}" alt="{{.Path}}">
+{{else if .Lines}}{{template "code" code "" .Lines}}
+{{else}}<p>binary file</p>
+{{end}}</body></html>
+`))
+
+func init() {
+	RootCmd.AddCommand(siteCmd)
+
+	siteCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	siteCmd.Flags().String("site", "site", util.T("the folder to save the site"))
+	siteCmd.Flags().Bool("preview", false, util.T("render the recovered wxml and wxss of every page to html and css under 'preview/', a rough preview for mapping the code to the ui"))
+	siteCmd.Flags().Bool("rewrite-assets", false, util.T("rewrite the absolute and the remote references of the extracted assets in the raw wxss, css, wxml and html files, like url() and src, to the relative local paths, so that the images are rendered when they are opened locally"))
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var statsCmd = &cobra.Command{
+	Use:     "stats",
+	Short:   util.T("Show the statistics of the unpacked mini programs"),
+	Example: "  " + programName + " stats -o unpack",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		top, _ := cmd.Flags().GetInt("top")
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		var all = newFileStats()
+		var apps = make(map[string]*fileStats)
+		var largest []largestFile
+		for _, dir := range dirs {
+			manifest, err := util.ReadManifest(dir)
+			util.Fatal(err)
+
+			if apps[manifest.Wxid] == nil {
+				apps[manifest.Wxid] = newFileStats()
+			}
+			for _, f := range manifest.Files {
+				all.add(f)
+				apps[manifest.Wxid].add(f)
+				largest = append(largest, largestFile{path: filepath.Join(dir, filepath.FromSlash(f.Path)), size: f.Size})
+			}
+		}
+
+		color.Cyan(util.T("[+] %d files, %s in %d unpacked folders of %d mini programs\n"), all.count, formatSize(all.size), len(dirs), len(apps))
+
+		color.Cyan(util.T("[+] extension statistics:\n"))
+		for _, ext := range all.sortedExts() {
+			color.Cyan("  - %-8s %6d  %10s\n", ext, all.exts[ext].count, formatSize(all.exts[ext].size))
+		}
+
+		color.Cyan(util.T("[+] mini program statistics:\n"))
+		var wxids = make([]string, 0, len(apps))
+		for wxid := range apps {
+			wxids = append(wxids, wxid)
+		}
+		sort.Strings(wxids)
+		for _, wxid := range wxids {
+			color.Cyan("  - %s %6d  %10s\n", wxid, apps[wxid].count, formatSize(apps[wxid].size))
+		}
+
+		sort.Slice(largest, func(i, j int) bool {
+			return largest[i].size > largest[j].size
+		})
+		if len(largest) > top {
+			largest = largest[:top]
+		}
+		color.Cyan(util.T("[+] the largest %d files:\n"), len(largest))
+		for _, f := range largest {
+			color.Cyan("  - %10s  %s\n", formatSize(int64(f.size)), f.path)
+		}
+	},
+}
+
+type largestFile struct {
+	path string
+	size uint32
+}
+
+type extStats struct {
+	count int
+	size  int64
+}
+
+type fileStats struct {
+	count int
+	size  int64
+	exts  map[string]*extStats
+}
+
+func newFileStats() *fileStats {
+	return &fileStats{exts: make(map[string]*extStats)}
+}
+
+func (s *fileStats) add(f util.ManifestFile) {
+	s.count++
+	s.size += int64(f.Size)
+
+	var ext = filepath.Ext(f.Name)
+	if s.exts[ext] == nil {
+		s.exts[ext] = &extStats{}
+	}
+	s.exts[ext].count++
+	s.exts[ext].size += int64(f.Size)
+}
+
+// sortedExts returns the extensions sorted by the count desc.
+func (s *fileStats) sortedExts() []string {
+	var result = make([]string, 0, len(s.exts))
+	for ext := range s.exts {
+		result = append(result, ext)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return s.exts[result[i]].count > s.exts[result[j]].count
+	})
+
+	return result
+}
+
+func formatSize(size int64) string {
+	var units = []string{"B", "KB", "MB", "GB"}
+	var f = float64(size)
+	var i = 0
+	for f >= 1024 && i < len(units)-1 {
+		f /= 1024
+		i++
+	}
+
+	if i == 0 {
+		return fmt.Sprintf("%d %s", size, units[i])
+	}
+	return fmt.Sprintf("%.1f %s", f, units[i])
+}
+
+func init() {
+	RootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	statsCmd.Flags().IntP("top", "t", 10, util.T("the number of the largest files to show"))
+}
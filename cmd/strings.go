@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var stringsCmd = &cobra.Command{
+	Use:   "strings <file.wxapkg...>",
+	Short: util.T("Extract the printable strings from the binary files in the wxapkg files, like the images and wasm modules"),
+	Example: "  " + programName + " strings -n 8 --pattern 'https?://' " +
+		"\"D:\\WeChat Files\\Applet\\wx12345678901234\\12\\__APP__.wxapkg\"",
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		wxid, _ := cmd.Flags().GetString("wxid")
+		min, _ := cmd.Flags().GetInt("min")
+		pattern, _ := cmd.Flags().GetString("pattern")
+		encodings, _ := cmd.Flags().GetStringSlice("encoding")
+		all, _ := cmd.Flags().GetBool("all")
+
+		for _, encoding := range encodings {
+			if encoding != util.EncodingAscii && encoding != util.EncodingUtf8 && encoding != util.EncodingUtf16le {
+				util.Fatal(fmt.Errorf(util.T("invalid encoding '%s', 'ascii', 'utf-8' or 'utf-16le' expected"), encoding))
+			}
+		}
+		var reg *regexp.Regexp
+		if pattern != "" {
+			var err error
+			if reg, err = regexp.Compile(pattern); err != nil {
+				util.Fatal(fmt.Errorf(util.T("invalid regex '%s': %v"), pattern, err))
+			}
+		}
+
+		var found = 0
+		for _, path := range args {
+			var id = wxid
+			if id == "" {
+				id = regAppId.FindString(path)
+			}
+
+			pkg, closer, err := openPackage(id, path, true)
+			util.Fatal(util.WithFile(err, path))
+			_, files, err := readIndex(pkg)
+			util.Fatal(util.WithFile(err, path))
+
+			for _, f := range files {
+				var data = make([]byte, f.Size)
+				if _, err := pkg.ReadAt(data, int64(f.Offset)); err != nil {
+					util.Fatal(util.WithFile(util.WithExitCode(util.ExitIO, err), path))
+				}
+				if !all && util.IsTextFile(f.Name, data) {
+					continue
+				}
+
+				var header = false
+				for _, s := range util.ExtractStrings(data, min, encodings) {
+					if reg != nil && !reg.MatchString(s.Text) {
+						continue
+					}
+					if !header {
+						header = true
+						color.Yellow("[+] %s: %s\n", path, f.Name)
+					}
+					found++
+					fmt.Printf("  0x%08x  %-8s  %s\n", s.Offset, s.Encoding, s.Text)
+				}
+			}
+			_ = closer.Close()
+		}
+
+		color.Cyan(util.T("[+] %d strings found\n"), found)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(stringsCmd)
+
+	stringsCmd.Flags().String("wxid", "", util.T("the wxid to decrypt the wxapkg files, detected from the path if empty"))
+	stringsCmd.Flags().IntP("min", "n", 6, util.T("the minimum length of the strings"))
+	stringsCmd.Flags().String("pattern", "", util.T("only show the strings matching the regex"))
+	stringsCmd.Flags().StringSlice("encoding", []string{util.EncodingAscii, util.EncodingUtf8, util.EncodingUtf16le}, util.T("the encodings of the strings, 'ascii', 'utf-8' and 'utf-16le'"))
+	stringsCmd.Flags().Bool("all", false, util.T("extract the strings from the text files as well"))
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+var timelineCmd = &cobra.Command{
+	Use:     "timeline",
+	Short:   util.T("Show how the versions of the unpacked mini programs evolve"),
+	Example: "  " + programName + " timeline -o unpack",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		var apps = make(map[string][]timelineVersion)
+		for _, dir := range dirs {
+			manifest, err := util.ReadManifest(dir)
+			util.Fatal(err)
+
+			version, err := newTimelineVersion(dir, manifest)
+			util.Fatal(err)
+			apps[manifest.Wxid] = append(apps[manifest.Wxid], version)
+		}
+
+		var timelines = make([]appTimeline, 0, len(apps))
+		for wxid, versions := range apps {
+			timelines = append(timelines, newAppTimeline(wxid, versions))
+		}
+		sort.Slice(timelines, func(i, j int) bool {
+			return timelines[i].Wxid < timelines[j].Wxid
+		})
+
+		for _, t := range timelines {
+			color.Yellow("[+] %s\n", t.Wxid)
+			for _, v := range t.Versions {
+				color.Cyan(util.T("  - %s  %-6s %6d files (%+d)  %10s (%s)\n"), v.Time.Format("2006-01-02 15:04"), v.Version,
+					v.Files, v.FilesDelta, formatSize(v.Size), formatSizeDelta(v.SizeDelta))
+				for _, domain := range v.NewDomains {
+					color.Green("      + %s\n", domain)
+				}
+				for _, endpoint := range v.NewEndpoints {
+					color.Green("      + %s\n", endpoint)
+				}
+			}
+		}
+
+		var path = filepath.Join(output, "timeline.json")
+		util.Fatal(util.SaveJson(path, timelines))
+		color.Cyan(util.T("[+] timeline of %d mini programs saved to '%s'\n"), len(timelines), path)
+	},
+}
+
+type appTimeline struct {
+	Wxid     string            `json:"wxid"`
+	Versions []timelineVersion `json:"versions"`
+}
+
+type timelineVersion struct {
+	Version      string    `json:"version"`
+	Path         string    `json:"path"`
+	Time         time.Time `json:"time"`
+	Files        int       `json:"files"`
+	FilesDelta   int       `json:"files_delta"`
+	Size         int64     `json:"size"`
+	SizeDelta    int64     `json:"size_delta"`
+	NewDomains   []string  `json:"new_domains"`
+	NewEndpoints []string  `json:"new_endpoints"`
+
+	domains   []string
+	endpoints []string
+}
+
+func newTimelineVersion(dir string, manifest *util.Manifest) (timelineVersion, error) {
+	var v = timelineVersion{Version: filepath.Base(dir), Path: dir, Time: manifest.Time, Files: len(manifest.Files)}
+
+	// the manifests saved by the old versions have no time
+	if v.Time.IsZero() {
+		info, err := os.Stat(filepath.Join(dir, util.ManifestName))
+		if err != nil {
+			return v, err
+		}
+		v.Time = info.ModTime()
+	}
+
+	var urls []string
+	for _, f := range manifest.Files {
+		v.Size += int64(f.Size)
+
+		switch filepath.Ext(f.Path) {
+		case ".js", ".json", ".html", ".wxml", ".wxs":
+		default:
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+		if err != nil {
+			return v, err
+		}
+		urls = append(urls, util.FindUrls(data)...)
+	}
+
+	for _, s := range urls {
+		u, err := url.Parse(s)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		v.domains = append(v.domains, u.Host)
+		v.endpoints = append(v.endpoints, strings.TrimSuffix(u.Scheme+"://"+u.Host+u.Path, "/"))
+	}
+	v.domains = util.UniqueSorted(v.domains)
+	v.endpoints = util.UniqueSorted(v.endpoints)
+
+	return v, nil
+}
+
+// newAppTimeline sorts the versions by time and compares every version with the previous one.
+func newAppTimeline(wxid string, versions []timelineVersion) appTimeline {
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Time.Before(versions[j].Time)
+	})
+
+	var domains = make(map[string]bool)
+	var endpoints = make(map[string]bool)
+	for i := range versions {
+		var v = &versions[i]
+		if i > 0 {
+			v.FilesDelta = v.Files - versions[i-1].Files
+			v.SizeDelta = v.Size - versions[i-1].Size
+		} else {
+			v.FilesDelta = v.Files
+			v.SizeDelta = v.Size
+		}
+
+		v.NewDomains = []string{}
+		for _, d := range v.domains {
+			if !domains[d] {
+				domains[d] = true
+				v.NewDomains = append(v.NewDomains, d)
+			}
+		}
+		v.NewEndpoints = []string{}
+		for _, e := range v.endpoints {
+			if !endpoints[e] {
+				endpoints[e] = true
+				v.NewEndpoints = append(v.NewEndpoints, e)
+			}
+		}
+	}
+
+	return appTimeline{Wxid: wxid, Versions: versions}
+}
+
+func formatSizeDelta(delta int64) string {
+	if delta < 0 {
+		return "-" + formatSize(-delta)
+	}
+
+	return "+" + formatSize(delta)
+}
+
+func init() {
+	RootCmd.AddCommand(timelineCmd)
+
+	timelineCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+}
@@ -2,142 +2,773 @@ package cmd
 
 import (
 	"bytes"
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/sha1"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"runtime"
 	"sort"
+	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/wux1an/wxapkg/util"
-	"golang.org/x/crypto/pbkdf2"
+	"github.com/wux1an/wxapkg/wxapkg"
 )
 
 var programName = filepath.Base(os.Args[0])
 var unpackCmd = &cobra.Command{
 	Use:     "unpack",
-	Short:   "Decrypt wechat mini program",
+	Short:   util.T("Decrypt wechat mini program"),
 	Example: "  " + programName + "unpack -o unpack -r \"D:\\WeChat Files\\Applet\\wx12345678901234\"",
 	Run: func(cmd *cobra.Command, args []string) {
 		root, _ := cmd.Flags().GetString("root")
 		output, _ := cmd.Flags().GetString("output")
 		thread, _ := cmd.Flags().GetInt("thread")
-		disableBeautify, _ := cmd.Flags().GetBool("disable-beautify")
+		jobs, _ := cmd.Flags().GetInt("jobs")
+		vendor, _ := cmd.Flags().GetBool("vendor")
+		fingerprints, _ := cmd.Flags().GetString("fingerprints")
+		transformsFile, _ := cmd.Flags().GetString("transforms")
+		licenses, _ := cmd.Flags().GetBool("licenses")
+		open, _ := cmd.Flags().GetBool("open")
+		retries, _ := cmd.Flags().GetInt("retries")
+		maxMemory, _ := cmd.Flags().GetString("max-memory")
+		maxWriteRate, _ := cmd.Flags().GetString("max-write-rate")
+		transcode, _ := cmd.Flags().GetBool("transcode")
+		unescape, _ := cmd.Flags().GetBool("unescape-unicode")
+		eol, _ := cmd.Flags().GetString("eol")
+		bom, _ := cmd.Flags().GetString("bom")
+		normalize, _ := cmd.Flags().GetString("normalize")
+		sources, _ := cmd.Flags().GetBool("sources")
+		extractMaps, _ := cmd.Flags().GetBool("extract-maps")
+		dest, _ := cmd.Flags().GetString("dest")
+		format, _ := cmd.Flags().GetString("format")
+		level, _ := cmd.Flags().GetInt("level")
+		password, _ := cmd.Flags().GetString("password")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		progressMode, _ := cmd.Flags().GetString("progress")
+		progressInterval, _ := cmd.Flags().GetDuration("progress-interval")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		if jsonOutput {
+			util.JsonOutput = true
+			color.Output = io.Discard
+		}
+		layout, _ := cmd.Flags().GetString("layout")
+		carve, _ := cmd.Flags().GetBool("carve")
+		force, _ := cmd.Flags().GetBool("force")
+		linkVersions, _ := cmd.Flags().GetBool("link-versions")
+		hardlink, _ := cmd.Flags().GetBool("hardlink")
+		cas, _ := cmd.Flags().GetString("cas")
+		wxidMap, _ := cmd.Flags().GetString("wxid-map")
+		allAccounts, _ := cmd.Flags().GetBool("all-accounts")
+		versionSpecs, _ := cmd.Flags().GetStringSlice("version-filter")
+		newerThan, _ := cmd.Flags().GetString("newer-than")
+		if flat, _ := cmd.Flags().GetBool("flat"); flat {
+			layout = layoutFlat
+		}
+		if password == "" {
+			password = os.Getenv("WXAPKG_PASSWORD")
+		}
 
-		wxid, err := parseWxid(root)
-		util.Fatal(err)
+		// the placeholders of the scheduled runs, like 'unpack/{date}'
+		var started = time.Now()
+		var runId = util.NewRunId()
+		output = util.ExpandOutputPath(output, started, runId)
+		dest = util.ExpandOutputPath(dest, started, runId)
+		var run = &util.RunSummary{RunId: runId, Args: args, Flags: runFlags(cmd), Root: root, Started: started}
 
-		dirs, err := os.ReadDir(root)
+		memoryLimit, err := util.ParseSize(maxMemory)
+		util.Fatal(err)
+		writeFiles, writeBytes, err := util.ParseWriteRate(maxWriteRate)
+		util.Fatal(err)
+		versions, err := util.ParseVersionFilter(versionSpecs)
 		util.Fatal(err)
+		beautifyExts, err := beautifyExtensions(cmd)
+		util.Fatal(err)
+		if dest == "" {
+			dest = output
+		}
+		var out util.Output
+		if format == "dir" {
+			out, err = util.NewOutput(dest)
+			util.Fatal(err)
+		} else {
+			if dest != output {
+				util.Fatal(errors.New(util.T("the archive formats can't be used with '--dest'")))
+			}
+			archive, err := util.NewArchiveOutput(output, format, level)
+			util.Fatal(err)
+			if password != "" {
+				util.Fatal(archive.SetPassword(password))
+			}
+			out = archive
+		}
+		if eol != util.EolLf && eol != util.EolCrlf && eol != util.EolKeep {
+			util.Fatal(fmt.Errorf(util.T("invalid line ending '%s', 'lf', 'crlf' or 'keep' expected"), eol))
+		}
+		if layout != layoutMerged && layout != layoutPackage && layout != layoutFlat {
+			util.Fatal(fmt.Errorf(util.T("invalid layout '%s', 'merged', 'package' or 'flat' expected"), layout))
+		}
+		if progressMode != progressBar && progressMode != progressPlain && progressMode != progressNone {
+			util.Fatal(fmt.Errorf(util.T("invalid progress mode '%s', 'bar', 'plain' or 'none' expected"), progressMode))
+		}
+		if bom != util.BomStrip && bom != util.BomAdd && bom != util.BomKeep {
+			util.Fatal(fmt.Errorf(util.T("invalid bom option '%s', 'strip', 'add' or 'keep' expected"), bom))
+		}
+		if normalize != util.NormNfc && normalize != util.NormNfd && normalize != util.NormKeep {
+			util.Fatal(fmt.Errorf(util.T("invalid normalization form '%s', 'nfc', 'nfd' or 'keep' expected"), normalize))
+		}
+		if thread < 0 || thread > maxThreads {
+			util.Fatal(fmt.Errorf(util.T("invalid thread number %d, 0 for auto or 1 to %d expected"), thread, maxThreads))
+		}
+		if jobs < 0 || jobs > maxThreads {
+			util.Fatal(fmt.Errorf(util.T("invalid number of the parallel packages %d, 0 for auto or 1 to %d expected"), jobs, maxThreads))
+		}
+		if thread == 0 {
+			thread = autoThreads()
+		}
+		if jobs == 0 {
+			jobs = runtime.NumCPU()
+		}
+		var budget = util.NewMemoryBudget(memoryLimit)
+		var localOutput, local = out.(util.DirOutput)
+		if linkVersions && !local {
+			util.Fatal(errors.New(util.T("'--link-versions' only works with the local output path")))
+		}
+		if cas != "" && !local {
+			util.Fatal(errors.New(util.T("'--cas' only works with the local output path")))
+		}
+
+		// the concurrent invocations, like a cron job overlapping a manual run, corrupt the manifests
+		if local {
+			lock, err := util.LockDir(string(localOutput))
+			if errors.Is(err, util.ErrLocked) {
+				util.Fatal(util.WithExitCode(util.ExitLocked, fmt.Errorf(util.T("the output path '%s' is being written by another process, try again later: %v"), localOutput, err)))
+			}
+			util.Fatal(util.WithExitCode(util.ExitIO, err))
+			// util.Fatal exits without running the deferred calls
+			util.OnFatal(func() { _ = lock.Unlock() })
+			defer lock.Unlock()
+		}
 
-		color.Cyan("[+] unpack root '%s' with %d threads\n", root, thread)
+		if fingerprints != "" {
+			util.Fatal(util.LoadFingerprints(fingerprints))
+		}
+		var transforms []util.Transform
+		if transformsFile != "" {
+			transforms, err = util.ReadTransforms(transformsFile)
+			util.Fatal(err)
+		}
 
-		var allFileCount = 0
-		for _, subDir := range dirs {
-			//修改开始
-			if subDir.Name() == ".DS_Store" {
-				continue
+		// the files modified during this run are unpacked again by the next '--newer-than last', and so are the
+		// packages failed or skipped in the last runs
+		var state = &util.State{}
+		if local {
+			if s, err := util.ReadState(string(localOutput)); err == nil {
+				state = s
+			} else if !os.IsNotExist(err) && newerThan == "last" {
+				util.Fatal(util.WithExitCode(util.ExitIO, err))
 			}
-			//修改结束
-			subOutput := filepath.Join(output, subDir.Name())
+		}
+		var since time.Time
+		var pending map[string]bool
+		if newerThan == "last" {
+			if !local {
+				util.Fatal(errors.New(util.T("'--newer-than last' only works with the local output path")))
+			}
+			since = state.LastRun
+			pending = make(map[string]bool, len(state.Pending))
+			for _, file := range state.Pending {
+				pending[file] = true
+			}
+		} else if newerThan != "" {
+			since, err = util.ParseTime(newerThan, started)
+			util.Fatal(err)
+		}
+
+		var opt = unpackOptions{
+			thread:      thread,
+			workers:     make(chan struct{}, thread),
+			beautify:    beautifyExts,
+			vendor:      vendor,
+			licenses:    licenses,
+			retries:     retries,
+			transcode:   transcode,
+			unescape:    unescape,
+			transforms:  transforms,
+			eol:         eol,
+			bom:         bom,
+			normalize:   normalize,
+			sources:     sources,
+			restored:    &sync.Map{},
+			extractMaps: extractMaps,
+			carve:       carve,
+			output:      out,
+		}
+
+		// the sync clients fall behind or throttle on the bursts of the small files
+		if provider := util.SyncedFolder(string(localOutput)); local && provider != "" && maxWriteRate == "" {
+			writeFiles = util.DefaultSyncedWriteRate
+			color.Yellow(util.T("[!] the output path is synced by %s, the writes are limited to %d files per second, set '--max-write-rate 0' to disable it\n"), provider, util.DefaultSyncedWriteRate)
+		}
+		if writeFiles > 0 || writeBytes > 0 {
+			opt.output = util.NewThrottledOutput(out, writeFiles, writeBytes)
+		}
 
-			files, err := scanFiles(filepath.Join(root, subDir.Name()))
+		var groups []packageGroup
+		if root == "" && !allAccounts {
+			util.Fatal(errors.New(util.T("'--root' is required unless '--all-accounts' is set")))
+		}
+		if accounts := util.AccountAppletRoots(root); allAccounts || len(accounts) > 0 {
+			// the 'WeChat Files' folder with the accounts, or all the accounts discovered
+			if root == "" {
+				accounts = util.FindAccountAppletRoots()
+			}
+			if len(accounts) == 0 {
+				util.Fatal(util.WithExitCode(util.ExitNoPackage, errors.New(util.T("no applet root of the wechat accounts found"))))
+			}
+			groups, err = scanAccounts(accounts, layout, versions)
+			util.Fatal(err)
+		} else if loose, _ := filepath.Glob(filepath.Join(root, "*.wxapkg")); len(loose) > 0 {
+			wxids, err := readWxidMap(wxidMap)
+			util.Fatal(err)
+			groups = groupLooseFiles(loose, wxids)
+			if len(groups) == 0 {
+				util.Fatal(util.WithExitCode(util.ExitNoPackage, fmt.Errorf(util.T("no wxid found for the wxapkg files in '%s'"), root)))
+			}
+		} else {
+			groups, err = scanVersions(root, layout, versions)
 			util.Fatal(err)
+		}
+
+		if !since.IsZero() {
+			var all = len(groups)
+			groups = newerGroups(groups, since, pending)
+			color.Cyan(util.T("[+] %d of %d folders have the wxapkg files modified after %s\n"), len(groups), all, since.Format("2006-01-02 15:04:05"))
+		}
+
+		if root != "" {
+			color.Cyan(util.T("[+] unpack root '%s' with %d threads\n"), root, thread)
+		}
+
+		var allFileCount = 0
+		var failures []error
+		var locked []string
+		var broken []string             // the packages failed to unpack with the errors
+		var incompletePackages []string // the wxapkg files failed, skipped or partly unpacked
+		var exts = make(map[string]int)
+		var outputs []string
+		var manifests = make(map[string]*util.Manifest) // output -> manifest
+		var infos = make(map[string]*util.AppInfo)      // output -> metadata card
+		var markers = make(map[string]*util.Marker)     // output -> marker, only for the local output
+		var incomplete = make(map[string]bool)          // the outputs with the failed or skipped packages
+
+		var packages []packageJob
+		var totalSize int64
+		for _, group := range groups {
+			var subOutput, files = group.output, group.files
+			var base = filepath.Dir(root)
+			if group.base != "" {
+				base = group.base
+			}
+
+			var roles map[string]string
+			if layout == layoutPackage {
+				roles = packageRoles(group.wxid, files)
+			}
+
+			if local {
+				var sources = make(map[string]string, len(files))
+				for _, file := range files {
+					rel, _ := filepath.Rel(base, file)
+					sources[filepath.ToSlash(rel)] = file
+				}
+				if marker, err := util.NewMarker(sources); err == nil {
+					var dir = filepath.Join(string(localOutput), filepath.FromSlash(subOutput))
+					if old, err := util.ReadMarker(dir); err == nil && old.Equal(marker) && !force {
+						color.Cyan(util.T("[+] skip '%s', it's unpacked already, use '--force' to unpack again\n"), dir)
+						continue
+					}
+					markers[subOutput] = marker
+				}
+			}
 
+			var manifest = &util.Manifest{Wxid: group.wxid}
+			var info = util.NewAppInfo(group.wxid, filepath.Base(filepath.Dir(files[0])))
+			outputs = append(outputs, subOutput)
+			manifests[subOutput] = manifest
+			infos[subOutput] = info
 			for _, file := range files {
-				var decryptedData = decryptFile(wxid, file)
-				fileCount, err := unpack(decryptedData, subOutput, thread, !disableBeautify)
-				util.Fatal(err)
-				allFileCount += fileCount
+				rel, _ := filepath.Rel(base, file)
 
-				rel, _ := filepath.Rel(filepath.Dir(root), file)
-				color.Yellow("\r[+] unpacked %5d files from '%s'", fileCount, rel)
+				var size int64
+				if info, err := os.Stat(file); err == nil {
+					size = info.Size()
+					if info.ModTime().After(manifest.Time) {
+						manifest.Time = info.ModTime()
+					}
+				}
+				totalSize += size
+				packages = append(packages, packageJob{file: file, rel: rel, wxid: group.wxid, output: subOutput, dir: roles[file], size: size, manifest: manifest, info: info})
 			}
 		}
 
-		color.Cyan("[+] all %d files saved to '%s'\n", allFileCount, output)
+		if jsonOutput {
+			progressMode = progressNone
+		}
+		var progress = newUnpackProgress(len(packages), totalSize, progressMode, progressInterval)
+		var locker = sync.Mutex{}
+		var wg = sync.WaitGroup{}
+		var running = make(chan struct{}, jobs) // the packages unpacking in parallel
+		for _, job := range packages {
+			// the encrypted and decrypted data are both in memory if not streaming
+			var cost = job.size * 2
+			var stream = memoryLimit > 0 && cost > memoryLimit
+			if stream {
+				cost = 0
+			}
+
+			running <- struct{}{}
+			budget.Acquire(cost)
+			wg.Add(1)
+			go func(job packageJob) {
+				defer func() {
+					budget.Release(cost)
+					<-running
+					wg.Done()
+				}()
+
+				var start = time.Now()
+				pkg, closer, err := openPackage(job.wxid, job.file, stream)
+				var decrypted = time.Now()
+				if util.IsLockedError(err) {
+					progress.packageDone(job.size, func() {
+						color.Yellow(util.T("[!] skip '%s', it's held by the running wechat, try again after closing the mini program\n"), job.rel)
+					})
+					if jsonOutput {
+						util.PrintJson(util.NewErrorRecord(&util.FileError{Code: util.ErrCodePackageLocked, File: job.rel, Err: err}))
+					}
+					locker.Lock()
+					locked = append(locked, job.rel)
+					incompletePackages = append(incompletePackages, job.file)
+					run.Errors = append(run.Errors, fmt.Sprintf("%s: %v", job.rel, err))
+					incomplete[job.output] = true
+					locker.Unlock()
+					return
+				}
+				// the broken package fails alone, the others are still unpacked
+				var fail = func(err error) {
+					err = util.WithFile(err, job.rel)
+					progress.packageDone(job.size, func() {
+						color.Red(util.T("[!] failed to unpack '%s': %v\n"), job.rel, err)
+					})
+					if jsonOutput {
+						util.PrintJson(util.NewErrorRecord(err))
+					}
+					locker.Lock()
+					broken = append(broken, fmt.Sprintf("%s: %v", job.rel, err))
+					incompletePackages = append(incompletePackages, job.file)
+					run.Errors = append(run.Errors, broken[len(broken)-1])
+					incomplete[job.output] = true
+					locker.Unlock()
+				}
+				if err != nil {
+					fail(err)
+					return
+				}
+				result, err := unpack(pkg, job.output, job.dir, opt, func(done, total int) {
+					progress.fileDone(job.rel, done, total)
+				})
+				_ = closer.Close()
+				if err != nil {
+					fail(err)
+					return
+				}
+
+				result.elapsed = time.Since(start)
+				result.decrypt = decrypted.Sub(start)
+				for i := range result.failures {
+					result.failures[i] = util.WithFile(result.failures[i], job.rel)
+				}
+
+				for i := range result.files {
+					result.files[i].Package = filepath.ToSlash(job.rel)
+				}
+				var stats = util.NewPackageStats(filepath.ToSlash(job.rel), result.files)
+
+				locker.Lock()
+				allFileCount += len(result.files)
+				failures = append(failures, result.failures...)
+				run.Packages = append(run.Packages, util.RunPackage{
+					Package: filepath.ToSlash(job.rel), Output: job.output, Size: job.size, Files: len(result.files),
+					Written: result.written, Beautified: result.beautified, Failures: len(result.failures),
+					Decrypt: result.decrypt.Milliseconds(), Parse: result.parse.Milliseconds(),
+					Extract: result.extract.Milliseconds(), Beautify: result.beautify.Milliseconds(),
+					Elapsed: result.elapsed.Milliseconds(), Placeholders: result.placeholders,
+				})
+				for _, err := range result.failures {
+					run.Errors = append(run.Errors, err.Error())
+				}
+				if len(result.failures) > 0 {
+					incomplete[job.output] = true
+					incompletePackages = append(incompletePackages, job.file)
+				}
+				job.manifest.Files = append(job.manifest.Files, result.files...)
+				job.info.AddHints(result.hints)
+				if result.config != nil {
+					if err := job.info.SetConfig(result.config); err != nil {
+						color.Yellow(util.T("[!] failed to parse the app config of '%s': %v\n"), job.rel, err)
+					}
+				}
+				job.manifest.Packages = append(job.manifest.Packages, stats)
+				for ext, count := range stats.Extensions {
+					exts[ext] += count
+				}
+				locker.Unlock()
+
+				if jsonOutput {
+					util.PrintJson(packageRecord{Type: "package", Package: filepath.ToSlash(job.rel), Output: job.output,
+						Files: len(result.files), Written: result.written, Beautified: result.beautified, Failures: len(result.failures),
+						Elapsed: result.elapsed.Milliseconds(), Placeholders: result.placeholders})
+					for _, err := range result.failures {
+						util.PrintJson(util.NewErrorRecord(err))
+					}
+				}
+				progress.packageDone(job.size, func() {
+					color.Yellow(util.T("[+] unpacked %5d files from '%s'")+util.T(", %[3]s written, %[4]d beautified, %[5]d failed in %[6]s")+" (%[7]s)",
+						len(result.files), job.rel, formatSize(result.written), result.beautified, len(result.failures),
+						result.elapsed.Round(time.Millisecond), formatExtensions(stats.Extensions))
+					if verbose {
+						printStageTimings(result, thread)
+					}
+					if len(result.placeholders) > 0 {
+						color.Yellow(util.T("[!] %d placeholder entries without a body in '%s' are skipped: %s\n"), len(result.placeholders), job.rel, strings.Join(result.placeholders, ", "))
+					}
+					if result.carved {
+						color.Red(util.T("[!] the index of '%s' is broken, %d files are carved to 'carved/'\n"), job.rel, len(result.files))
+					}
+					if !result.header.KnownInfo1() {
+						color.Red(util.T("[!] '%s' has an unknown info1 0x%08x in the header, the format may be changed, please check the unpacked files\n"), job.rel, result.header.Info1)
+					}
+				})
+			}(job)
+		}
+		wg.Wait()
+		progress.finish()
+
+		for _, subOutput := range outputs {
+			var manifest = manifests[subOutput]
+
+			// the packages are finished in any order
+			sort.Slice(manifest.Packages, func(i, j int) bool {
+				return manifest.Packages[i].Package < manifest.Packages[j].Package
+			})
+			sort.SliceStable(manifest.Files, func(i, j int) bool {
+				return manifest.Files[i].Package < manifest.Files[j].Package
+			})
+
+			util.Fatal(util.WithExitCode(util.ExitIO, manifest.Save(out, subOutput)))
+			infos[subOutput].Finish(manifest)
+			util.Fatal(util.WithExitCode(util.ExitIO, infos[subOutput].Save(out, subOutput)))
+			if licenses {
+				util.Fatal(util.WithExitCode(util.ExitIO, util.NewLicenseReport(manifest).Save(out, subOutput)))
+			}
+
+			// the incomplete folder is unpacked again next time
+			if marker := markers[subOutput]; marker != nil && !incomplete[subOutput] {
+				util.Fatal(util.WithExitCode(util.ExitIO, marker.Save(out, subOutput)))
+			} else if local {
+				_ = os.Remove(filepath.Join(string(localOutput), filepath.FromSlash(subOutput), util.MarkerName))
+			}
+		}
+
+		var files = make(map[string]string) // the local files unpacked in this run -> sha256
+		if local {
+			for _, subOutput := range outputs {
+				for _, f := range manifests[subOutput].Files {
+					files[filepath.Join(string(localOutput), filepath.FromSlash(subOutput), filepath.FromSlash(f.Path))] = f.Sha256
+				}
+			}
+		}
+
+		// the store deduplicates the files as well
+		if cas != "" {
+			stored, linked, saved, err := util.StoreContent(cas, files)
+			util.Fatal(util.WithExitCode(util.ExitIO, err))
+			color.Cyan(util.T("[+] %d new files stored to '%s', %d files linked to the store, %s saved\n"), stored, cas, linked, formatSize(saved))
+		} else if local && hardlink {
+			linked, saved, err := util.LinkDuplicates(files)
+			util.Fatal(util.WithExitCode(util.ExitIO, err))
+			if linked > 0 {
+				color.Cyan(util.T("[+] %d duplicate files are hard linked, %s saved\n"), linked, formatSize(saved))
+			}
+		}
+
+		if linkVersions {
+			linked, saved, err := util.LinkVersions(string(localOutput))
+			util.Fatal(util.WithExitCode(util.ExitIO, err))
+			color.Cyan(util.T("[+] %d files identical to the previous versions are linked, %s saved\n"), linked, formatSize(saved))
+		}
+
+		run.Output = out.String()
+		run.Totals.Skipped = len(locked)
+		util.Fatal(util.WithExitCode(util.ExitIO, run.Finish(out, time.Now())))
+
+		if closer, ok := out.(io.Closer); ok {
+			util.Fatal(util.WithExitCode(util.ExitIO, closer.Close()))
+		}
+		if local {
+			util.Fatal(util.WithExitCode(util.ExitIO, nextState(state, started, packages, incompletePackages).Save(string(localOutput))))
+		}
+
+		color.Cyan(util.T("[+] all %d files saved to '%s'\n"), allFileCount, out)
 		if len(args) == 2 && "detailFilePath" == args[0] {
-			color.Cyan("[+] mini program detail info saved to '%s'\n", args[1])
+			color.Cyan(util.T("[+] mini program detail info saved to '%s'\n"), args[1])
 		}
 
-		color.Cyan("[+] extension statistics:\n")
+		color.Cyan(util.T("[+] extension statistics:\n"))
 
-		var keys [][]interface{}
-		for k, v := range exts {
-			keys = append(keys, []interface{}{k, v})
+		for _, ext := range sortedExtensions(exts) {
+			color.Cyan("  - %-5s %5d\n", ext, exts[ext])
 		}
 
-		sort.Slice(keys, func(i, j int) bool {
-			return keys[i][1].(int) > keys[j][1].(int)
-		})
+		if open && local {
+			util.Fatal(util.OpenFolder(output))
+		}
+
+		if len(locked) > 0 {
+			color.Yellow(util.T("[!] %d packages skipped because wechat holds them:\n"), len(locked))
+			for _, rel := range locked {
+				color.Yellow("  - %s\n", rel)
+			}
+		}
+
+		if jsonOutput {
+			util.PrintJson(summaryRecord{Type: "summary", Output: out.String(), Files: allFileCount, Failures: len(failures) + len(broken), Skipped: len(locked)})
+		}
 
-		for _, kk := range keys {
-			color.Cyan("  - %-5s %5d\n", kk[0], kk[1])
+		if len(failures) > 0 || len(locked) > 0 || len(broken) > 0 {
+			for _, msg := range broken {
+				color.Red("  - %s\n", msg)
+			}
+			for _, err := range failures {
+				color.Red("  - %v\n", err)
+			}
+			util.Fatal(util.WithExitCode(util.ExitPartial, fmt.Errorf(util.T("%d packages and %d files failed to unpack, %d packages skipped"), len(broken), len(failures), len(locked))))
 		}
 	},
 }
 
-type wxapkgFile struct {
-	nameLen uint32
-	name    []byte
-	offset  uint32
-	size    uint32
+type unpackOptions struct {
+	thread      int
+	workers     chan struct{}   // the file workers shared by all the packages
+	beautify    map[string]bool // the extensions to beautify
+	vendor      bool            // place known libraries under 'vendor/'
+	licenses    bool            // detect the license headers
+	retries     int             // retry the failed writes, for network shares and cloud-synced folders
+	transcode   bool            // transcode the text not in UTF-8 to UTF-8
+	unescape    bool            // decode the unicode escapes in the strings of the js and the json
+	transforms  []util.Transform
+	eol         string    // the line endings of the text, see util.NormalizeEol
+	bom         string    // strip, add or keep the UTF-8 BOM of the text
+	normalize   string    // the unicode normalization form of the file names, see util.NormalizeName
+	sources     bool      // restore the original sources from the inline source maps
+	restored    *sync.Map // the restored sources, the same source in many bundles is saved once
+	extractMaps bool      // save the inline source maps to the '.map' files
+	carve       bool      // carve the files by their signatures if the index is broken
+	output      util.Output
 }
 
-func unpack(decryptedData []byte, unpackRoot string, thread int, beautify bool) (int, error) {
-	var f = bytes.NewReader(decryptedData)
-
-	// Read header
-	var (
-		firstMark       uint8
-		info1           uint32
-		indexInfoLength uint32
-		bodyInfoLength  uint32
-		lastMark        uint8
-	)
-	_ = binary.Read(f, binary.BigEndian, &firstMark)
-	_ = binary.Read(f, binary.BigEndian, &info1)
-	_ = binary.Read(f, binary.BigEndian, &indexInfoLength)
-	_ = binary.Read(f, binary.BigEndian, &bodyInfoLength)
-	_ = binary.Read(f, binary.BigEndian, &lastMark)
+// maxThreads is the limit of '--thread' and '--jobs', more goroutines only add the contention.
+const maxThreads = 1024
 
-	if firstMark != 0xBE || lastMark != 0xED {
-		return 0, errors.New("failed to unpack, it's not a valid wxapkg file")
+// autoThreads returns the number of the file workers for '--thread 0', the writes are I/O bound so there
+// are more workers than the CPUs.
+func autoThreads() int {
+	var n = runtime.NumCPU() * 4
+	if n < 8 {
+		n = 8
 	}
+	if n > 64 {
+		n = 64
+	}
+
+	return n
+}
 
-	var fileCount uint32
-	_ = binary.Read(f, binary.BigEndian, &fileCount)
+// printStageTimings prints how long the stages of the package took, with the advice if beautifying takes most
+// of the time.
+func printStageTimings(result *unpackResult, threads int) {
+	color.White(util.T("    decrypt %s, parse %s, extract %s, beautify %s (summed over %d threads)\n"),
+		result.decrypt.Round(time.Millisecond), result.parse.Round(time.Millisecond),
+		result.extract.Round(time.Millisecond), result.beautify.Round(time.Millisecond), threads)
 
-	// Read index
-	var fileList = make([]*wxapkgFile, fileCount)
-	for i := uint32(0); i < fileCount; i++ {
-		data := &wxapkgFile{}
-		_ = binary.Read(f, binary.BigEndian, &data.nameLen)
+	if result.extract > 0 && result.beautify/time.Duration(threads) > result.extract/2 {
+		color.White(util.T("    beautifying takes most of the extraction, '--disable-beautify' or '--no-beautify' speeds it up\n"))
+	} else if result.extract > time.Second && result.beautify/time.Duration(threads) < result.extract/10 {
+		color.White(util.T("    writing the files takes most of the extraction, try more threads by '-n'\n"))
+	}
+}
 
-		if data.nameLen > 10<<20 { // 10 MB
-			return 0, errors.New("invalid decrypted wxapkg file")
+// runFlags returns the flags set explicitly for the run summary, the secrets are redacted.
+func runFlags(cmd *cobra.Command) map[string]string {
+	var flags = make(map[string]string)
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if f.Name == "password" {
+			flags[f.Name] = "***"
+		} else {
+			flags[f.Name] = f.Value.String()
 		}
+	})
 
-		data.name = make([]byte, data.nameLen)
-		_, _ = io.ReadAtLeast(f, data.name, int(data.nameLen))
-		_ = binary.Read(f, binary.BigEndian, &data.offset)
-		_ = binary.Read(f, binary.BigEndian, &data.size)
+	return flags
+}
 
-		fileList[i] = data
+// packageJob is a wxapkg file to unpack.
+type packageJob struct {
+	file     string
+	rel      string // the path relative to the parent of the root
+	wxid     string
+	output   string // the slash path relative to the output
+	dir      string // the folder of the package in the output path, empty in the merged layout
+	size     int64
+	manifest *util.Manifest // the manifest of the output
+	info     *util.AppInfo  // the metadata card of the output
+}
+
+// packageRecord is the unpacked package in the JSON output.
+type packageRecord struct {
+	Type       string `json:"type"` // always "package"
+	Package    string `json:"package"`
+	Output     string `json:"output"`
+	Files      int    `json:"files"`
+	Written    int64  `json:"written"` // the bytes written to the output
+	Beautified int    `json:"beautified"`
+	Failures   int    `json:"failures"`
+	Elapsed    int64  `json:"elapsed_ms"`
+
+	Placeholders []string `json:"placeholders,omitempty"` // the entries without a body, skipped
+}
+
+// summaryRecord is the last record in the JSON output.
+type summaryRecord struct {
+	Type     string `json:"type"` // always "summary"
+	Output   string `json:"output"`
+	Files    int    `json:"files"`
+	Failures int    `json:"failures"`
+	Skipped  int    `json:"skipped"` // the packages held by wechat
+}
+
+type unpackResult struct {
+	files        []util.ManifestFile
+	failures     []error // the files failed to extract
+	header       *wxapkg.Header
+	carved       bool     // the index is broken, and the files are carved from the body
+	placeholders []string // the entries without a body, see wxapkg.Entry.IsPlaceholder
+	written      int64    // the bytes written to the output
+	beautified   int
+	elapsed      time.Duration
+	decrypt      time.Duration // opening and decrypting the package, only the head if streaming
+	parse        time.Duration // reading or carving the index
+	extract      time.Duration // saving the files
+	beautify     time.Duration // beautifying the files, summed over the file workers
+	config       []byte        // the 'app-config.json' in the main package
+	hints        []string      // the hints of the base library version
+}
+
+// entryResult is the file saved by unpackEntry.
+type entryResult struct {
+	file       util.ManifestFile
+	written    int // the bytes written to the output
+	beautified bool
+	beautify   time.Duration // the time of beautifying
+	config     []byte
+	hints      []string
+	sources    []util.ManifestFile // the original sources and the source map extracted from the inline source map
+}
+
+// packageReader reads the decrypted wxapkg file, in memory (*bytes.Reader) or streaming (*wxapkg.Decrypter).
+type packageReader interface {
+	io.ReaderAt
+	Size() int64
+}
+
+// readIndex reads the header and the file index of the decrypted wxapkg file.
+func readIndex(pkg packageReader) (*wxapkg.Header, []wxapkg.Entry, error) {
+	header, entries, err := wxapkg.ReadIndex(pkg, pkg.Size())
+	if errors.Is(err, wxapkg.ErrFormat) {
+		return nil, nil, util.WithExitCode(util.ExitInvalidPackage, errors.New(util.T("failed to unpack, it's not a valid wxapkg file")))
+	}
+	if err != nil && header != nil && !header.KnownInfo1() {
+		return nil, nil, util.WithExitCode(util.ExitInvalidPackage, fmt.Errorf(util.T("invalid decrypted wxapkg file, the info1 0x%08x in the header is unknown, the format may be changed"), header.Info1))
+	}
+	if err != nil {
+		return nil, nil, util.WithExitCode(util.ExitInvalidPackage, errors.New(util.T("invalid decrypted wxapkg file")))
+	}
+
+	return header, entries, nil
+}
+
+// carveIndex carves the files from the body of the wxapkg file with the broken index, see wxapkg.Carve.
+func carveIndex(pkg packageReader) (*wxapkg.Header, []wxapkg.Entry, error) {
+	var data = make([]byte, pkg.Size())
+	if _, err := pkg.ReadAt(data, 0); err != nil && err != io.EOF {
+		return nil, nil, util.WithExitCode(util.ExitIO, err)
+	}
+
+	// the body starts after the index if the header is not broken
+	var start int64
+	header, _, _ := wxapkg.ReadIndex(pkg, pkg.Size())
+	if header != nil && int64(wxapkg.HeaderSize)+int64(header.IndexInfoLength) < pkg.Size() {
+		start = int64(wxapkg.HeaderSize) + int64(header.IndexInfoLength)
+	} else {
+		header = &wxapkg.Header{}
+	}
+
+	var entries = wxapkg.Carve(data, start)
+	if len(entries) == 0 {
+		return nil, nil, util.WithExitCode(util.ExitInvalidPackage, errors.New(util.T("invalid decrypted wxapkg file, no file is carved")))
+	}
+
+	return header, entries, nil
+}
+
+// unpack saves the files in the wxapkg file to the folder dir under unpackRoot in the output, onFile is called
+// after every file is saved.
+func unpack(pkg packageReader, unpackRoot, dir string, opt unpackOptions, onFile func(done, total int)) (*unpackResult, error) {
+	var start = time.Now()
+	header, fileList, err := readIndex(pkg)
+	var carved = false
+	if err != nil && opt.carve {
+		header, fileList, err = carveIndex(pkg)
+		carved = true
+	}
+	if err != nil {
+		return nil, err
+	}
+	// the placeholders are reported instead of saved as the strange empty files
+	var placeholders []string
+	var entries = fileList[:0:0]
+	for _, d := range fileList {
+		if d.IsPlaceholder() {
+			placeholders = append(placeholders, d.Name)
+		} else {
+			entries = append(entries, d)
+		}
 	}
+	fileList = entries
+	var fileCount = len(fileList)
+	var parsed = time.Now()
 
 	// Save files
-	var chFiles = make(chan *wxapkgFile)
+	var chFiles = make(chan wxapkg.Entry)
 	var wg = sync.WaitGroup{}
 	wg.Add(1)
 	go func() {
@@ -149,51 +780,356 @@ func unpack(decryptedData []byte, unpackRoot string, thread int, beautify bool)
 		close(chFiles)
 	}()
 
-	wg.Add(thread)
+	wg.Add(opt.thread)
 	var locker = sync.Mutex{}
 	var count = 0
-	var colorPrint = color.New()
-	for i := 0; i < thread; i++ {
+	var result = &unpackResult{files: make([]util.ManifestFile, 0, fileCount), header: header, carved: carved, placeholders: placeholders, parse: parsed.Sub(start)}
+	for i := 0; i < opt.thread; i++ {
 		go func() {
 			defer wg.Done()
 
 			for d := range chFiles {
-				d.name = []byte(filepath.Join(unpackRoot, string(d.name)))
-				outputFilePath := string(d.name)
-				dir := filepath.Dir(outputFilePath)
-
-				err := os.MkdirAll(dir, os.ModePerm)
-				util.Fatal(err)
-
-				data := decryptedData[d.offset : d.offset+d.size]
-
-				if beautify {
-					data = fileBeautify(outputFilePath, data)
-				}
-				err = os.WriteFile(outputFilePath, data, 0600)
-				util.Fatal(err)
+				opt.workers <- struct{}{}
+				entry, err := safeUnpackEntry(pkg, d, unpackRoot, dir, opt)
+				<-opt.workers
 
 				locker.Lock()
+				if err != nil {
+					result.failures = append(result.failures, err)
+					locker.Unlock()
+					continue
+				}
 				count++
-				_, _ = colorPrint.Print(color.GreenString("\runpack %d/%d", count, fileCount))
+				result.files = append(result.files, entry.file)
+				result.files = append(result.files, entry.sources...)
+				result.written += int64(entry.written)
+				if entry.beautified {
+					result.beautified++
+				}
+				result.beautify += entry.beautify
+				if entry.config != nil {
+					result.config = entry.config
+				}
+				result.hints = append(result.hints, entry.hints...)
+				onFile(count, fileCount)
 				locker.Unlock()
 			}
 		}()
 	}
 
 	wg.Wait()
+	result.extract = time.Since(parsed)
 
-	return int(fileCount), nil
+	sort.Slice(result.files, func(i, j int) bool {
+		return result.files[i].Name < result.files[j].Name
+	})
+
+	return result, nil
+}
+
+// unpackEntry saves the file in the wxapkg file to the folder dir under unpackRoot, the slash path relative to
+// the output. The path in the manifest is relative to unpackRoot.
+func unpackEntry(pkg packageReader, d wxapkg.Entry, unpackRoot, dir string, opt unpackOptions) (entryResult, error) {
+	var name = util.NormalizeName(d.Name, opt.normalize)
+	if int64(d.Offset)+int64(d.Size) > pkg.Size() {
+		return entryResult{}, &util.FileError{Code: util.ErrCodeBadIndex, Entry: name,
+			Err: fmt.Errorf(util.T("%s: out of range [%d:%d]"), name, d.Offset, int64(d.Offset)+int64(d.Size))}
+	}
+	// the empty files may be at the end of the package, where ReadAt returns io.EOF
+	data := make([]byte, d.Size)
+	if _, err := pkg.ReadAt(data, int64(d.Offset)); err != nil && d.Size > 0 {
+		return entryResult{}, &util.FileError{Code: util.ErrCodeRead, Entry: name, Err: fmt.Errorf("%s: %w", name, err)}
+	}
+
+	var library = util.MatchLibrary(name, data)
+	var licenses []string
+	if opt.licenses {
+		licenses = util.DetectLicenses(library, data)
+	}
+	outputFilePath := outputPath(unpackRoot, dir, name)
+	if opt.vendor && library != "" {
+		outputFilePath = outputPath(unpackRoot, dir, "vendor", name)
+	}
+
+	var charset string
+	var bom, transcoded bool
+	var reformatted []string // the changes repack can't undo
+	var text = util.IsTextFile(name, data)
+	if text {
+		// the BOM is removed before beautify and added back later
+		data, bom = util.TrimBom(data)
+		if charset = util.DetectCharset(data); charset == util.CharsetUtf8 {
+			charset = ""
+		} else if opt.transcode && charset != "" {
+			if utf8Data, err := util.ToUtf8(data, charset); err == nil {
+				data, transcoded = utf8Data, true
+			}
+		}
+		if opt.unescape && util.CanUnescape(name) && utf8.Valid(data) {
+			if unescaped := util.UnescapeUnicode(data); !bytes.Equal(unescaped, data) {
+				data = unescaped
+				reformatted = append(reformatted, util.ReformatUnescape)
+			}
+		}
+	}
+
+	var sources []util.ManifestFile
+	if opt.sources && text && path.Ext(name) == ".js" {
+		var err error
+		if sources, err = restoreSources(name, data, path.Join(unpackRoot, dir), unpackRoot, opt); err != nil {
+			return entryResult{}, &util.FileError{Code: util.ErrCodeWrite, Entry: name, Err: fmt.Errorf("%s: %w", name, err)}
+		}
+	}
+	if opt.extractMaps && text && path.Ext(name) == ".js" {
+		var mapFile *util.ManifestFile
+		var err error
+		if data, mapFile, err = extractSourceMap(name, data, outputFilePath, unpackRoot, opt); err != nil {
+			return entryResult{}, &util.FileError{Code: util.ErrCodeWrite, Entry: name, Err: fmt.Errorf("%s: %w", name, err)}
+		}
+		if mapFile != nil {
+			sources = append(sources, *mapFile)
+			reformatted = append(reformatted, util.ReformatExtractMaps)
+		}
+	}
+
+	var beautified = false
+	var beautifyTime time.Duration
+	if opt.beautify[path.Ext(outputFilePath)] {
+		var start = time.Now()
+		var pretty = fileBeautify(outputFilePath, data)
+		beautifyTime = time.Since(start)
+		beautified = !bytes.Equal(pretty, data)
+		data = pretty
+		if beautified {
+			reformatted = append(reformatted, util.ReformatBeautify)
+		}
+	}
+	var transformed []string
+	if text && len(opt.transforms) > 0 {
+		data, transformed = util.ApplyTransforms(opt.transforms, name, data)
+	}
+	if text {
+		if normalized := util.NormalizeEol(data, opt.eol); !bytes.Equal(normalized, data) {
+			data = normalized
+			reformatted = append(reformatted, util.ReformatEol)
+		}
+		if opt.bom == util.BomAdd || (opt.bom == util.BomKeep && bom) {
+			data = util.AddBom(data)
+		}
+	}
+
+	err := util.Retry(opt.retries, func() error {
+		return opt.output.WriteFile(outputFilePath, data)
+	})
+	if err != nil {
+		return entryResult{}, &util.FileError{Code: util.ErrCodeWrite, Entry: name, Err: fmt.Errorf("%s: %w", name, err)}
+	}
+
+	var file = util.ManifestFile{
+		Name:        name,
+		Original:    d.Name,
+		Path:        rootRelative(outputFilePath, unpackRoot),
+		Sha256:      util.Sha256(data),
+		Size:        d.Size,
+		Library:     library,
+		Licenses:    licenses,
+		Charset:     charset,
+		Transcoded:  transcoded,
+		Bom:         bom,
+		Reformatted: reformatted,
+		Transforms:  transformed,
+	}
+	if file.Original == file.Name {
+		file.Original = ""
+	}
+
+	var result = entryResult{file: file, written: len(data), beautified: beautified, beautify: beautifyTime, sources: sources}
+	if text {
+		result.hints = util.FindLibVersions(data)
+	}
+	if name == "/"+util.AppConfigName {
+		result.config = data
+	}
+
+	return result, nil
+}
+
+// restoreSources saves the original sources embedded in the inline source map of the bundle to 'src/' under
+// the folder dir, the slash path relative to the output. The paths in the manifest are relative to unpackRoot.
+func restoreSources(name string, data []byte, dir, unpackRoot string, opt unpackOptions) ([]util.ManifestFile, error) {
+	mapData, _, _, ok := util.FindInlineSourceMap(data)
+	if !ok {
+		return nil, nil
+	}
+	m, err := util.ParseSourceMap(mapData)
+	if err != nil {
+		return nil, nil // not a valid source map, the bundle is still saved
+	}
+
+	var result []util.ManifestFile
+	for source, content := range m.SourceFiles() {
+		var sourcePath = outputPath(dir, "src", source)
+		if _, loaded := opt.restored.LoadOrStore(sourcePath, true); loaded {
+			continue
+		}
+
+		var sourceData = []byte(content)
+		err := util.Retry(opt.retries, func() error {
+			return opt.output.WriteFile(sourcePath, sourceData)
+		})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, util.ManifestFile{
+			Name:   "/src/" + source,
+			Path:   rootRelative(sourcePath, unpackRoot),
+			Size:   uint32(len(sourceData)),
+			Sha256: util.Sha256(sourceData),
+			Source: name,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// extractSourceMap saves the inline source map of the script to the sibling '.map' file of outputFilePath, and
+// replaces the inline one with the reference to it. It returns the script and the map file, nil if there is no
+// inline source map.
+func extractSourceMap(name string, data []byte, outputFilePath, unpackRoot string, opt unpackOptions) ([]byte, *util.ManifestFile, error) {
+	mapData, start, end, ok := util.FindInlineSourceMap(data)
+	if !ok {
+		return data, nil, nil
+	}
+
+	var mapPath = outputFilePath + ".map"
+	err := util.Retry(opt.retries, func() error {
+		return opt.output.WriteFile(mapPath, mapData)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var script = append(append(append([]byte{}, data[:start]...), "//# sourceMappingURL="+path.Base(mapPath)...), data[end:]...)
+	return script, &util.ManifestFile{
+		Name:   name + ".map",
+		Path:   rootRelative(mapPath, unpackRoot),
+		Size:   uint32(len(mapData)),
+		Sha256: util.Sha256(mapData),
+		Source: name,
+	}, nil
+}
+
+// outputPath joins the elements to the slash path relative to the output, without the leading '/' even if the
+// unpack root is empty in the flat layout.
+func outputPath(elem ...string) string {
+	return strings.TrimPrefix(path.Join(elem...), "/")
+}
+
+// rootRelative returns the slash path in the output relative to unpackRoot, the output path itself if it's empty.
+func rootRelative(name, unpackRoot string) string {
+	if unpackRoot == "" {
+		return name
+	}
+
+	return strings.TrimPrefix(name, unpackRoot+"/")
+}
+
+// safeUnpackEntry calls unpackEntry, the panic is recovered as the failure of the file so that the other
+// files are still unpacked.
+func safeUnpackEntry(pkg packageReader, d wxapkg.Entry, unpackRoot, dir string, opt unpackOptions) (result entryResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = entryResult{}
+			err = &util.FileError{Code: util.ErrCodePanic, Entry: d.Name,
+				Err: fmt.Errorf(util.T("%s: unexpected panic at %s: %v"), d.Name, panicLocation(), r)}
+		}
+	}()
+
+	return unpackEntry(pkg, d, unpackRoot, dir, opt)
+}
+
+// panicLocation returns the 'file:line' where the recovered panic happened, it must be called by the
+// deferred function.
+func panicLocation() string {
+	var pcs = make([]uintptr, 32)
+	var frames = runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			return fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// sortedExtensions returns the extensions sorted by the count desc.
+func sortedExtensions(exts map[string]int) []string {
+	var keys = make([]string, 0, len(exts))
+	for ext := range exts {
+		keys = append(keys, ext)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if exts[keys[i]] != exts[keys[j]] {
+			return exts[keys[i]] > exts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	return keys
+}
+
+func formatExtensions(exts map[string]int) string {
+	var parts []string
+	for _, ext := range sortedExtensions(exts) {
+		parts = append(parts, fmt.Sprintf("%s %d", ext, exts[ext]))
+	}
+
+	return strings.Join(parts, ", ")
 }
 
-var exts = make(map[string]int)
-var extsLocker = sync.Mutex{}
 var beautify = map[string]func([]byte) []byte{
 	".json": util.PrettyJson,
 	".html": util.PrettyHtml,
 	".js":   util.PrettyJavaScript,
 }
 
+// beautifyExtensions returns the extensions to beautify, like '.js', according to the flags
+// '--disable-beautify', '--beautify-only' and '--no-beautify'.
+func beautifyExtensions(cmd *cobra.Command) (map[string]bool, error) {
+	disableBeautify, _ := cmd.Flags().GetBool("disable-beautify")
+	only, _ := cmd.Flags().GetStringSlice("beautify-only")
+	no, _ := cmd.Flags().GetStringSlice("no-beautify")
+
+	var exts = make(map[string]bool)
+	if disableBeautify {
+		return exts, nil
+	}
+
+	for ext := range beautify {
+		exts[ext] = len(only) == 0
+	}
+	for _, list := range []struct {
+		names  []string
+		enable bool
+	}{{only, true}, {no, false}} {
+		for _, name := range list.names {
+			var ext = "." + strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), ".")
+			if _, ok := beautify[ext]; !ok {
+				return nil, fmt.Errorf(util.T("unknown beautify type '%s', supported: js, json, html"), name)
+			}
+			exts[ext] = list.enable
+		}
+	}
+
+	return exts, nil
+}
+
 func fileBeautify(name string, data []byte) (result []byte) {
 	defer func() {
 		if err := recover(); err != nil {
@@ -203,81 +1139,281 @@ func fileBeautify(name string, data []byte) (result []byte) {
 
 	var ext = filepath.Ext(name)
 
-	extsLocker.Lock()
-	exts[ext] = exts[ext] + 1
-	extsLocker.Unlock()
-
 	b, ok := beautify[ext]
 	if !ok {
 		return data
 	}
 
+	// keep the formatted ones to save time and avoid the reformat diffs, unless the json keys are sorted
+	if util.IsPretty(data) && !(ext == ".json" && util.JsonOptions.SortKeys) {
+		return data
+	}
+
 	return b(data)
 }
 
+var regAppId = regexp.MustCompile(`(wx[0-9a-f]{16})`)
+
 func parseWxid(root string) (string, error) {
-	var regAppId = regexp.MustCompile(`(wx[0-9a-f]{16})`)
 	if !regAppId.MatchString(filepath.Base(root)) {
-		return "", errors.New("the path is not a mini program path")
+		return "", errors.New(util.T("the path is not a mini program path"))
 	}
 
 	return regAppId.FindStringSubmatch(filepath.Base(root))[1], nil
 }
 
+// packageGroup is the wxapkg files of a mini program saved to the same folder.
+type packageGroup struct {
+	wxid   string
+	output string // the slash path relative to the output
+	files  []string
+	base   string // the folder the package paths are relative to, the parent of the root if empty
+}
+
+// scanVersions scans the version folders in the mini program path like 'Applet/wx1234567890abcdef',
+// every version is saved to the folder with the same name, or the output path directly in the flat layout,
+// which fails if several versions are selected.
+// Only the versions selected by the filter are scanned. The wxid is recovered from the packages if it's not
+// in the path.
+func scanVersions(root, layout string, filter util.VersionFilter) ([]packageGroup, error) {
+	wxid, wxidErr := parseWxid(root)
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, util.WithExitCode(util.ExitIO, err)
+	}
+	var dirs = make([]os.DirEntry, 0, len(entries))
+	for _, e := range entries {
+		if filter.Match(e.Name()) {
+			dirs = append(dirs, e)
+		}
+	}
+	if len(dirs) == 0 && len(entries) > 0 {
+		return nil, util.WithExitCode(util.ExitNoPackage, fmt.Errorf(util.T("no version in '%s' is selected by the version filter"), root))
+	}
+	if versions := versionNames(dirs); layout == layoutFlat && len(versions) > 1 {
+		return nil, fmt.Errorf(util.T("%d versions are found in '%s': %s, select one with '--version-filter' to unpack in the flat layout"), len(versions), root, strings.Join(versions, ", "))
+	}
+
+	var groups []packageGroup
+	for _, subDir := range dirs {
+		//修改开始
+		if subDir.Name() == ".DS_Store" {
+			continue
+		}
+		//修改结束
+		var group = packageGroup{wxid: wxid, output: subDir.Name()}
+		if layout == layoutFlat {
+			group.output = ""
+		}
+
+		group.files, err = scanFiles(filepath.Join(root, subDir.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if group.wxid == "" {
+			if group.wxid = recoverWxids(group.files); group.wxid == "" {
+				return nil, wxidErr
+			}
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+// newerGroups returns the groups with any wxapkg file modified after since or pending, all the files of the group are
+// unpacked again so that the manifest is complete.
+func newerGroups(groups []packageGroup, since time.Time, pending map[string]bool) []packageGroup {
+	var result []packageGroup
+	for _, group := range groups {
+		for _, file := range group.files {
+			if pending[absPath(file)] {
+				result = append(result, group)
+				break
+			}
+			if info, err := os.Stat(file); err == nil && info.ModTime().After(since) {
+				result = append(result, group)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// nextState returns the state after the run started at started. The time of the last run moves forward only
+// if all the packages are unpacked completely, the incomplete ones and the pending ones not in this run are
+// unpacked again by the next '--newer-than last'.
+func nextState(prev *util.State, started time.Time, jobs []packageJob, incomplete []string) *util.State {
+	var next = &util.State{LastRun: started}
+	if len(incomplete) > 0 {
+		next.LastRun = prev.LastRun
+	}
+
+	var attempted = make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		attempted[absPath(job.file)] = true
+	}
+	for _, file := range prev.Pending {
+		if !attempted[file] {
+			next.Pending = append(next.Pending, file)
+		}
+	}
+	for _, file := range incomplete {
+		next.Pending = append(next.Pending, absPath(file))
+	}
+	sort.Strings(next.Pending)
+
+	return next
+}
+
+// absPath returns the absolute path of the file, the path as is if it fails.
+func absPath(name string) string {
+	if abs, err := filepath.Abs(name); err == nil {
+		return abs
+	}
+
+	return name
+}
+
 func scanFiles(root string) ([]string, error) {
 	paths, err := util.GetDirAllFilePaths(root, "", ".wxapkg")
-	util.Fatal(err)
+	if err != nil {
+		return nil, util.WithExitCode(util.ExitIO, err)
+	}
 
 	if len(paths) == 0 {
-		return nil, errors.New(fmt.Sprintf("no '.wxapkg' file found in '%s'", root))
+		return nil, util.WithExitCode(util.ExitNoPackage, fmt.Errorf(util.T("no '.wxapkg' file found in '%s'"), root))
 	}
 
 	return paths, nil
 }
 
-func decryptFile(wxid, wxapkgPath string) []byte {
-	var (
-		salt = "saltiest"
-		iv   = "the iv: 16 bytes"
-	)
+// cryptoProfile is the profile selected by '--profile', nil to detect the profile of every package.
+var cryptoProfile *wxapkg.Profile
+
+// decryptor is the external command set by '--decryptor' to decrypt the packages instead of the profiles,
+// for the formats not supported yet, see util.RunDecryptor.
+var decryptor string
+
+// packageProfile returns the profile to decrypt the wxapkg file with the head, the packages on macOS and
+// the plaintext ones harvested elsewhere are not encrypted.
+func packageProfile(head []byte) *wxapkg.Profile {
+	if cryptoProfile != nil {
+		return cryptoProfile
+	}
+
+	return wxapkg.DetectProfile(head)
+}
+
+// openPackage opens the wxapkg file, the streaming one reads and decrypts the file on demand
+// instead of loading it into memory.
+func openPackage(wxid, wxapkgPath string, stream bool) (packageReader, io.Closer, error) {
+	if !stream || decryptor != "" {
+		data, err := decryptFile(wxid, wxapkgPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return bytes.NewReader(data), io.NopCloser(nil), nil
+	}
+
+	f, err := util.OpenSharedFile(wxapkgPath)
+	if err != nil {
+		return nil, nil, util.WithExitCode(util.ExitIO, err)
+	}
 
-	dataByte, err := os.ReadFile(wxapkgPath)
+	info, err := f.Stat()
 	if err != nil {
-		log.Fatal(err)
+		_ = f.Close()
+		return nil, nil, util.WithExitCode(util.ExitIO, err)
 	}
 
-	if runtime.GOOS == "darwin" {
-		return dataByte
+	var head = make([]byte, wxapkg.EncryptedHeadSize)
+	n, _ := f.ReadAt(head, 0)
+	d, err := packageProfile(head[:n]).NewDecrypter(f, info.Size(), wxid)
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, decryptError(wxapkgPath, err)
 	}
 
-	dk := pbkdf2.Key([]byte(wxid), []byte(salt), 1000, 32, sha1.New)
-	block, _ := aes.NewCipher(dk)
-	blockMode := cipher.NewCBCDecrypter(block, []byte(iv))
-	originData := make([]byte, 1024)
-	blockMode.CryptBlocks(originData, dataByte[6:1024+6])
+	return d, f, nil
+}
+
+func decryptFile(wxid, wxapkgPath string) ([]byte, error) {
+	dataByte, err := util.ReadSharedFile(wxapkgPath)
+	if err != nil {
+		return nil, util.WithExitCode(util.ExitIO, err)
+	}
 
-	afData := make([]byte, len(dataByte)-1024-6) // remove first 6 + 1024 byte
-	var xorKey = byte(0x66)
-	if len(wxid) >= 2 {
-		xorKey = wxid[len(wxid)-2]
+	if decryptor != "" {
+		originData, err := util.RunDecryptor(decryptor, wxid, dataByte)
+		if err != nil {
+			return nil, util.WithExitCode(util.ExitDecrypt, fmt.Errorf(util.T("failed to decrypt '%s' with the decryptor: %v"), wxapkgPath, err))
+		}
+		return originData, nil
 	}
-	for i, b := range dataByte[1024+6:] { // from 6 + 1024 byte
-		afData[i] = b ^ xorKey
+
+	originData, err := packageProfile(dataByte).Decrypt(wxid, dataByte)
+	if err != nil {
+		return nil, decryptError(wxapkgPath, err)
 	}
 
-	originData = append(originData[:1023], afData...)
+	return originData, nil
+}
 
-	return originData
+func decryptError(wxapkgPath string, err error) error {
+	switch {
+	case errors.Is(err, wxapkg.ErrTooSmall):
+		return util.WithExitCode(util.ExitDecrypt, fmt.Errorf(util.T("failed to decrypt '%s', the file is too small"), wxapkgPath))
+	case errors.Is(err, wxapkg.ErrWxid):
+		return util.WithExitCode(util.ExitDecrypt, fmt.Errorf(util.T("failed to decrypt '%s', please check the wxid"), wxapkgPath))
+	default:
+		return util.WithExitCode(util.ExitIO, err)
+	}
 }
 
 func init() {
 	RootCmd.AddCommand(unpackCmd)
 
-	var homeDir, _ = os.UserHomeDir()
-	var defaultRoot = filepath.Join(homeDir, "Documents/WeChat Files/Applet", "wx00000000000000")
+	var defaultRoot = filepath.Join(util.DefaultAppletRoot(), "wx00000000000000")
 
-	unpackCmd.Flags().StringP("root", "r", "", "the mini progress path you want to decrypt, see: "+defaultRoot)
-	unpackCmd.Flags().StringP("output", "o", "unpack", "the output path to save result")
-	unpackCmd.Flags().IntP("thread", "n", 30, "the thread number")
-	_ = unpackCmd.MarkFlagRequired("root")
+	unpackCmd.Flags().StringP("root", "r", "", util.T("the mini progress path you want to decrypt, see: ")+defaultRoot)
+	unpackCmd.Flags().StringP("output", "o", "unpack", util.T("the output path to save result, '{date}', '{time}' and '{run-id}' in it are replaced, like 'unpack/{date}'"))
+	unpackCmd.Flags().StringSlice("version-filter", nil, util.T("only unpack the version folders selected by the names or the ranges, like '12', '10-15', '>=12', separated by commas"))
+	unpackCmd.Flags().String("newer-than", "", util.T("only unpack the folders with the wxapkg files modified after the time like '2023-06-01', '24h' ago, or 'last' for the start of the last run in the output path"))
+	unpackCmd.Flags().Bool("all-accounts", false, util.T("unpack the mini programs of all the wechat accounts logged in on this machine, saved to '<account>/<wxid>/', the same as '--root' with the 'WeChat Files' folder"))
+	unpackCmd.Flags().String("dest", "", util.T("upload to the object storage like 's3://bucket/prefix', 'oss://', 'cos://', or the WebDAV server like 'webdavs://host/path' instead of the output path"))
+	unpackCmd.Flags().IntP("thread", "n", 0, util.T("the thread number, 0 for auto"))
+	unpackCmd.Flags().IntP("jobs", "j", 0, util.T("the number of packages unpacked in parallel, 0 for the number of CPUs"))
+	unpackCmd.Flags().String("max-write-rate", "", util.T("the maximum write rate like '200/s' for the files or '5MB/s' for the bytes per second, limited for the cloud-synced output path by default, 0 for unlimited"))
+	unpackCmd.Flags().String("max-memory", "", util.T("the memory budget like '512MB', the large packages are streamed instead of loaded"))
+	unpackCmd.Flags().String("wxid-map", "", util.T("csv file mapping the loose wxapkg file names to the wxids, like 'name.wxapkg,wx1234567890abcdef'"))
+	unpackCmd.Flags().Int("retries", 3, util.T("the retry times of the failed writes"))
+	unpackCmd.Flags().Bool("vendor", false, util.T("place known libraries (weui, vant, lodash, dayjs) under 'vendor/'"))
+	unpackCmd.Flags().Bool("licenses", false, util.T("detect license headers and save a license report to 'licenses.json'"))
+	unpackCmd.Flags().String("format", "dir", util.T("the output format, 'dir' or the archive formats: ")+strings.Join(util.ArchiveFormats, ", "))
+	unpackCmd.Flags().String("layout", layoutMerged, util.T("'merged' merges the packages of a version into one folder, 'package' saves every package to the folder named after its role like 'main/' and 'sub_pages_shop/', 'flat' merges the packages of the only version, or the one selected by '--version-filter', into the output path directly"))
+	unpackCmd.Flags().Bool("flat", false, util.T("the same as '--layout flat'"))
+	unpackCmd.Flags().Int("level", 0, util.T("the compression level of the archive, the default level is used if 0"))
+	unpackCmd.Flags().String("password", "", util.T("encrypt the zip archive with AES-256, read from the 'WXAPKG_PASSWORD' environment variable if empty"))
+	unpackCmd.Flags().Bool("transcode", false, util.T("transcode the text files in GBK to UTF-8, the original charset is recorded in the manifest"))
+	unpackCmd.Flags().Bool("unescape-unicode", false, util.T("decode the unicode escapes like '\\u4e2d' in the strings of the js and json files, so that the chinese text can be searched"))
+	unpackCmd.Flags().String("eol", util.EolKeep, util.T("the line endings of the text files, 'lf', 'crlf' or 'keep'"))
+	unpackCmd.Flags().String("bom", util.BomKeep, util.T("strip, add or keep the UTF-8 BOM of the text files, 'strip', 'add' or 'keep'"))
+	unpackCmd.Flags().String("normalize", util.NormNfc, util.T("the unicode normalization form of the file names, 'nfc', 'nfd' or 'keep', the original names are recorded in the manifest"))
+	unpackCmd.Flags().Bool("sources", false, util.T("restore the original sources like the TypeScript files from the inline source maps with 'sourcesContent' to 'src/'"))
+	unpackCmd.Flags().Bool("extract-maps", false, util.T("save the inline source maps to the sibling '.map' files and reference them in the scripts, for the devtools"))
+	unpackCmd.Flags().Bool("carve", false, util.T("carve the files by their signatures from the damaged packages with the broken index"))
+	unpackCmd.Flags().Bool("hardlink", false, util.T("hard link the identical files unpacked in the same run, like the shared libraries and fonts, editing a linked file changes all its copies"))
+	unpackCmd.Flags().String("cas", "", util.T("store the bodies of the files once in the content-addressable store folder and link the unpacked files to them, for the archives of many versions, the linked files are read-only"))
+	unpackCmd.Flags().Bool("link-versions", false, util.T("replace the files identical to the previous version of the mini program with the symlinks, the previous versions must be kept"))
+	unpackCmd.Flags().Bool("force", false, util.T("unpack the folders again even if their wxapkg files are not changed since the last unpacking"))
+	unpackCmd.Flags().String("progress", progressBar, util.T("the progress mode, 'bar' updates one line in place, 'plain' prints one line every interval for the CI logs, or 'none'"))
+	unpackCmd.Flags().Duration("progress-interval", 0, util.T("the minimum interval between the progress updates like '1s', 100ms for 'bar' and 5s for 'plain' by default"))
+	unpackCmd.Flags().BoolP("verbose", "v", false, util.T("print how long the decryption, the index parsing, the extraction and the beautifying took for every package"))
+	unpackCmd.Flags().Bool("json", false, util.T("print the packages, the errors with the stable codes like 'ERR_DECRYPT' and the summary in JSON lines"))
+	unpackCmd.Flags().Bool("open", false, util.T("open the output path in the file manager when finished"))
+	unpackCmd.Flags().String("transforms", "", util.T("json file with the transforms applied to the matching text files in order, like [{\"type\": \"strip-console\", \"files\": [\"*.js\"]}], the types are 'replace' with 'pattern' and 'replacement', 'strip-console' and 'redact-tokens'"))
+	unpackCmd.Flags().String("fingerprints", "", util.T("json file with the sha256 hashes of the library builds, like {\"<sha256>\": \"<library>\"}, the libraries are only detected by the paths and the banners without it"))
 }
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/wux1an/wxapkg/util"
+	"github.com/wux1an/wxapkg/wxapkg"
+)
+
+func TestUnpackFlatZip(t *testing.T) {
+	var p wxapkg.Packer
+	p.AddEntry("/app-config.json", p.AddBody([]byte(`{"pages":["pages/index/index"]}`)))
+	p.AddEntry("/app-service.js", p.AddBody([]byte("var a = 1;")))
+	p.AddEntry("/pages/index/index.wxml", p.AddBody([]byte("<view>index</view>")))
+	var buf bytes.Buffer
+	if _, err := p.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := util.NewArchiveOutput(filepath.Join(t.TempDir(), "out"), "zip", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var opt = unpackOptions{thread: 1, workers: make(chan struct{}, 1), restored: &sync.Map{}, output: out}
+	// the unpack root is empty in the flat layout
+	result, err := unpack(bytes.NewReader(buf.Bytes()), "", "", opt, func(done, total int) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.failures) > 0 {
+		t.Fatal(result.failures)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.OpenReader(out.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+
+	var want = []string{"app-config.json", "app-service.js", "pages/index/index.wxml"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("got the members %v, want %v", names, want)
+	}
+	for _, f := range result.files {
+		if strings.HasPrefix(f.Path, "/") {
+			t.Errorf("%s: the path in the manifest is absolute", f.Path)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+)
+
+// sharedChunkLibrary is the library name tagged to the shared vendor chunks in the manifests, so that they
+// are skipped like the known libraries.
+const sharedChunkLibrary = "shared-chunk"
+
+var vendorsCmd = &cobra.Command{
+	Use:     "vendors",
+	Short:   util.T("Find the vendor chunks shared by many different mini programs and tag them in the manifests"),
+	Example: "  " + programName + " vendors -o unpack --min-apps 3",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		minApps, _ := cmd.Flags().GetInt("min-apps")
+		minSize, _ := cmd.Flags().GetString("min-size")
+		noTag, _ := cmd.Flags().GetBool("no-tag")
+
+		size, err := util.ParseSize(minSize)
+		util.Fatal(err)
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		var manifests = make(map[string]*util.Manifest, len(dirs))
+		var chunks = make(map[string]*vendorChunk) // sha256 -> chunk
+		var names = make(map[string]map[string]int)
+		for _, dir := range dirs {
+			manifest, err := util.ReadManifest(dir)
+			util.Fatal(err)
+			manifests[dir] = manifest
+
+			for _, f := range manifest.Files {
+				var ext = strings.ToLower(path.Ext(f.Path))
+				if f.Sha256 == "" || int64(f.Size) < size || (ext != ".js" && ext != ".wxss") {
+					continue
+				}
+				var chunk = chunks[f.Sha256]
+				if chunk == nil {
+					chunk = &vendorChunk{Sha256: f.Sha256, Size: int64(f.Size)}
+					chunks[f.Sha256] = chunk
+					names[f.Sha256] = make(map[string]int)
+				}
+				chunk.Apps = appendUnique(chunk.Apps, manifest.Wxid)
+				chunk.Files++
+				names[f.Sha256][path.Base(f.Path)]++
+			}
+		}
+
+		var shared = []vendorChunk{}
+		for sum, chunk := range chunks {
+			if len(chunk.Apps) < minApps {
+				continue
+			}
+			chunk.Name = commonName(names[sum])
+			sort.Strings(chunk.Apps)
+			shared = append(shared, *chunk)
+		}
+		sort.Slice(shared, func(i, j int) bool {
+			if len(shared[i].Apps) != len(shared[j].Apps) {
+				return len(shared[i].Apps) > len(shared[j].Apps)
+			}
+			return shared[i].Sha256 < shared[j].Sha256
+		})
+
+		for _, chunk := range shared {
+			color.Cyan(util.T("  - %s  %s, in %d mini programs, %d files\n"), chunk.Name, formatSize(chunk.Size), len(chunk.Apps), chunk.Files)
+		}
+		var report = filepath.Join(output, "vendors.json")
+		util.Fatal(util.SaveJson(report, shared))
+		color.Cyan(util.T("[+] %d shared vendor chunks found in %d unpacked folders, saved to '%s'\n"), len(shared), len(dirs), report)
+		if noTag || len(shared) == 0 {
+			return
+		}
+
+		var tagged = 0
+		for _, dir := range dirs {
+			var manifest = manifests[dir]
+			var changed = false
+			for i, f := range manifest.Files {
+				if chunk := chunks[f.Sha256]; chunk != nil && len(chunk.Apps) >= minApps && f.Library == "" {
+					manifest.Files[i].Library = sharedChunkLibrary
+					changed = true
+					tagged++
+				}
+			}
+			if changed {
+				util.Fatal(util.SaveJson(filepath.Join(dir, util.ManifestName), manifest))
+			}
+		}
+		color.Cyan(util.T("[+] %d files tagged as '%s' in the manifests, they are skipped by the audit\n"), tagged, sharedChunkLibrary)
+	},
+}
+
+// vendorChunk is a file identical in many different mini programs, like the 'vendor.js' of the build tools.
+type vendorChunk struct {
+	Sha256 string   `json:"sha256"`
+	Name   string   `json:"name"` // the most common file name
+	Size   int64    `json:"size"`
+	Apps   []string `json:"apps"`
+	Files  int      `json:"files"`
+}
+
+// commonName returns the name with the most count, the first one by the order if tied.
+func commonName(names map[string]int) string {
+	var result string
+	for name, count := range names {
+		if result == "" || count > names[result] || count == names[result] && name < result {
+			result = name
+		}
+	}
+
+	return result
+}
+
+func init() {
+	RootCmd.AddCommand(vendorsCmd)
+
+	vendorsCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	vendorsCmd.Flags().Int("min-apps", 3, util.T("the minimum number of the different mini programs sharing the chunk"))
+	vendorsCmd.Flags().String("min-size", "1KB", util.T("the minimum size of the chunks, the tiny files are shared by chance"))
+	vendorsCmd.Flags().Bool("no-tag", false, util.T("only report the chunks, do not tag them in the manifests"))
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wux1an/wxapkg/util"
+	"github.com/wux1an/wxapkg/wxapkg"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [file.wxapkg...]",
+	Short: util.T("Verify the unpacked files with the checksums in the manifest, or the wxapkg files without extraction"),
+	Example: "  " + programName + " verify -o unpack/wx12345678901234\n" +
+		"  " + programName + " verify \"D:\\WeChat Files\\Applet\\wx12345678901234\\12\\__APP__.wxapkg\"",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		wxid, _ := cmd.Flags().GetString("wxid")
+
+		if len(args) > 0 {
+			verifyPackages(args, wxid)
+			return
+		}
+
+		dirs, err := util.FindUnpackedDirs(output)
+		util.Fatal(err)
+
+		var problems = 0
+		var checked = 0
+		for _, dir := range dirs {
+			manifest, err := util.ReadManifest(dir)
+			util.Fatal(err)
+
+			for _, f := range manifest.Files {
+				checked++
+				if err := verifyFile(dir, f); err != nil {
+					problems++
+					color.Red("  - %v\n", err)
+				}
+			}
+		}
+
+		if problems > 0 {
+			util.Fatal(util.WithExitCode(util.ExitVerify, fmt.Errorf(util.T("%d of %d files are modified or missing"), problems, checked)))
+		}
+		color.Cyan(util.T("[+] all %d files in %d unpacked folders are verified\n"), checked, len(dirs))
+	},
+}
+
+func verifyFile(dir string, f util.ManifestFile) error {
+	var path = filepath.Join(dir, filepath.FromSlash(f.Path))
+	if f.Sha256 == "" {
+		return fmt.Errorf(util.T("%s: no checksum in the manifest"), path)
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf(util.T("%s: missing"), path)
+	}
+	if err != nil {
+		return err
+	}
+
+	if util.Sha256(data) != f.Sha256 {
+		return fmt.Errorf(util.T("%s: modified"), path)
+	}
+
+	return nil
+}
+
+func verifyPackages(paths []string, wxid string) {
+	var invalid = 0
+	for _, path := range paths {
+		var id = wxid
+		if id == "" {
+			id = regAppId.FindString(path)
+		}
+
+		var header, problems, warnings, err = verifyPackage(id, path)
+		if err != nil {
+			problems = append(problems, err)
+		}
+
+		if len(problems) == 0 {
+			color.Cyan(util.T("[+] '%s' is valid\n"), path)
+			color.Cyan(util.T("  - info1 0x%08x, %d files, the index %d bytes, the body %d bytes\n"), header.Info1, header.FileCount, header.IndexInfoLength, header.BodyInfoLength)
+			for _, w := range warnings {
+				color.Yellow("  - %v\n", w)
+			}
+			continue
+		}
+
+		invalid++
+		color.Red(util.T("[-] '%s' is invalid:\n"), path)
+		for _, p := range problems {
+			color.Red("  - %v\n", p)
+		}
+		for _, w := range warnings {
+			color.Yellow("  - %v\n", w)
+		}
+	}
+
+	if invalid > 0 {
+		util.Fatal(util.WithExitCode(util.ExitInvalidPackage, fmt.Errorf(util.T("%d of %d wxapkg files are invalid"), invalid, len(paths))))
+	}
+}
+
+// verifyPackage decrypts the wxapkg file and checks the index is consistent with the body. The placeholder
+// entries are reported as the warnings, and the entries with the same offset and size share the body.
+func verifyPackage(wxid, path string) (*wxapkg.Header, []error, []error, error) {
+	pkg, closer, err := openPackage(wxid, path, true)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer closer.Close()
+
+	header, files, err := readIndex(pkg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var problems, warnings []error
+	if !header.KnownInfo1() {
+		problems = append(problems, fmt.Errorf(util.T("the info1 0x%08x in the header is unknown, the format may be changed"), header.Info1))
+	}
+	var indexLength = wxapkg.IndexLength(files)
+	if indexLength != int64(header.IndexInfoLength) {
+		problems = append(problems, fmt.Errorf(util.T("the index length is %d, but %d in the header"), indexLength, header.IndexInfoLength))
+	}
+
+	var bodyStart = int64(wxapkg.HeaderSize) + int64(header.IndexInfoLength)
+	if bodyStart+int64(header.BodyInfoLength) > pkg.Size() { // the small ones are padded when encrypted
+		problems = append(problems, fmt.Errorf(util.T("the body length is %d, but %d in the header"), pkg.Size()-bodyStart, header.BodyInfoLength))
+	}
+
+	var names = make(map[string]bool)
+	var bodies []wxapkg.Entry // the entries with a body
+	for _, f := range files {
+		if names[f.Name] {
+			problems = append(problems, fmt.Errorf(util.T("%s: duplicate file"), f.Name))
+		}
+		names[f.Name] = true
+
+		if f.IsPlaceholder() {
+			warnings = append(warnings, fmt.Errorf(util.T("%s: placeholder entry without a body, offset %d, size %d"), f.Name, f.Offset, f.Size))
+			continue
+		}
+		if int64(f.Offset) < bodyStart || int64(f.Offset)+int64(f.Size) > pkg.Size() {
+			problems = append(problems, fmt.Errorf(util.T("%s: out of range [%d:%d]"), f.Name, f.Offset, int64(f.Offset)+int64(f.Size)))
+		}
+		bodies = append(bodies, f)
+	}
+
+	problems = append(problems, checkOverlaps(bodies)...)
+
+	return header, problems, warnings, nil
+}
+
+// checkOverlaps reports the entries overlapping with the bodies of the others, each entry is compared with the
+// one reaching the furthest before it, so the entries inside a large body are all found. The entries with the
+// same offset and size share the body.
+func checkOverlaps(entries []wxapkg.Entry) []error {
+	var sorted = append([]wxapkg.Entry{}, entries...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Offset != sorted[j].Offset {
+			return sorted[i].Offset < sorted[j].Offset
+		}
+		return sorted[i].Size > sorted[j].Size
+	})
+
+	var problems []error
+	var furthest wxapkg.Entry // the entry with the largest end so far
+	for i, cur := range sorted {
+		var end = int64(cur.Offset) + int64(cur.Size)
+		if i == 0 {
+			furthest = cur
+			continue
+		}
+		var furthestEnd = int64(furthest.Offset) + int64(furthest.Size)
+		if cur.Offset == furthest.Offset && cur.Size == furthest.Size {
+			continue // the identical files share the body
+		}
+		if furthestEnd > int64(cur.Offset) {
+			problems = append(problems, fmt.Errorf(util.T("%s: overlaps with %s"), cur.Name, furthest.Name))
+		}
+		if end > furthestEnd {
+			furthest = cur
+		}
+	}
+
+	return problems
+}
+
+func init() {
+	RootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().StringP("output", "o", "unpack", util.T("the unpacked path"))
+	verifyCmd.Flags().String("wxid", "", util.T("the wxid to decrypt the wxapkg files, detected from the path if empty"))
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/wux1an/wxapkg/wxapkg"
+)
+
+func TestCheckOverlaps(t *testing.T) {
+	var tests = []struct {
+		name    string
+		entries []wxapkg.Entry
+		want    int
+	}{
+		{"apart", []wxapkg.Entry{{Name: "/a", Offset: 0, Size: 10}, {Name: "/b", Offset: 10, Size: 10}}, 0},
+		{"shared", []wxapkg.Entry{{Name: "/a", Offset: 0, Size: 10}, {Name: "/b", Offset: 0, Size: 10}}, 0},
+		{"inside", []wxapkg.Entry{{Name: "/a", Offset: 0, Size: 100}, {Name: "/b", Offset: 10, Size: 10}, {Name: "/c", Offset: 30, Size: 10}}, 2},
+		{"shared inside", []wxapkg.Entry{{Name: "/a", Offset: 0, Size: 100}, {Name: "/b", Offset: 10, Size: 10}, {Name: "/c", Offset: 10, Size: 10}}, 2},
+		{"same offset", []wxapkg.Entry{{Name: "/a", Offset: 0, Size: 5}, {Name: "/b", Offset: 0, Size: 10}}, 1},
+	}
+
+	for _, tt := range tests {
+		if got := checkOverlaps(tt.entries); len(got) != tt.want {
+			t.Errorf("%s: got %d overlaps %v, want %d", tt.name, len(got), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"io"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+
+	"github.com/fatih/color"
+	"github.com/wux1an/wxapkg/util"
+	"github.com/wux1an/wxapkg/wxapkg"
+)
+
+// regConfigAppId matches the appid in the 'app-config.json' or the 'ext.json'.
+var regConfigAppId = regexp.MustCompile(`"(?:appid|appId|extAppid)"\s*:\s*"(wx[0-9a-f]{16})"`)
+
+// recoverWxids returns the first wxid recovered from the wxapkg files, see recoverWxid.
+func recoverWxids(files []string) string {
+	for _, file := range files {
+		if wxid := recoverWxid(file); wxid != "" {
+			color.Yellow(util.T("[!] wxid '%s' is recovered from '%s'\n"), wxid, file)
+			return wxid
+		}
+	}
+
+	return ""
+}
+
+// recoverWxid finds the wxid of the wxapkg file without a wxid in its name or the mini program path.
+// The encrypted one is decrypted with the wxids in its full path by trial, the nearest one first, and the
+// plaintext one is labeled with the appid in its 'app-config.json' or 'ext.json'. It returns "" if not found.
+func recoverWxid(file string) string {
+	f, err := util.OpenSharedFile(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return ""
+	}
+
+	var head = make([]byte, wxapkg.EncryptedHeadSize)
+	n, err := f.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return ""
+	}
+	var profile = packageProfile(head[:n])
+	if !profile.Encrypted() {
+		reader, err := wxapkg.NewReader(f, info.Size())
+		if err != nil {
+			return ""
+		}
+		return configAppId(reader)
+	}
+
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		abs = file
+	}
+	var candidates = regAppId.FindAllString(abs, -1)
+	for i := len(candidates) - 1; i >= 0; i-- {
+		d, err := profile.NewDecrypter(f, info.Size(), candidates[i])
+		if err != nil {
+			continue
+		}
+		// the index is garbage if the key is wrong
+		if _, err := wxapkg.NewReader(d, d.Size()); err == nil {
+			return candidates[i]
+		}
+	}
+
+	return ""
+}
+
+// configAppId returns the appid in the 'app-config.json' or the 'ext.json' of the package.
+func configAppId(reader *wxapkg.Reader) string {
+	for _, name := range []string{util.AppConfigName, "ext.json"} {
+		data, err := fs.ReadFile(reader, name)
+		if err != nil {
+			continue
+		}
+		if m := regConfigAppId.FindSubmatch(data); m != nil {
+			return string(m[1])
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,44 @@
+package util
+
+import (
+	"regexp"
+	"sort"
+)
+
+var regWxApi = regexp.MustCompile(`\bwx\.(\w+)\s*\(`)
+var regUrl = regexp.MustCompile("(?i)\\b(?:https?|wss?)://[^\\s\"'`<>\\\\()]+")
+
+// FindApis returns the sorted `wx.*` APIs called in the code, like 'request'.
+func FindApis(code []byte) []string {
+	var result []string
+	for _, match := range regWxApi.FindAllSubmatch(code, -1) {
+		result = append(result, string(match[1]))
+	}
+
+	return UniqueSorted(result)
+}
+
+// FindUrls returns the sorted http(s) and ws(s) urls in the code.
+func FindUrls(code []byte) []string {
+	var result []string
+	for _, match := range regUrl.FindAll(code, -1) {
+		result = append(result, string(match))
+	}
+
+	return UniqueSorted(result)
+}
+
+// UniqueSorted removes the duplicates and sorts the list.
+func UniqueSorted(list []string) []string {
+	var seen = make(map[string]bool)
+	var result = make([]string, 0, len(list))
+	for _, s := range list {
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	sort.Strings(result)
+
+	return result
+}
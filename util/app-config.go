@@ -0,0 +1,135 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AppConfigName is the merged config of the mini program, saved in the main package.
+const AppConfigName = "app-config.json"
+
+type SubPackage struct {
+	Root        string                  `json:"root"`
+	Pages       []string                `json:"pages"`
+	Plugins     map[string]PluginConfig `json:"plugins"`
+	Independent bool                    `json:"independent"` // runs without the main package
+}
+
+type PluginConfig struct {
+	Version  string `json:"version"`
+	Provider string `json:"provider"` // the appid of the plugin
+}
+
+type TabBarItem struct {
+	PagePath string `json:"pagePath"`
+	Text     string `json:"text"`
+}
+
+type TabBar struct {
+	List []TabBarItem `json:"list"`
+}
+
+type PageConfig struct {
+	Window map[string]interface{} `json:"window"`
+}
+
+type AppConfig struct {
+	EntryPagePath   string                  `json:"entryPagePath"`
+	Pages           []string                `json:"pages"`
+	SubPackages     []SubPackage            `json:"subPackages"`
+	TabBar          TabBar                  `json:"tabBar"`
+	Global          PageConfig              `json:"global"`
+	Page            map[string]PageConfig   `json:"page"`    // 'pages/index/index.html' -> config
+	Plugins         map[string]PluginConfig `json:"plugins"` // alias -> plugin
+	LazyCodeLoading string                  `json:"lazyCodeLoading"`
+
+	// merged from 'ext.json' by the third-party platform
+	ExtAppid string                 `json:"extAppid"`
+	Ext      map[string]interface{} `json:"ext"`
+}
+
+// ReadAppConfig loads the 'app-config.json' in the unpacked folder dir.
+func ReadAppConfig(dir string) (*AppConfig, error) {
+	data, err := os.ReadFile(filepath.Join(dir, AppConfigName))
+	if err != nil {
+		return nil, err
+	}
+
+	var config AppConfig
+	if err := UnmarshalJson(data, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// IsPage reports whether the module (like 'pages/index/index.js') is a page.
+func (c *AppConfig) IsPage(module string) bool {
+	var name = strings.TrimSuffix(module, filepath.Ext(module))
+	for _, page := range c.Pages {
+		if page == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EntryPage returns the entry page without the '.html' suffix, the first page by default.
+func (c *AppConfig) EntryPage() string {
+	if c.EntryPagePath != "" {
+		return strings.TrimSuffix(c.EntryPagePath, ".html")
+	}
+	if len(c.Pages) > 0 {
+		return c.Pages[0]
+	}
+
+	return ""
+}
+
+// UsingComponents returns the custom components (tag -> path) declared by the page or component,
+// the global components are included.
+func (c *AppConfig) UsingComponents(page string) map[string]string {
+	var result = make(map[string]string)
+	var collect = func(window map[string]interface{}) {
+		components, _ := window["usingComponents"].(map[string]interface{})
+		for tag, p := range components {
+			if s, ok := p.(string); ok {
+				result[tag] = s
+			}
+		}
+	}
+
+	collect(c.Global.Window)
+	collect(c.Page[page+".html"].Window)
+
+	return result
+}
+
+// SubPackageOf returns the subpackage the page belongs to, nil for the main package.
+func (c *AppConfig) SubPackageOf(page string) *SubPackage {
+	for i, sub := range c.SubPackages {
+		var root = strings.TrimSuffix(sub.Root, "/") + "/"
+		if strings.HasPrefix(page, root) {
+			return &c.SubPackages[i]
+		}
+	}
+
+	return nil
+}
+
+// AllPlugins returns the plugins used by the main package and the subpackages, alias -> plugin.
+func (c *AppConfig) AllPlugins() map[string]PluginConfig {
+	var result = make(map[string]PluginConfig)
+	for alias, p := range c.Plugins {
+		result[alias] = p
+	}
+	for _, sub := range c.SubPackages {
+		for alias, p := range sub.Plugins {
+			result[alias] = p
+		}
+	}
+
+	return result
+}
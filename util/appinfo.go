@@ -0,0 +1,102 @@
+package util
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// AppInfoName is the file name of the metadata card saved in every unpacked folder, see AppInfo.
+const AppInfoName = "app.info.json"
+
+var regLibVersion = regexp.MustCompile(`(?:libVersion|sdkVersion|SDKVersion)["']?\s*[:=]\s*["'](\d+\.\d+\.\d+)["']`)
+
+// AppInfo is the metadata card of the unpacked mini program, for the inventory systems.
+type AppInfo struct {
+	Wxid             string          `json:"wxid"`
+	Name             string          `json:"name"`    // the nickname queried before, or the title of the window
+	Version          string          `json:"version"` // the version folder of the wxapkg files
+	Time             time.Time       `json:"time"`
+	BaseLibraryHints []string        `json:"base_library_hints"`
+	Size             int64           `json:"size"`
+	Packages         []PackageStats  `json:"packages"`
+	Pages            int             `json:"pages"`
+	SubPackages      int             `json:"subpackages"`
+	Plugins          []AppInfoPlugin `json:"plugins"`
+
+	config *AppConfig
+}
+
+// AppInfoPlugin is a plugin used by the mini program.
+type AppInfoPlugin struct {
+	Alias    string `json:"alias"`
+	Provider string `json:"provider"`
+	Version  string `json:"version"`
+}
+
+// NewAppInfo returns the empty card of the version of the mini program wxid.
+func NewAppInfo(wxid, version string) *AppInfo {
+	return &AppInfo{Wxid: wxid, Version: version, BaseLibraryHints: []string{}, Plugins: []AppInfoPlugin{}}
+}
+
+// FindLibVersions returns the base library versions mentioned in the code, like `libVersion: "2.19.4"`.
+func FindLibVersions(data []byte) []string {
+	var result []string
+	for _, match := range regLibVersion.FindAllSubmatch(data, -1) {
+		result = append(result, string(match[1]))
+	}
+
+	return result
+}
+
+// AddHints adds the hints of the base library version.
+func (i *AppInfo) AddHints(hints []string) {
+	i.BaseLibraryHints = UniqueSorted(append(i.BaseLibraryHints, hints...))
+}
+
+// SetConfig reads the pages and the plugins from the data of 'app-config.json'.
+func (i *AppInfo) SetConfig(data []byte) error {
+	var config AppConfig
+	if err := UnmarshalJson(data, &config); err != nil {
+		return err
+	}
+	i.config = &config
+
+	i.Pages = len(config.Pages)
+	i.SubPackages = len(config.SubPackages)
+	for alias, p := range config.AllPlugins() {
+		i.Plugins = append(i.Plugins, AppInfoPlugin{Alias: alias, Provider: p.Provider, Version: p.Version})
+	}
+	sort.Slice(i.Plugins, func(a, b int) bool {
+		return i.Plugins[a].Alias < i.Plugins[b].Alias
+	})
+
+	// the lazy code loading requires the base library 2.11.1 or later
+	if config.LazyCodeLoading != "" {
+		i.AddHints([]string{">=2.11.1 (lazyCodeLoading)"})
+	}
+
+	return nil
+}
+
+// Finish fills the packages, the time and the name after all the packages are unpacked.
+func (i *AppInfo) Finish(m *Manifest) {
+	i.Time = m.Time
+	i.Packages = m.Packages
+	i.Size = 0
+	for _, p := range m.Packages {
+		i.Size += p.Size
+	}
+
+	if info, ok := cachedWxid[i.Wxid]; ok && info.Nickname != "" {
+		i.Name = info.Nickname
+	} else if i.config != nil {
+		i.Name, _ = i.config.Global.Window["navigationBarTitleText"].(string)
+	}
+}
+
+// Save saves the card to the folder dir in the output.
+func (i *AppInfo) Save(out Output, dir string) error {
+	return WriteJson(out, path.Join(dir, AppInfoName), i)
+}
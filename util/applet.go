@@ -0,0 +1,127 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// FindAppletRoots probes the known locations and returns the existing applet roots,
+// which contain the 'wx...' folders of the mini programs.
+func FindAppletRoots() []string {
+	var homeDir, _ = os.UserHomeDir()
+
+	var candidates []string
+	for _, files := range wechatFilesCandidates() {
+		candidates = append(candidates, filepath.Join(files, "Applet"))
+	}
+	switch runtime.GOOS {
+	case "windows":
+		candidates = append(candidates, filepath.Join(os.Getenv("APPDATA"), "Tencent/WeChat/radium/Applet/packages"))
+	case "darwin":
+		candidates = append(candidates,
+			filepath.Join(homeDir, "Library/Containers/com.tencent.xinWeChat/Data/.wxapplet/packages"),
+		)
+	default: // wechat running in wine
+		candidates = append(candidates, filepath.Join(homeDir, ".xwechat/radium/Applet/packages"))
+	}
+
+	var result []string
+	var seen = make(map[string]bool)
+	for _, c := range candidates {
+		c = filepath.Clean(c)
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+
+		if info, err := os.Stat(c); err == nil && info.IsDir() {
+			result = append(result, c)
+		}
+	}
+
+	return result
+}
+
+// DefaultAppletRoot returns the first discovered applet root, or the default location if none is found.
+func DefaultAppletRoot() string {
+	if roots := FindAppletRoots(); len(roots) > 0 {
+		return roots[0]
+	}
+
+	var homeDir, _ = os.UserHomeDir()
+	if runtime.GOOS == "darwin" {
+		return filepath.Join(homeDir, "Library/Containers/com.tencent.xinWeChat/Data/.wxapplet/packages/")
+	}
+
+	return filepath.Join(homeDir, "Documents/WeChat Files/Applet")
+}
+
+// wechatFilesCandidates returns the possible 'WeChat Files' folders, which contain the folders of the accounts.
+func wechatFilesCandidates() []string {
+	var homeDir, _ = os.UserHomeDir()
+	switch runtime.GOOS {
+	case "windows":
+		return append(wechatFilesPaths(), filepath.Join(homeDir, "Documents/WeChat Files"))
+	case "darwin":
+		return nil
+	default: // wechat running in wine
+		return []string{
+			filepath.Join(homeDir, ".wine/drive_c/users", os.Getenv("USER"), "Documents/WeChat Files"),
+			filepath.Join(homeDir, ".deepinwine/Deepin-WeChat/drive_c/users", os.Getenv("USER"), "My Documents/WeChat Files"),
+		}
+	}
+}
+
+// AppletRoot is the applet root of a logged-in wechat account.
+type AppletRoot struct {
+	Account string // the folder name of the account, empty for the root shared by the accounts
+	Path    string
+}
+
+// AccountAppletRoots returns the applet roots of the accounts in the 'WeChat Files' folder files,
+// like 'WeChat Files/<account>/Applet'.
+func AccountAppletRoots(files string) []AppletRoot {
+	entries, err := os.ReadDir(files)
+	if err != nil {
+		return nil
+	}
+
+	var result []AppletRoot
+	for _, e := range entries {
+		if !e.IsDir() || e.Name() == "Applet" {
+			continue
+		}
+		var p = filepath.Join(files, e.Name(), "Applet")
+		if info, err := os.Stat(p); err == nil && info.IsDir() {
+			result = append(result, AppletRoot{Account: e.Name(), Path: p})
+		}
+	}
+
+	return result
+}
+
+// FindAccountAppletRoots returns the applet roots of all the accounts logged in on this machine, and the
+// shared roots found by FindAppletRoots.
+func FindAccountAppletRoots() []AppletRoot {
+	var result []AppletRoot
+	var seen = make(map[string]bool)
+	var add = func(root AppletRoot) {
+		root.Path = filepath.Clean(root.Path)
+		if !seen[root.Path] {
+			seen[root.Path] = true
+			result = append(result, root)
+		}
+	}
+
+	for _, files := range wechatFilesCandidates() {
+		for _, root := range AccountAppletRoots(files) {
+			add(root)
+		}
+	}
+	for _, p := range FindAppletRoots() {
+		add(AppletRoot{Path: p})
+	}
+
+	return result
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+
+package util
+
+// wechatFilesPaths returns the custom 'WeChat Files' paths configured in the wechat client.
+func wechatFilesPaths() []string {
+	return nil
+}
+
+func findWindowsClient() (WechatClient, bool) {
+	return WechatClient{}, false
+}
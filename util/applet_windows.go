@@ -0,0 +1,60 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// wechatFilesPaths returns the custom 'WeChat Files' paths configured in the wechat client.
+func wechatFilesPaths() []string {
+	var result []string
+	var add = func(p string) {
+		p = strings.TrimSpace(p)
+		if p == "" || strings.HasPrefix(p, "MyDocument:") { // the default location
+			return
+		}
+		result = append(result, filepath.Join(p, "WeChat Files"))
+	}
+
+	if key, err := registry.OpenKey(registry.CURRENT_USER, `Software\Tencent\WeChat`, registry.QUERY_VALUE); err == nil {
+		if p, _, err := key.GetStringValue("FileSavePath"); err == nil {
+			add(p)
+		}
+		_ = key.Close()
+	}
+
+	var ini = filepath.Join(os.Getenv("APPDATA"), `Tencent\WeChat\All Users\config\3ebffe94.ini`)
+	if data, err := os.ReadFile(ini); err == nil {
+		add(string(data))
+	}
+
+	return result
+}
+
+// findWindowsClient returns the wechat client registered by the installer, the 4.x (Weixin) first.
+func findWindowsClient() (WechatClient, bool) {
+	if key, err := registry.OpenKey(registry.CURRENT_USER, `Software\Tencent\Weixin`, registry.QUERY_VALUE); err == nil {
+		defer key.Close()
+		if p, _, err := key.GetStringValue("InstallPath"); err == nil && p != "" {
+			return WechatClient{Path: p, Version: latestVersionDir(p)}, true
+		}
+	}
+
+	if key, err := registry.OpenKey(registry.CURRENT_USER, `Software\Tencent\WeChat`, registry.QUERY_VALUE); err == nil {
+		defer key.Close()
+		if p, _, err := key.GetStringValue("InstallPath"); err == nil && p != "" {
+			var client = WechatClient{Path: p}
+			// like 0x63090a13 for 3.9.10.19, the high nibble is always 6
+			if v, _, err := key.GetIntegerValue("Version"); err == nil {
+				client.Version = fmt.Sprintf("%d.%d.%d.%d", v>>24&0x0f, v>>16&0xff, v>>8&0xff, v&0xff)
+			}
+			return client, true
+		}
+	}
+
+	return WechatClient{}, false
+}
@@ -0,0 +1,279 @@
+package util
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ArchiveFormats are the supported archive formats, the extension of the archive file is the same.
+var ArchiveFormats = []string{"zip", "tar.gz", "tar.zst", "7z"}
+
+// ArchiveOutput saves the files into an archive file instead of a folder.
+type ArchiveOutput struct {
+	path   string
+	file   *os.File
+	locker sync.Mutex
+
+	zip        *zip.Writer
+	password   string // encrypt the zip entries with AES-256 if not empty
+	level      int
+	tar        *tar.Writer
+	compressor io.WriteCloser // the gzip writer or the stdin of zstd
+	zstd       *exec.Cmd
+
+	// the files are staged in the temporary folder and compressed by the '7z' command when closed
+	sevenZip string
+	staging  DirOutput
+}
+
+// NewArchiveOutput creates the archive file, the level is the compression level of the format, the
+// default level is used if it's 0. The 'tar.zst' and '7z' formats require the 'zstd' and '7z' commands.
+func NewArchiveOutput(path, format string, level int) (*ArchiveOutput, error) {
+	if !isArchiveFormat(format) {
+		return nil, fmt.Errorf(T("unsupported archive format '%s', supported: %s"), format, strings.Join(ArchiveFormats, ", "))
+	}
+	if !strings.HasSuffix(path, "."+format) {
+		path += "." + format
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, WithExitCode(ExitIO, err)
+	}
+
+	var a = &ArchiveOutput{path: path, file: file, level: level}
+	switch format {
+	case "zip":
+		a.zip = zip.NewWriter(file)
+		if level != 0 {
+			a.zip.RegisterCompressor(zip.Deflate, func(w io.Writer) (io.WriteCloser, error) {
+				return flate.NewWriter(w, level)
+			})
+		}
+	case "tar.gz":
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		if a.compressor, err = gzip.NewWriterLevel(file, level); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		a.tar = tar.NewWriter(a.compressor)
+	case "tar.zst":
+		if err := a.startZstd(level); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		a.tar = tar.NewWriter(a.compressor)
+	case "7z":
+		if err := a.stage7z(); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+func isArchiveFormat(format string) bool {
+	for _, f := range ArchiveFormats {
+		if f == format {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetPassword encrypts the entries with the password, only the 'zip' format supports it.
+func (a *ArchiveOutput) SetPassword(password string) error {
+	if a.zip == nil {
+		return errors.New(T("the password is only supported by the 'zip' format"))
+	}
+	a.password = password
+
+	return nil
+}
+
+// startZstd pipes the tar stream to the 'zstd' command.
+func (a *ArchiveOutput) startZstd(level int) error {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		return errors.New(T("'zstd' is not found, it's required by the 'tar.zst' format"))
+	}
+
+	var args = []string{"-q", "-T0", "-c"}
+	if level > 19 {
+		args = append(args, "--ultra")
+	}
+	if level != 0 {
+		args = append(args, "-"+strconv.Itoa(level))
+	}
+
+	a.zstd = exec.Command("zstd", args...)
+	a.zstd.Stdout = a.file
+	a.zstd.Stderr = os.Stderr
+	stdin, err := a.zstd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	a.compressor = stdin
+
+	return a.zstd.Start()
+}
+
+// stage7z creates the temporary folder for the '7z' command, any of '7z', '7zz', '7za' and '7zr'.
+func (a *ArchiveOutput) stage7z() error {
+	for _, name := range []string{"7z", "7zz", "7za", "7zr"} {
+		if path, err := exec.LookPath(name); err == nil {
+			a.sevenZip = path
+			break
+		}
+	}
+	if a.sevenZip == "" {
+		return errors.New(T("'7z' is not found, it's required by the '7z' format"))
+	}
+
+	dir, err := os.MkdirTemp("", "wxapkg-7z-")
+	if err != nil {
+		return WithExitCode(ExitIO, err)
+	}
+	a.staging = DirOutput(dir)
+
+	return nil
+}
+
+// compress7z compresses the staged files with the solid compression, which helps a lot with the
+// many small js files.
+func (a *ArchiveOutput) compress7z() error {
+	defer os.RemoveAll(string(a.staging))
+
+	// 7z appends to the existing archive
+	_ = a.file.Close()
+	_ = os.Remove(a.path)
+	path, err := filepath.Abs(a.path)
+	if err != nil {
+		return err
+	}
+
+	var args = []string{"a", "-t7z", "-ms=on", "-bd", "-y"}
+	if a.level != 0 {
+		args = append(args, "-mx="+strconv.Itoa(a.level))
+	}
+	args = append(args, path, ".")
+
+	var cmd = exec.Command(a.sevenZip, args...)
+	cmd.Dir = string(a.staging)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w\n%s", filepath.Base(a.sevenZip), err, output)
+	}
+
+	return nil
+}
+
+func (a *ArchiveOutput) WriteFile(name string, data []byte) error {
+	name, err := archiveName(name)
+	if err != nil {
+		return err
+	}
+
+	a.locker.Lock()
+	defer a.locker.Unlock()
+
+	if a.sevenZip != "" {
+		return a.staging.WriteFile(name, data)
+	}
+
+	if a.zip != nil && a.password != "" {
+		raw, extra, err := zipAesEncrypt(a.password, data, a.level)
+		if err != nil {
+			return err
+		}
+		w, err := a.zip.CreateRaw(&zip.FileHeader{
+			Name:               name,
+			Method:             zipMethodAes,
+			Flags:              0x1, // encrypted
+			Modified:           time.Now(),
+			Extra:              extra,
+			CompressedSize64:   uint64(len(raw)),
+			UncompressedSize64: uint64(len(data)),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(raw)
+		return err
+	}
+	if a.zip != nil {
+		w, err := a.zip.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	var header = &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()}
+	if err := a.tar.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = a.tar.Write(data)
+	return err
+}
+
+// archiveName cleans the name of the member to the relative slash path, the members with the absolute names
+// or '..' would be extracted out of the target folder.
+func archiveName(name string) (string, error) {
+	var clean = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if clean == "" {
+		return "", fmt.Errorf(T("invalid archive member name '%s'"), name)
+	}
+
+	return clean, nil
+}
+
+func (a *ArchiveOutput) String() string {
+	return a.path
+}
+
+// Close finishes the archive file.
+func (a *ArchiveOutput) Close() error {
+	a.locker.Lock()
+	defer a.locker.Unlock()
+
+	if a.sevenZip != "" {
+		return a.compress7z()
+	}
+
+	var errs []error
+	if a.zip != nil {
+		errs = append(errs, a.zip.Close())
+	}
+	if a.tar != nil {
+		errs = append(errs, a.tar.Close(), a.compressor.Close())
+	}
+	if a.zstd != nil {
+		errs = append(errs, a.zstd.Wait())
+	}
+	errs = append(errs, a.file.Close())
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
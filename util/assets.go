@@ -0,0 +1,121 @@
+package util
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var regCssUrl = regexp.MustCompile(`(url\(\s*)(["']?)([^"'()\s]+)(["']?\s*\))`)
+var regMarkupSrc = regexp.MustCompile(`(\b(?:src|href|poster)\s*=\s*)(["'])([^"']*)(["'])`)
+
+// AssetResolver resolves the asset references in the styles and the templates to the extracted files.
+type AssetResolver struct {
+	files  map[string]bool
+	byBase map[string][]string // the base name -> the slash paths
+}
+
+// NewAssetResolver returns the resolver of the extracted files, the slash paths relative to the unpacked folder.
+func NewAssetResolver(paths []string) *AssetResolver {
+	var r = &AssetResolver{files: make(map[string]bool), byBase: make(map[string][]string)}
+	for _, p := range paths {
+		p = strings.TrimPrefix(p, "/")
+		r.files[p] = true
+		r.byBase[path.Base(p)] = append(r.byBase[path.Base(p)], p)
+	}
+
+	return r
+}
+
+// Resolve returns the extracted file the reference in the file name points to, "" if it's not extracted. The
+// absolute references like '/images/a.png' are relative to the root of the mini program, and the remote ones
+// like 'https://cdn.example.com/app/images/a.png' match the file with the longest path suffix, like maplocal.
+func (r *AssetResolver) Resolve(name, ref string) string {
+	if strings.Contains(ref, "{{") || strings.HasPrefix(ref, "data:") || strings.HasPrefix(ref, "#") {
+		return "" // the dynamic references and the inline data
+	}
+	u, err := url.Parse(ref)
+	if err != nil || u.Path == "" {
+		return ""
+	}
+
+	if u.Host != "" {
+		var match = ""
+		for _, p := range r.byBase[path.Base(u.Path)] {
+			if strings.HasSuffix(u.Path, "/"+p) && len(p) > len(match) {
+				match = p
+			}
+		}
+		return match
+	}
+	if u.Scheme != "" {
+		return ""
+	}
+
+	var p = path.Join(path.Dir("/"+strings.TrimPrefix(name, "/")), u.Path)
+	if strings.HasPrefix(u.Path, "/") {
+		p = path.Clean(u.Path)
+	}
+	if p = strings.TrimPrefix(p, "/"); r.files[p] {
+		return p
+	}
+
+	return ""
+}
+
+// RewriteAssetRefs rewrites the url() references in the wxss or css file name, and the src and the href in the
+// wxml or html file, to the relative paths of the extracted files, so that the images are rendered when the
+// files are opened locally. It returns the rewritten data and the number of the rewritten references.
+func RewriteAssetRefs(name string, data []byte, r *AssetResolver) ([]byte, int) {
+	var reg *regexp.Regexp
+	switch strings.ToLower(path.Ext(name)) {
+	case ".wxss", ".css":
+		reg = regCssUrl
+	case ".wxml", ".html":
+		reg = regMarkupSrc
+	default:
+		return data, 0
+	}
+
+	var count = 0
+	var result = reg.ReplaceAllFunc(data, func(match []byte) []byte {
+		var groups = reg.FindSubmatch(match)
+		var ref = string(groups[3])
+		if !strings.HasPrefix(ref, "/") && !strings.Contains(ref, "://") {
+			return match // the relative references work already
+		}
+		var target = r.Resolve(name, ref)
+		if target == "" {
+			return match
+		}
+
+		var local = relativePath(path.Dir(strings.TrimPrefix(name, "/")), target)
+		if i := strings.IndexByte(ref, '#'); i >= 0 {
+			local += ref[i:] // like the symbols of the svg sprites
+		}
+		if local == ref {
+			return match
+		}
+		count++
+
+		return []byte(string(groups[1]) + string(groups[2]) + local + string(groups[4]))
+	})
+
+	return result, count
+}
+
+// relativePath returns the slash path of target relative to the folder dir, both are relative to the same root.
+func relativePath(dir, target string) string {
+	var from, to = strings.Split(path.Clean(dir), "/"), strings.Split(target, "/")
+	if dir == "." || dir == "" {
+		from = nil
+	}
+
+	var common = 0
+	for common < len(from) && common < len(to)-1 && from[common] == to[common] {
+		common++
+	}
+
+	return strings.Repeat("../", len(from)-common) + strings.Join(to[common:], "/")
+}
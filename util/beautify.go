@@ -2,6 +2,7 @@ package util
 
 import (
 	"bytes"
+	"encoding/json"
 	"github.com/ditashi/jsbeautifier-go/jsbeautifier"
 	"github.com/tidwall/pretty"
 	"github.com/yosssi/gohtml"
@@ -12,8 +13,31 @@ import (
 var regScriptInHtml = regexp.MustCompile(`(?s) *<script.*?>(.*?)</script>`)
 var jsOptions = jsbeautifier.DefaultOptions()
 
+// JsonOptions is the options of the json beautify, the numbers are kept as they are, so the large
+// integers are not rounded.
+var JsonOptions = &pretty.Options{Width: 80, Indent: "  "}
+
 func PrettyJson(data []byte) []byte {
-	return pretty.Pretty(data)
+	return pretty.PrettyOptions(data, JsonOptions)
+}
+
+// MarshalJson returns the json encoding of v, beautified with JsonOptions.
+func MarshalJson(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return PrettyJson(data), nil
+}
+
+// UnmarshalJson parses the json data like json.Unmarshal, but the numbers in interface{} are
+// decoded as json.Number instead of float64 to keep the large integers.
+func UnmarshalJson(data []byte, v interface{}) error {
+	var decoder = json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(v)
 }
 
 func PrettyHtml(data []byte) []byte {
@@ -43,6 +67,33 @@ func PrettyJavaScript(data []byte) []byte {
 	return []byte(beautify)
 }
 
+const (
+	prettyAverageLine = 80   // the average line length of the formatted code at most
+	prettyLongestLine = 1000 // the longest line of the formatted code at most
+)
+
+// IsPretty reports whether the code looks already formatted by the newline density, like the
+// development builds which are not minified.
+func IsPretty(data []byte) bool {
+	var lines = bytes.Count(data, []byte("\n")) + 1
+	if lines < 3 || len(data) > lines*prettyAverageLine {
+		return false
+	}
+
+	for len(data) > 0 {
+		var i = bytes.IndexByte(data, '\n')
+		if i < 0 {
+			i = len(data)
+		}
+		if i > prettyLongestLine {
+			return false
+		}
+		data = data[min(i+1, len(data)):]
+	}
+
+	return true
+}
+
 func countLeadingSpaces(data []byte) int {
 	var result = 0
 	for _, c := range data {
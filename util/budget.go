@@ -0,0 +1,84 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MemoryBudget limits the memory used by the concurrent tasks.
+type MemoryBudget struct {
+	limit int64 // no limit if <= 0
+	used  int64
+	cond  *sync.Cond
+}
+
+func NewMemoryBudget(limit int64) *MemoryBudget {
+	return &MemoryBudget{limit: limit, cond: sync.NewCond(&sync.Mutex{})}
+}
+
+// Acquire blocks until n bytes are available, n larger than the limit is clipped to the limit.
+func (b *MemoryBudget) Acquire(n int64) {
+	if b.limit <= 0 {
+		return
+	}
+	if n > b.limit {
+		n = b.limit
+	}
+
+	b.cond.L.Lock()
+	for b.used+n > b.limit {
+		b.cond.Wait()
+	}
+	b.used += n
+	b.cond.L.Unlock()
+}
+
+// Release releases the n bytes acquired.
+func (b *MemoryBudget) Release(n int64) {
+	if b.limit <= 0 {
+		return
+	}
+	if n > b.limit {
+		n = b.limit
+	}
+
+	b.cond.L.Lock()
+	b.used -= n
+	b.cond.L.Unlock()
+	b.cond.Broadcast()
+}
+
+var sizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"GB", 1 << 30}, {"G", 1 << 30},
+	{"MB", 1 << 20}, {"M", 1 << 20},
+	{"KB", 1 << 10}, {"K", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize parses the size like '512MB', '1G' or '1024', "" means 0.
+func ParseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return 0, nil
+	}
+
+	var unit int64 = 1
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			s, unit = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf(T("invalid size '%s'"), s)
+	}
+
+	return int64(n * float64(unit)), nil
+}
@@ -0,0 +1,155 @@
+package util
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var regDefine = regexp.MustCompile(`define\(\s*["']([^"']+)["']\s*,\s*function\s*\([^)]*\)\s*\{`)
+var regRequire = regexp.MustCompile(`\brequire\(\s*["']([^"']+)["']\s*\)`)
+
+// Module is a module defined by `define("name", function(require, module, exports, ...) {...})`
+// in the bundled 'app-service.js'.
+type Module struct {
+	Name string
+	Code []byte // the function body
+	Line int    // the line of the define in the bundled file, starts from 1
+}
+
+// SplitModules splits the bundled js code into modules.
+func SplitModules(data []byte) []Module {
+	var result []Module
+	var end = 0
+	for _, loc := range regDefine.FindAllSubmatchIndex(data, -1) {
+		if loc[0] < end { // nested in the previous module
+			continue
+		}
+
+		var open = loc[1] - 1
+		var close = matchBrace(data, open)
+		if close < 0 {
+			continue
+		}
+		end = close
+
+		result = append(result, Module{
+			Name: string(data[loc[2]:loc[3]]),
+			Code: data[open+1 : close],
+			Line: bytes.Count(data[:loc[0]], []byte("\n")) + 1,
+		})
+	}
+
+	return result
+}
+
+// Requires returns the modules required by the module, resolved to the module names.
+func (m Module) Requires() []string {
+	var result []string
+	var seen = make(map[string]bool)
+	for _, match := range regRequire.FindAllSubmatch(m.Code, -1) {
+		var name = ResolveModule(m.Name, string(match[1]))
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
+
+// ResolveModule resolves the required path relative to the module `from`.
+func ResolveModule(from, required string) string {
+	var name = required
+	if strings.HasPrefix(required, ".") {
+		name = path.Join(path.Dir(from), required)
+	}
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+
+	if path.Ext(name) != ".js" {
+		name += ".js"
+	}
+
+	return name
+}
+
+// matchBrace returns the index of the '}' matching the '{' at open, skipping strings and comments.
+func matchBrace(data []byte, open int) int {
+	var depth = 0
+	for i := open; i < len(data); i++ {
+		switch c := data[i]; c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case '"', '\'', '`':
+			for i++; i < len(data) && data[i] != c; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+		case '/':
+			if i+1 >= len(data) {
+				continue
+			}
+			if data[i+1] == '/' {
+				for i < len(data) && data[i] != '\n' {
+					i++
+				}
+			} else if data[i+1] == '*' {
+				var end = bytes.Index(data[i+2:], []byte("*/"))
+				if end < 0 {
+					return -1
+				}
+				i += end + 3
+			}
+		}
+	}
+
+	return -1
+}
+
+// LoadModules loads the modules from all the js files in the unpacked folder dir,
+// a js file without `define` is a module itself.
+func LoadModules(dir string) ([]Module, error) {
+	paths, err := GetDirAllFilePaths(dir, "", ".js")
+	if err != nil {
+		return nil, err
+	}
+
+	var result, files []Module
+	var defined = make(map[string]bool)
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+
+		var modules = SplitModules(data)
+		if len(modules) == 0 {
+			rel, _ := filepath.Rel(dir, p)
+			files = append(files, Module{Name: filepath.ToSlash(rel), Code: data})
+			continue
+		}
+		for _, m := range modules {
+			if !defined[m.Name] {
+				defined[m.Name] = true
+				result = append(result, m)
+			}
+		}
+	}
+
+	for _, m := range files {
+		if !defined[m.Name] {
+			result = append(result, m)
+		}
+	}
+
+	return result, nil
+}
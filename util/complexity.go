@@ -0,0 +1,53 @@
+package util
+
+import "strings"
+
+// the keywords of the branches counted by the cyclomatic complexity
+var branchKeywords = map[string]bool{"if": true, "for": true, "while": true, "case": true, "catch": true}
+
+// the keywords of the statements without ';' like 'if (...) {}'
+var statementKeywords = map[string]bool{"if": true, "for": true, "while": true, "do": true, "switch": true, "try": true}
+
+// CodeComplexity is the rough complexity of the js code.
+type CodeComplexity struct {
+	Cyclomatic int `json:"cyclomatic"` // 1 + the branches, like 'if', 'case', '&&' and '?:'
+	Statements int `json:"statements"`
+	Functions  int `json:"functions"`
+}
+
+// Complexity estimates the complexity of the js code by the tokens, the strings and the comments are skipped.
+// It's not a parser, the result is only to compare the modules.
+func Complexity(code []byte) CodeComplexity {
+	var result = CodeComplexity{Cyclomatic: 1}
+	for _, t := range scriptTokens(string(code), true) {
+		switch t.class {
+		case tokenKeyword:
+			if branchKeywords[t.text] {
+				result.Cyclomatic++
+			}
+			if statementKeywords[t.text] {
+				result.Statements++
+			}
+			if t.text == "function" {
+				result.Functions++
+			}
+		case tokenPlain:
+			var text = t.text
+			result.Cyclomatic += strings.Count(text, "&&") + strings.Count(text, "||") + strings.Count(text, "??")
+			result.Functions += strings.Count(text, "=>")
+			result.Statements += strings.Count(text, ";")
+			// the ternaries, not the optional chaining '?.' or the nullish coalescing '??'
+			for i := 0; i < len(text); i++ {
+				if text[i] != '?' {
+					continue
+				}
+				if i+1 < len(text) && (text[i+1] == '.' || text[i+1] == '?') || i > 0 && text[i-1] == '?' {
+					continue
+				}
+				result.Cyclomatic++
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,160 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CurlBaseUrl is the shell variable of the host of the requests without the fixed host.
+const CurlBaseUrl = "BASE_URL"
+
+// curlMarker marks the variables in the body built by dataBody, the templates like '{id}' can't be told from
+// the empty objects. It's a noncharacter kept as is by the json encoder.
+const curlMarker = "\ufffe"
+
+// CurlScript is the shell script of the curl commands of the requests found in the code, the dynamic parts
+// like the host and the tokens are the shell variables which can be set in the environment. See FindRequests.
+type CurlScript struct {
+	baseUrl  string
+	vars     []string
+	defaults map[string]string // variable -> the default value
+	commands []string
+}
+
+// NewCurlScript returns the empty script, baseUrl is the default value of the host variable.
+func NewCurlScript(baseUrl string) *CurlScript {
+	var s = &CurlScript{defaults: make(map[string]string)}
+	s.addVar(CurlBaseUrl, strings.TrimSuffix(baseUrl, "/"))
+
+	return s
+}
+
+// AddRequest adds the curl command of the request call, source is the location of the call.
+func (s *CurlScript) AddRequest(call RequestCall, source string) {
+	server, path, query := SplitRequestUrl(call.Url)
+	if server == "" {
+		server = "{" + CurlBaseUrl + "}"
+	}
+	if strings.HasPrefix(server, "{") {
+		// the templates of the base urls default to the host variable
+		for _, name := range TemplateParams(server) {
+			s.addVar(shellVar(name), "$"+CurlBaseUrl)
+		}
+	}
+
+	var method = strings.ToUpper(call.Method)
+	var u = server + path
+	if query != "" {
+		u += "?" + query
+	}
+	if len(call.Data) > 0 && (method == "GET" || method == "DELETE" || method == "HEAD") {
+		if query == "" {
+			u += "?"
+		} else {
+			u += "&"
+		}
+		u += s.formData(call.Data, "&")
+	}
+
+	var args = []string{"curl -X " + method + " " + s.quote(markTemplates(u))}
+	var hasContentType = false
+	for _, name := range sortedStringKeys(call.Header) {
+		hasContentType = hasContentType || strings.EqualFold(name, "content-type")
+		args = append(args, "-H "+s.quote(markTemplates(name+": "+call.Header[name])))
+	}
+	if len(call.Data) > 0 && method != "GET" && method != "DELETE" && method != "HEAD" {
+		var contentType = call.contentType()
+		switch {
+		case strings.Contains(contentType, "multipart"):
+			for _, name := range sortedStringKeys(call.Data) {
+				args = append(args, "-F "+s.quote(name+"="+s.placeholder(name, call.Data[name])))
+			}
+		case strings.Contains(contentType, "json"):
+			if !hasContentType {
+				args = append(args, "-H "+s.quote("content-type: "+contentType))
+			}
+			args = append(args, "--data "+s.quote(dataBody(call.Data, contentType, curlMarker+"%s"+curlMarker)))
+		default:
+			args = append(args, "--data "+s.quote(s.formData(call.Data, "&")))
+		}
+	}
+
+	s.commands = append(s.commands, fmt.Sprintf("# %s\n%s\n", source, strings.Join(args, " \\\n  ")))
+}
+
+// Script returns the content of the script.
+func (s *CurlScript) Script() []byte {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("# the curl commands of the requests found in the code statically, set the variables to run them\n\n")
+
+	var vars = append([]string(nil), s.vars[1:]...)
+	sort.Strings(vars)
+	for _, name := range append([]string{CurlBaseUrl}, vars...) {
+		var value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`").Replace(s.defaults[name])
+		b.WriteString(fmt.Sprintf("%s=\"${%s:-%s}\"\n", name, name, value))
+	}
+	for _, command := range s.commands {
+		b.WriteString("\n" + command)
+	}
+
+	return []byte(b.String())
+}
+
+// Len returns the count of the curl commands.
+func (s *CurlScript) Len() int {
+	return len(s.commands)
+}
+
+// formData returns the data as 'name=value' joined by sep, the values of the unknown types are marked.
+func (s *CurlScript) formData(data map[string]string, sep string) string {
+	var params []string
+	for _, name := range sortedStringKeys(data) {
+		params = append(params, name+"="+s.placeholder(name, data[name]))
+	}
+
+	return strings.Join(params, sep)
+}
+
+func (s *CurlScript) placeholder(name, t string) string {
+	return dataPlaceholder(name, t, curlMarker+"%s"+curlMarker)
+}
+
+// quote quotes the text in the double quotes, the marked variables are expanded.
+func (s *CurlScript) quote(text string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i, part := range strings.Split(text, curlMarker) {
+		if i%2 == 1 {
+			var name = shellVar(part)
+			s.addVar(name, "")
+			b.WriteString("${" + name + "}")
+			continue
+		}
+		b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", "$", `\$`).Replace(part))
+	}
+	b.WriteByte('"')
+
+	return b.String()
+}
+
+func (s *CurlScript) addVar(name, value string) {
+	if _, ok := s.defaults[name]; ok {
+		return
+	}
+	s.defaults[name] = value
+	s.vars = append(s.vars, name)
+}
+
+// markTemplates marks the templates like '{id}' in the text as the variables.
+func markTemplates(text string) string {
+	return regTemplateParam.ReplaceAllStringFunc(text, func(match string) string {
+		return curlMarker + match[1:len(match)-1] + curlMarker
+	})
+}
+
+// shellVar converts the name of the template to the shell variable name.
+func shellVar(name string) string {
+	return strings.ReplaceAll(name, "$", "_")
+}
@@ -0,0 +1,43 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// RunDecryptor decrypts the wxapkg file with the external command, which reads the encrypted data from
+// stdin and writes the decrypted data to stdout. The command runs in the shell, '{wxid}' in it is replaced
+// with the wxid, which is also passed in the environment variable 'WXAPKG_WXID'.
+func RunDecryptor(command, wxid string, data []byte) ([]byte, error) {
+	command = strings.ReplaceAll(command, "{wxid}", wxid)
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), "WXAPKG_WXID="+wxid)
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+
+	if stdout.Len() == 0 || stdout.Bytes()[0] != 0xBE {
+		return nil, errors.New(T("the output of the decryptor is not a decrypted wxapkg file"))
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,249 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// LinkVersions replaces the files identical to the same files in the previous version of the mini program
+// with the symlinks to them, for all the unpacked folders in root. The versions are ordered by the time in
+// the manifests, and the older versions must be kept, or the links are broken. It returns the number of the
+// linked files and the bytes saved.
+func LinkVersions(root string) (int, int64, error) {
+	dirs, err := FindUnpackedDirs(root)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	type version struct {
+		dir      string
+		manifest *Manifest
+	}
+	var apps = make(map[string][]version)
+	for _, dir := range dirs {
+		manifest, err := ReadManifest(dir)
+		if err != nil {
+			return 0, 0, err
+		}
+		apps[manifest.Wxid] = append(apps[manifest.Wxid], version{dir: dir, manifest: manifest})
+	}
+
+	var linked = 0
+	var saved int64
+	for _, versions := range apps {
+		sort.SliceStable(versions, func(i, j int) bool {
+			return versions[i].manifest.Time.Before(versions[j].manifest.Time)
+		})
+
+		for i := 1; i < len(versions); i++ {
+			var prev, cur = versions[i-1], versions[i]
+			var checksums = make(map[string]string, len(prev.manifest.Files))
+			for _, f := range prev.manifest.Files {
+				checksums[f.Path] = f.Sha256
+			}
+
+			for _, f := range cur.manifest.Files {
+				if f.Sha256 == "" || checksums[f.Path] != f.Sha256 {
+					continue
+				}
+
+				var name = filepath.Join(cur.dir, filepath.FromSlash(f.Path))
+				size, err := linkFile(name, filepath.Join(prev.dir, filepath.FromSlash(f.Path)))
+				if err != nil {
+					return linked, saved, err
+				}
+				if size > 0 {
+					linked++
+					saved += size
+				}
+			}
+		}
+	}
+
+	return linked, saved, nil
+}
+
+// linkFile replaces the regular file name with the relative symlink to the file target points to,
+// it returns the size of the replaced file, 0 if it's not replaced.
+func linkFile(name, target string) (int64, error) {
+	info, err := os.Lstat(name)
+	if err != nil || !info.Mode().IsRegular() {
+		return 0, nil
+	}
+
+	// link to the real file instead of a chain of links
+	real, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		return 0, nil
+	}
+	if same, err := os.Stat(real); err != nil || os.SameFile(info, same) {
+		return 0, nil
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(name))
+	if err != nil {
+		return 0, err
+	}
+	real, err = filepath.Abs(real)
+	if err != nil {
+		return 0, err
+	}
+	rel, err := filepath.Rel(dir, real)
+	if err != nil {
+		return 0, nil
+	}
+
+	var tmp = name + ".link~"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(rel, tmp); err != nil {
+		return 0, nil // not permitted, e.g. on windows without the developer mode
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		_ = os.Remove(tmp)
+		return 0, err
+	}
+
+	return info.Size(), nil
+}
+
+// LinkDuplicates replaces the identical files with the hard links to the first one by the path, the files
+// are path -> sha256. It returns the number of the linked files and the bytes saved.
+func LinkDuplicates(files map[string]string) (int, int64, error) {
+	var paths = make([]string, 0, len(files))
+	for name := range files {
+		paths = append(paths, name)
+	}
+	sort.Strings(paths)
+
+	var linked = 0
+	var saved int64
+	var firsts = make(map[string]string) // sha256 -> the first file
+	for _, name := range paths {
+		var sum = files[name]
+		if sum == "" {
+			continue
+		}
+
+		info, err := os.Lstat(name)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		first, ok := firsts[sum]
+		if !ok {
+			firsts[sum] = name
+			continue
+		}
+		firstInfo, err := os.Stat(first)
+		if err != nil || firstInfo.Size() != info.Size() || os.SameFile(info, firstInfo) {
+			continue
+		}
+
+		var tmp = name + ".link~"
+		_ = os.Remove(tmp)
+		if err := os.Link(first, tmp); err != nil {
+			continue // not supported, e.g. on FAT32 or across the devices
+		}
+		if err := os.Rename(tmp, name); err != nil {
+			_ = os.Remove(tmp)
+			return linked, saved, err
+		}
+		linked++
+		saved += info.Size()
+	}
+
+	return linked, saved, nil
+}
+
+// StoreContent moves the files into the content-addressable store, each body is stored once as
+// '<store>/<sha256[:2]>/<sha256>' and the files are replaced with the hard links to it, or the symlinks
+// if the store is on another device. The stored objects are read-only, so that editing a linked file fails
+// instead of changing the body of every version sharing it, the files must be copied before editing. The
+// files are path -> sha256. It returns the number of the new bodies stored, the number of the files linked
+// to the stored bodies and the bytes saved.
+func StoreContent(store string, files map[string]string) (int, int, int64, error) {
+	var paths = make([]string, 0, len(files))
+	for name := range files {
+		paths = append(paths, name)
+	}
+	sort.Strings(paths)
+
+	var stored, linked = 0, 0
+	var saved int64
+	for _, name := range paths {
+		var sum = files[name]
+		if len(sum) < 3 {
+			continue
+		}
+
+		info, err := os.Lstat(name)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		var object = filepath.Join(store, sum[:2], sum)
+		if _, err := os.Stat(object); os.IsNotExist(err) {
+			if err := storeObject(name, object); err != nil {
+				return stored, linked, saved, err
+			}
+			stored++
+		} else if err != nil {
+			return stored, linked, saved, err
+		}
+		// the object may be made writable to replace a linked file, see DirOutput.WriteFile
+		if err := os.Chmod(object, storeObjectMode); err != nil {
+			return stored, linked, saved, err
+		}
+
+		objectInfo, err := os.Stat(object)
+		if err != nil {
+			return stored, linked, saved, err
+		}
+		if os.SameFile(info, objectInfo) {
+			continue
+		}
+
+		var tmp = name + ".link~"
+		_ = os.Remove(tmp)
+		if err := os.Link(object, tmp); err == nil {
+			if err := os.Rename(tmp, name); err != nil {
+				_ = os.Remove(tmp)
+				return stored, linked, saved, err
+			}
+		} else if size, err := linkFile(name, object); err != nil {
+			return stored, linked, saved, err
+		} else if size == 0 {
+			continue
+		}
+		linked++
+		saved += info.Size()
+	}
+
+	return stored, linked, saved, nil
+}
+
+// storeObjectMode is the mode of the objects in the store, read-only.
+const storeObjectMode = 0444
+
+// storeObject saves the body of the file name as the object in the store, it's hard linked if possible.
+func storeObject(name, object string) error {
+	if err := os.MkdirAll(filepath.Dir(object), 0755); err != nil {
+		return err
+	}
+	if err := os.Link(name, object); err == nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	var tmp = object + ".tmp~"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, object)
+}
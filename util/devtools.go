@@ -0,0 +1,52 @@
+package util
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// the default install locations of the wechat devtools cli
+var devtoolsCliPaths = map[string][]string{
+	"windows": {
+		`C:\Program Files (x86)\Tencent\微信web开发者工具\cli.bat`,
+		`C:\Program Files\Tencent\微信web开发者工具\cli.bat`,
+	},
+	"darwin": {
+		"/Applications/wechatwebdevtools.app/Contents/MacOS/cli",
+	},
+}
+
+// FindDevtoolsCli returns the path of the wechat devtools cli.
+func FindDevtoolsCli() (string, error) {
+	for _, p := range devtoolsCliPaths[runtime.GOOS] {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+
+	return "", errors.New(T("wechat devtools cli not found, use '--devtools-cli' to specify it"))
+}
+
+// OpenInDevtools opens the project in the wechat devtools, the cli is detected if empty.
+func OpenInDevtools(cli, project string) error {
+	if cli == "" {
+		var err error
+		if cli, err = FindDevtoolsCli(); err != nil {
+			return err
+		}
+	}
+
+	project, err := filepath.Abs(project)
+	if err != nil {
+		return err
+	}
+
+	var cmd = exec.Command(cli, "open", "--project", project)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
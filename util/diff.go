@@ -0,0 +1,181 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// UnifiedDiff returns the unified diff of the two texts with 3 lines of context, "" if they are the same.
+func UnifiedDiff(nameA, nameB string, a, b []byte) string {
+	var ops = diffLines(splitLines(a), splitLines(b))
+
+	const context = 3
+	var out strings.Builder
+	var lineA, lineB = 1, 1 // the line numbers of ops[i]
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			lineA++
+			lineB++
+			i++
+			continue
+		}
+
+		// the hunk starts at `context` lines before the change
+		var start = i
+		for start > 0 && i-start < context && ops[start-1].kind == ' ' {
+			start--
+		}
+		var startA, startB = lineA - (i - start), lineB - (i - start)
+
+		// and ends when there are more than 2*context unchanged lines
+		var end = i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			var same = end
+			for same < len(ops) && ops[same].kind == ' ' {
+				same++
+			}
+			if same == len(ops) || same-end > 2*context {
+				end += min(context, same-end)
+				break
+			}
+			end = same
+		}
+
+		var countA, countB = 0, 0
+		var hunk strings.Builder
+		for _, op := range ops[start:end] {
+			hunk.WriteByte(op.kind)
+			hunk.WriteString(op.line)
+			hunk.WriteByte('\n')
+			if op.kind != '+' {
+				countA++
+			}
+			if op.kind != '-' {
+				countB++
+			}
+		}
+
+		if out.Len() == 0 {
+			out.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", nameA, nameB))
+		}
+		out.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", startA, countA, startB, countB))
+		out.WriteString(hunk.String())
+
+		for _, op := range ops[i:end] {
+			if op.kind != '+' {
+				lineA++
+			}
+			if op.kind != '-' {
+				lineB++
+			}
+		}
+		i = end
+	}
+
+	return out.String()
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// diffLines finds the shortest edit script with the Myers algorithm.
+func diffLines(a, b []string) []diffOp {
+	var n, m = len(a), len(b)
+	var max = n + m
+	var offset = max + 1
+	var v = make([]int, 2*max+2)
+	var trace [][]int
+
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int{}, v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			var y = x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, offset)
+			}
+		}
+	}
+
+	return nil
+}
+
+func backtrack(trace [][]int, a, b []string, offset int) []diffOp {
+	var ops []diffOp
+	var x, y = len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		var v = trace[d]
+		var k = x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		var prevX = v[offset+prevK]
+		var prevY = prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{' ', a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				ops = append(ops, diffOp{'+', b[y]})
+			} else {
+				x--
+				ops = append(ops, diffOp{'-', a[x]})
+			}
+		}
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+
+	return ops
+}
+
+// IsText reports whether the data looks like text, without NUL in the first 8000 bytes like git.
+func IsText(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+
+	return bytes.IndexByte(data, 0) < 0
+}
@@ -0,0 +1,11 @@
+//go:build !windows && !darwin && !dragonfly && !freebsd && !linux
+
+package util
+
+import "errors"
+
+// DiskFree returns the free bytes of the disk of the path available to the user, it's not supported on this
+// platform.
+func DiskFree(path string) (uint64, error) {
+	return 0, errors.New(T("the free disk space is not supported on this platform"))
+}
@@ -0,0 +1,15 @@
+//go:build darwin || dragonfly || freebsd || linux
+
+package util
+
+import "syscall"
+
+// DiskFree returns the free bytes of the disk of the path available to the user.
+func DiskFree(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
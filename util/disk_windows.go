@@ -0,0 +1,18 @@
+package util
+
+import "golang.org/x/sys/windows"
+
+// DiskFree returns the free bytes of the disk of the path available to the user.
+func DiskFree(path string) (uint64, error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var free uint64
+	if err := windows.GetDiskFreeSpaceEx(p, &free, nil, nil); err != nil {
+		return 0, err
+	}
+
+	return free, nil
+}
@@ -0,0 +1,84 @@
+package util
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// the kinds of the domains in the mini program console, the requests and the sockets are configured separately
+const (
+	DomainRequest = "request"
+	DomainSocket  = "socket"
+)
+
+// DomainAllowlist is the list of the approved domains, like the server domains configured in the mini program
+// console. The entries are the hosts or the urls, like 'api.example.com' or 'wss://ws.example.com', the
+// wildcards like '*.example.com' match the subdomains.
+type DomainAllowlist struct {
+	hosts     map[string]bool
+	wildcards []string // the suffixes like '.example.com'
+}
+
+// ReadDomainAllowlist reads the allowlist file, one entry per line, the empty lines and the lines starting
+// with '#' are ignored.
+func ReadDomainAllowlist(path string) (*DomainAllowlist, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var list = &DomainAllowlist{hosts: make(map[string]bool)}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var host = line
+		if strings.Contains(line, "://") {
+			u, err := url.Parse(line)
+			if err != nil || u.Host == "" {
+				return nil, fmt.Errorf(T("invalid domain '%s' at line %d of '%s'"), line, i+1, path)
+			}
+			host = u.Host
+		}
+		host = strings.ToLower(strings.TrimSuffix(host, "/"))
+		if strings.HasPrefix(host, "*.") {
+			list.wildcards = append(list.wildcards, host[1:])
+		} else {
+			list.hosts[host] = true
+		}
+	}
+
+	return list, nil
+}
+
+// Allowed checks if the host, with the port or not, is in the list.
+func (l *DomainAllowlist) Allowed(host string) bool {
+	host = strings.ToLower(host)
+	if l.hosts[host] {
+		return true
+	}
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && l.hosts[host[:i]] {
+		return true
+	}
+	for _, suffix := range l.wildcards {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DomainKind returns the kind of the domain by the scheme of the url, DomainSocket for 'ws' and 'wss'.
+func DomainKind(scheme string) string {
+	switch strings.ToLower(scheme) {
+	case "ws", "wss":
+		return DomainSocket
+	default:
+		return DomainRequest
+	}
+}
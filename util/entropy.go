@@ -0,0 +1,63 @@
+package util
+
+import "math"
+
+// Entropy returns the shannon entropy of the data in bits per byte, from 0 to 8. The text is about 4 to 5,
+// the base64 of the random data is about 6, and the compressed or encrypted data is close to 8.
+func Entropy(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+
+	var counts [256]int
+	for _, b := range data {
+		counts[b]++
+	}
+
+	var entropy float64
+	var total = float64(len(data))
+	for _, c := range counts {
+		if c > 0 {
+			var p = float64(c) / total
+			entropy -= p * math.Log2(p)
+		}
+	}
+
+	return entropy
+}
+
+// EntropyRegion is a region of the data with the high entropy.
+type EntropyRegion struct {
+	Start   int     `json:"start"`
+	End     int     `json:"end"`
+	Entropy float64 `json:"entropy"` // the highest entropy of the windows in the region
+}
+
+// HighEntropyRegions slides the window over the data by half of the window, and merges the overlapping
+// windows with the entropy higher than threshold into the regions.
+func HighEntropyRegions(data []byte, window int, threshold float64) []EntropyRegion {
+	if window <= 0 || len(data) < window {
+		return nil
+	}
+
+	var result []EntropyRegion
+	var step = window / 2
+	if step == 0 {
+		step = 1
+	}
+	for start := 0; start+window <= len(data); start += step {
+		var entropy = Entropy(data[start : start+window])
+		if entropy <= threshold {
+			continue
+		}
+
+		if n := len(result); n > 0 && result[n-1].End >= start {
+			result[n-1].End = start + window
+			result[n-1].Entropy = math.Max(result[n-1].Entropy, entropy)
+			continue
+		}
+		result = append(result, EntropyRegion{Start: start, End: start + window, Entropy: entropy})
+	}
+
+	return result
+}
@@ -0,0 +1,106 @@
+package util
+
+import "errors"
+
+// The error codes in the JSON output, they are stable so that the automation can triage the failures
+// without matching the messages.
+const (
+	ErrCodeFailure       = "ERR_FAILURE"
+	ErrCodeNoPackage     = "ERR_NO_PACKAGE"
+	ErrCodeDecrypt       = "ERR_DECRYPT"
+	ErrCodeBadHeader     = "ERR_BAD_HEADER"
+	ErrCodeBadIndex      = "ERR_BAD_INDEX"
+	ErrCodeRead          = "ERR_READ"
+	ErrCodeWrite         = "ERR_WRITE"
+	ErrCodePanic         = "ERR_PANIC" // a bug while processing the file, the other files are not affected
+	ErrCodeIO            = "ERR_IO"
+	ErrCodePartial       = "ERR_PARTIAL"
+	ErrCodeVerify        = "ERR_VERIFY"
+	ErrCodePackageLocked = "ERR_PACKAGE_LOCKED" // held by the running wechat
+	ErrCodeOutputLocked  = "ERR_OUTPUT_LOCKED"  // being written by another process
+)
+
+// the codes of the errors with only the exit codes
+var exitErrCodes = map[int]string{
+	ExitNoPackage:      ErrCodeNoPackage,
+	ExitDecrypt:        ErrCodeDecrypt,
+	ExitInvalidPackage: ErrCodeBadHeader,
+	ExitPartial:        ErrCodePartial,
+	ExitIO:             ErrCodeIO,
+	ExitVerify:         ErrCodeVerify,
+	ExitLocked:         ErrCodeOutputLocked,
+}
+
+// FileError is the error of the wxapkg file or the file in it, with the error code.
+type FileError struct {
+	Code  string // the code of Err by default, see ErrorCode
+	File  string // the wxapkg file
+	Entry string // the file in the wxapkg file
+	Err   error
+}
+
+func (e *FileError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// WithFile attaches the wxapkg file to the error, nil is returned if err is nil.
+func WithFile(err error, file string) error {
+	if err == nil {
+		return nil
+	}
+
+	var e *FileError
+	if errors.As(err, &e) && e.File == "" {
+		e.File = file
+		return err
+	}
+
+	return &FileError{File: file, Err: err}
+}
+
+// ErrorCode returns the code of the error, which is attached by FileError or derived from the exit code.
+func ErrorCode(err error) string {
+	var e *FileError
+	for errors.As(err, &e) {
+		if e.Code != "" {
+			return e.Code
+		}
+		err = e.Err
+	}
+
+	if code, ok := exitErrCodes[ExitCode(err)]; ok {
+		return code
+	}
+
+	return ErrCodeFailure
+}
+
+// ErrorRecord is the error in the JSON output.
+type ErrorRecord struct {
+	Type    string `json:"type"` // always "error"
+	Code    string `json:"code"`
+	File    string `json:"file,omitempty"`
+	Entry   string `json:"entry,omitempty"`
+	Message string `json:"message"`
+}
+
+func NewErrorRecord(err error) ErrorRecord {
+	var record = ErrorRecord{Type: "error", Code: ErrorCode(err), Message: err.Error()}
+
+	var e *FileError
+	for errors.As(err, &e) {
+		if record.File == "" {
+			record.File = e.File
+		}
+		if record.Entry == "" {
+			record.Entry = e.Entry
+		}
+		err = e.Err
+	}
+
+	return record
+}
@@ -0,0 +1,52 @@
+package util
+
+import "errors"
+
+// The exit codes of the process, so that scripts can branch on the outcomes.
+const (
+	ExitOK             = 0
+	ExitFailure        = 1 // the other errors, like invalid arguments
+	ExitNoPackage      = 2 // no wxapkg file found
+	ExitDecrypt        = 3 // failed to decrypt the wxapkg file
+	ExitInvalidPackage = 4 // not a valid wxapkg file
+	ExitPartial        = 5 // some files failed to extract
+	ExitIO             = 6 // failed to read or write
+	ExitVerify         = 7 // the unpacked files are modified or missing
+	ExitLocked         = 8 // the output path is being written by another process
+)
+
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// WithExitCode attaches the exit code to the error, nil is returned if err is nil.
+func WithExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &ExitError{Code: code, Err: err}
+}
+
+// ExitCode returns the exit code attached to the error, ExitFailure by default.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var e *ExitError
+	if errors.As(err, &e) {
+		return e.Code
+	}
+
+	return ExitFailure
+}
@@ -42,3 +42,36 @@ func GetDirAllFilePaths(dirname string, prefix string, suffix string) ([]string,
 	}
 	return paths, nil
 }
+
+// FindUnpackedDirs finds the unpacked folders (with a manifest) in root, including root itself.
+func FindUnpackedDirs(root string) ([]string, error) {
+	var result []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if _, err := os.Stat(filepath.Join(path, ManifestName)); err == nil {
+			result = append(result, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// WriteFileIfNotExist writes data to the file only if it does not exist, reports whether it is written.
+func WriteFileIfNotExist(path string, data []byte) (bool, error) {
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return false, err
+	}
+
+	return true, os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+
+package util
+
+import "os"
+
+// ReadSharedFile reads the file like os.ReadFile, the files are never locked on this platform.
+func ReadSharedFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// OpenSharedFile opens the file for reading like os.Open.
+func OpenSharedFile(path string) (*os.File, error) {
+	return os.Open(path)
+}
+
+// IsLockedError reports whether the error is caused by a file locked by another process.
+func IsLockedError(err error) bool {
+	return false
+}
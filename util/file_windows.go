@@ -0,0 +1,52 @@
+package util
+
+import (
+	"errors"
+	"io"
+	"os"
+	"syscall"
+)
+
+const (
+	errorSharingViolation syscall.Errno = 32
+	errorLockViolation    syscall.Errno = 33
+)
+
+// ReadSharedFile reads the file like os.ReadFile, but also shares delete access,
+// so that the files opened by the running wechat can be read.
+func ReadSharedFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil || !IsLockedError(err) {
+		return data, err
+	}
+
+	f, err := OpenSharedFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
+
+// OpenSharedFile opens the file for reading like os.Open, but also shares delete access.
+func OpenSharedFile(path string) (*os.File, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+
+	handle, err := syscall.CreateFile(name, syscall.GENERIC_READ,
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil, syscall.OPEN_EXISTING, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+
+	return os.NewFile(uintptr(handle), path), nil
+}
+
+// IsLockedError reports whether the error is caused by a file locked by another process.
+func IsLockedError(err error) bool {
+	return errors.Is(err, errorSharingViolation) || errors.Is(err, errorLockViolation)
+}
@@ -0,0 +1,184 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// the severities of the findings
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+// the categories of the findings
+const (
+	CategoryDynamicCode = "dynamic-code"
+	CategoryCredential  = "credential"
+	CategoryEnvironment = "environment"
+)
+
+// the maximum length of the line shown in the findings
+const findingTextLength = 160
+
+// Finding is a problem found in the unpacked file, with the location.
+type Finding struct {
+	Rule     string `json:"rule"`
+	Category string `json:"category"`
+	Severity string `json:"severity"`
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Text     string `json:"text"` // the line around the match
+}
+
+type findingRule struct {
+	id       string
+	severity string
+	reg      *regexp.Regexp
+}
+
+var dynamicCodeRules = []findingRule{
+	{"eval", SeverityHigh, regexp.MustCompile(`\beval\s*\(`)},
+	{"new-function", SeverityHigh, regexp.MustCompile(`\bnew\s+Function\s*\(|(?:^|[^\w$.])Function\s*\(\s*["'` + "`" + `]`)},
+	{"string-timer", SeverityMedium, regexp.MustCompile(`\bset(?:Timeout|Interval)\s*\(\s*["'` + "`" + `]`)},
+	{"webassembly", SeverityMedium, regexp.MustCompile(`\b(?:WX)?WebAssembly\.(?:instantiate|instantiateStreaming|compile|compileStreaming|Module|Instance)\b`)},
+	{"js-interpreter", SeverityMedium, regexp.MustCompile(`\bnew\s+(?:[\w$]+\.)?Interpreter\s*\(|\beval5\b`)},
+	{"remote-script", SeverityHigh, regexp.MustCompile(`createElement\(\s*["']script["']\s*\)|\bimportScripts\s*\(|` +
+		`\brequire\s*\(\s*["'](?:https?:)?//|<script\b[^>]*\bsrc\s*=\s*["']?(?:https?:)?//`)},
+}
+
+var credentialRules = []findingRule{
+	{"aliyun-access-key", SeverityHigh, regexp.MustCompile(`\bLTAI[0-9A-Za-z]{12,20}\b`)},
+	{"tencent-secret-id", SeverityHigh, regexp.MustCompile(`\bAKID[0-9A-Za-z]{13,40}\b`)},
+	{"aws-access-key", SeverityHigh, regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"private-key", SeverityHigh, regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH )?PRIVATE KEY-----`)},
+}
+
+// the keys of the credentials and the environment flags in the configs, matched case-insensitively
+var regCredentialKey = regexp.MustCompile(`(?i)secret|passw(?:or)?d|token|api_?key|access_?key|private_?key|app_?key|signkey|salt`)
+var regEnvironmentKey = regexp.MustCompile(`(?i)^(?:env|environment|debug|is_?debug|is_?dev|is_?test|mode|stage|base_?url|api_?(?:host|url|base))$`)
+
+// regConfigName matches the names of the config files, like 'ext.json' and 'config.json'
+var regConfigName = regexp.MustCompile(`(?i)(?:^|[._-])(?:ext|config|env|setting|settings|siteinfo)[._-]?.*\.json$`)
+
+// IsConfigFile reports whether the file is a config file which may have the credentials, like 'ext.json',
+// 'config.json' and 'app-config.json', where ext.json is merged to by the third-party platform.
+func IsConfigFile(name string) bool {
+	return regConfigName.MatchString(path.Base(name))
+}
+
+// FindCredentials finds the keys of the cloud services and the private keys in the code.
+func FindCredentials(path string, data []byte) []Finding {
+	return findRules(credentialRules, CategoryCredential, path, data)
+}
+
+// FindConfigCredentials parses the json config file and finds the values of the credential keys, like
+// 'appSecret' in the ext config of the third-party platform, and the environment flags like 'debug'.
+func FindConfigCredentials(path string, data []byte) []Finding {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil
+	}
+
+	var result []Finding
+	var walk func(key string, v interface{})
+	walk = func(key string, v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			var keys = make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				var child = k
+				if key != "" {
+					child = key + "." + k
+				}
+				walk(child, v[k])
+			}
+		case []interface{}:
+			for i, item := range v {
+				walk(fmt.Sprintf("%s[%d]", key, i), item)
+			}
+		case string, float64, bool:
+			var name = key[strings.LastIndex(key, ".")+1:]
+			var finding = Finding{Path: path, Line: 1}
+			switch {
+			case regCredentialKey.MatchString(name) && v != "":
+				finding.Rule, finding.Category, finding.Severity = "config-credential", CategoryCredential, SeverityHigh
+			case regEnvironmentKey.MatchString(name):
+				finding.Rule, finding.Category, finding.Severity = "config-environment", CategoryEnvironment, SeverityLow
+			default:
+				return
+			}
+
+			if i := bytes.Index(data, []byte(`"`+name+`"`)); i >= 0 {
+				finding.Line, _ = LineAt(data, i)
+			}
+			value, _ := json.Marshal(v)
+			finding.Text = key + " = " + string(value)
+			result = append(result, finding)
+		}
+	}
+	walk("", v)
+
+	return result
+}
+
+// FindDynamicCode finds the dynamic code execution in the js or html code, like eval, new Function, the
+// WebAssembly instantiation and the remote scripts, which are checked when vetting the mini programs.
+func FindDynamicCode(path string, data []byte) []Finding {
+	return findRules(dynamicCodeRules, CategoryDynamicCode, path, data)
+}
+
+func findRules(rules []findingRule, category, path string, data []byte) []Finding {
+	var result []Finding
+	for _, rule := range rules {
+		for _, loc := range rule.reg.FindAllIndex(data, -1) {
+			line, text := LineAt(data, loc[0])
+			result = append(result, Finding{Rule: rule.id, Category: category, Severity: rule.severity, Path: path, Line: line, Text: text})
+		}
+	}
+
+	return result
+}
+
+// LineAt returns the line number of the offset in the data, starts from 1, and the text of the line,
+// the long line is cut around the offset.
+func LineAt(data []byte, offset int) (int, string) {
+	var start = bytes.LastIndexByte(data[:offset], '\n') + 1
+	var end = bytes.IndexByte(data[offset:], '\n')
+	if end < 0 {
+		end = len(data)
+	} else {
+		end += offset
+	}
+
+	var line = bytes.Count(data[:start], []byte("\n")) + 1
+	if end-start <= findingTextLength {
+		return line, strings.TrimSpace(string(data[start:end]))
+	}
+
+	var from = offset - findingTextLength/2
+	if from < start {
+		from = start
+	}
+	var to = from + findingTextLength
+	if to > end {
+		to = end
+	}
+	var text = string(data[from:to])
+	if !utf8.ValidString(text) {
+		text = strings.ToValidUTF8(text, "")
+	}
+
+	return line, "..." + strings.TrimSpace(text) + "..."
+}
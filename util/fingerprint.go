@@ -0,0 +1,96 @@
+package util
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+)
+
+type library struct {
+	name    string
+	path    *regexp.Regexp // matched against the entry name
+	content *regexp.Regexp // matched against the head of the entry, where the banner is
+}
+
+var knownLibraries = []library{
+	{
+		name:    "weui",
+		path:    regexp.MustCompile(`(^|/)miniprogram_npm/weui-miniprogram/`),
+		content: regexp.MustCompile(`weui-miniprogram|WeUI v\d`),
+	},
+	{
+		name:    "vant",
+		path:    regexp.MustCompile(`(^|/)miniprogram_npm/@vant/weapp/`),
+		content: regexp.MustCompile(`@vant/weapp`),
+	},
+	{
+		name:    "lodash",
+		path:    regexp.MustCompile(`(^|/)miniprogram_npm/lodash(\.[\w-]+)?/`),
+		content: regexp.MustCompile(`@license\s+Lodash|lodash\.com/license`),
+	},
+	{
+		name:    "dayjs",
+		path:    regexp.MustCompile(`(^|/)miniprogram_npm/dayjs/`),
+		content: regexp.MustCompile(`\)\.dayjs\s*=|\$isDayjsObject`),
+	},
+}
+
+// sha256 -> library name, no hashes are built in, they are only loaded by LoadFingerprints
+var knownHashes = make(map[string]string)
+
+const fingerprintHeadSize = 1024
+
+// LoadFingerprints loads the sha256 hashes of the library builds from a json file like `{"<sha256>": "<library>"}`,
+// so that the minified files without the banners are detected as well.
+func LoadFingerprints(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var hashes map[string]string
+	if err := json.Unmarshal(data, &hashes); err != nil {
+		return err
+	}
+
+	for hash, name := range hashes {
+		knownHashes[strings.ToLower(hash)] = name
+	}
+
+	return nil
+}
+
+// MatchLibrary returns the name of the known library the entry belongs to, or "" for first-party code. The
+// entry is matched by the hashes loaded by LoadFingerprints, then by the npm path and the banner in the head.
+func MatchLibrary(name string, data []byte) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+
+	if len(knownHashes) > 0 {
+		if lib, ok := knownHashes[Sha256(data)]; ok {
+			return lib
+		}
+	}
+
+	for _, lib := range knownLibraries {
+		if lib.path.MatchString(name) {
+			return lib.name
+		}
+	}
+
+	if !strings.HasSuffix(name, ".js") && !strings.HasSuffix(name, ".wxss") {
+		return ""
+	}
+
+	var head = data
+	if len(head) > fingerprintHeadSize {
+		head = head[:fingerprintHeadSize]
+	}
+	for _, lib := range knownLibraries {
+		if lib.content.Match(head) {
+			return lib.name
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,159 @@
+package util
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Har is the HAR 1.2 archive of the requests found in the code, without the responses, for importing into the
+// proxies like Burp and ZAP. See FindRequests.
+type Har struct {
+	Log HarLog `json:"log"`
+}
+
+type HarLog struct {
+	Version string     `json:"version"`
+	Creator HarCreator `json:"creator"`
+	Entries []HarEntry `json:"entries"`
+}
+
+type HarCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HarEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            int         `json:"time"`
+	Request         HarRequest  `json:"request"`
+	Response        HarResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HarTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"` // the call site like 'pages/index/index.js:12'
+}
+
+type HarRequest struct {
+	Method      string         `json:"method"`
+	Url         string         `json:"url"`
+	HttpVersion string         `json:"httpVersion"`
+	Cookies     []HarNameValue `json:"cookies"`
+	Headers     []HarNameValue `json:"headers"`
+	QueryString []HarNameValue `json:"queryString"`
+	PostData    *HarPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HarNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HarPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type HarResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HttpVersion string         `json:"httpVersion"`
+	Cookies     []HarNameValue `json:"cookies"`
+	Headers     []HarNameValue `json:"headers"`
+	Content     HarContent     `json:"content"`
+	RedirectUrl string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HarContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+}
+
+type HarTimings struct {
+	Send    int `json:"send"`
+	Wait    int `json:"wait"`
+	Receive int `json:"receive"`
+}
+
+// NewHar returns the empty archive.
+func NewHar(creator string) *Har {
+	return &Har{Log: HarLog{Version: "1.2", Creator: HarCreator{Name: creator, Version: "1.0"}, Entries: []HarEntry{}}}
+}
+
+// AddRequest adds the request call to the archive, the templates of the base urls like '{baseUrl}' are replaced
+// with baseUrl, and the body is filled with the placeholders of the inferred types. source is the location of
+// the call.
+func (h *Har) AddRequest(call RequestCall, baseUrl, source string) {
+	server, path, query := SplitRequestUrl(call.Url)
+	if server == "" || strings.HasPrefix(server, "{") {
+		server = strings.TrimSuffix(baseUrl, "/")
+	}
+
+	var req = HarRequest{
+		Method:      call.Method,
+		Url:         server + path,
+		HttpVersion: "HTTP/1.1",
+		Cookies:     []HarNameValue{},
+		Headers:     []HarNameValue{},
+		QueryString: []HarNameValue{},
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+
+	for _, name := range sortedStringKeys(call.Header) {
+		req.Headers = append(req.Headers, HarNameValue{Name: name, Value: call.Header[name]})
+	}
+	var contentType = call.contentType()
+
+	var params = url.Values{}
+	if values, err := url.ParseQuery(query); err == nil {
+		params = values
+	}
+	var method = strings.ToUpper(call.Method)
+	if len(call.Data) > 0 && (method == "GET" || method == "DELETE" || method == "HEAD") {
+		for name, t := range call.Data {
+			params.Set(name, dataPlaceholder(name, t, "{%s}"))
+		}
+	} else if len(call.Data) > 0 {
+		req.PostData = &HarPostData{MimeType: contentType, Text: dataBody(call.Data, contentType, "{%s}")}
+		req.BodySize = len(req.PostData.Text)
+	}
+	for name, values := range params {
+		for _, v := range values {
+			req.QueryString = append(req.QueryString, HarNameValue{Name: name, Value: v})
+		}
+	}
+	sort.SliceStable(req.QueryString, func(i, j int) bool {
+		return req.QueryString[i].Name < req.QueryString[j].Name
+	})
+	if len(params) > 0 {
+		req.Url += "?" + params.Encode()
+	}
+
+	h.Log.Entries = append(h.Log.Entries, HarEntry{
+		StartedDateTime: time.Unix(0, 0).UTC().Format(time.RFC3339),
+		Request:         req,
+		Response: HarResponse{
+			HttpVersion: "HTTP/1.1",
+			Cookies:     []HarNameValue{},
+			Headers:     []HarNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Comment: source,
+	})
+}
+
+func sortedStringKeys(m map[string]string) []string {
+	var keys = make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,246 @@
+package util
+
+import (
+	"html"
+	"html/template"
+	"path/filepath"
+	"strings"
+)
+
+// the classes of the highlighted tokens, see HighlightCss
+const (
+	tokenPlain   = ""
+	tokenKeyword = "k"
+	tokenString  = "s"
+	tokenComment = "c"
+	tokenNumber  = "n"
+	tokenTag     = "t"
+	tokenAttr    = "a"
+)
+
+// HighlightCss is the style of the classes in the highlighted code.
+const HighlightCss = `.k{color:#0033b3;font-weight:bold}.s{color:#067d17}.c{color:#8c8c8c;font-style:italic}` +
+	`.n{color:#1750eb}.t{color:#0033b3}.a{color:#871094}`
+
+var jsKeywords = map[string]bool{
+	"var": true, "let": true, "const": true, "function": true, "return": true, "if": true, "else": true,
+	"for": true, "while": true, "do": true, "switch": true, "case": true, "default": true, "break": true,
+	"continue": true, "new": true, "delete": true, "typeof": true, "instanceof": true, "in": true, "of": true,
+	"this": true, "null": true, "undefined": true, "true": true, "false": true, "try": true, "catch": true,
+	"finally": true, "throw": true, "class": true, "extends": true, "super": true, "import": true,
+	"export": true, "async": true, "await": true, "yield": true, "void": true,
+}
+
+type token struct {
+	class string
+	text  string
+}
+
+// HighlightLines highlights the code by the extension of the file name, it returns the html of every line,
+// the tokens spanning multiple lines are split so that every line is balanced. The unknown types are only
+// escaped.
+func HighlightLines(name string, code []byte) []template.HTML {
+	var tokens []token
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".js", ".wxs", ".json":
+		tokens = scriptTokens(string(code), true)
+	case ".wxss", ".css":
+		tokens = scriptTokens(string(code), false)
+	case ".html", ".wxml", ".xml", ".svg":
+		tokens = markupTokens(string(code))
+	default:
+		tokens = []token{{text: string(code)}}
+	}
+
+	var lines []template.HTML
+	var line strings.Builder
+	for _, t := range tokens {
+		for i, part := range strings.Split(t.text, "\n") {
+			if i > 0 {
+				lines = append(lines, template.HTML(line.String()))
+				line.Reset()
+			}
+			if part == "" {
+				continue
+			}
+			if t.class == tokenPlain {
+				line.WriteString(html.EscapeString(part))
+			} else {
+				line.WriteString(`<span class="` + t.class + `">` + html.EscapeString(part) + `</span>`)
+			}
+		}
+	}
+
+	// no empty line after the last line break
+	if line.Len() > 0 || len(lines) == 0 {
+		lines = append(lines, template.HTML(line.String()))
+	}
+
+	return lines
+}
+
+// scriptTokens splits the js, json or wxss code into the tokens, lineComment enables the '//' comments.
+func scriptTokens(code string, lineComment bool) []token {
+	var tokens []token
+	var plain = 0 // the start of the pending plain text
+	var emit = func(start, end int, class string) {
+		if plain < start {
+			tokens = append(tokens, token{text: code[plain:start]})
+		}
+		tokens = append(tokens, token{class: class, text: code[start:end]})
+		plain = end
+	}
+
+	var i = 0
+	for i < len(code) {
+		var c = code[i]
+		switch {
+		case lineComment && strings.HasPrefix(code[i:], "//"):
+			var end = strings.IndexByte(code[i:], '\n')
+			if end < 0 {
+				end = len(code) - i
+			}
+			emit(i, i+end, tokenComment)
+			i += end
+		case strings.HasPrefix(code[i:], "/*"):
+			var end = strings.Index(code[i+2:], "*/")
+			if end < 0 {
+				end = len(code) - i
+			} else {
+				end += 4
+			}
+			emit(i, i+end, tokenComment)
+			i += end
+		case c == '"' || c == '\'' || c == '`':
+			var end = quotedEnd(code, i)
+			emit(i, end, tokenString)
+			i = end
+		case isDigit(c) && (i == 0 || !isIdentChar(code[i-1])):
+			var end = i + 1
+			for end < len(code) && (isIdentChar(code[end]) || code[end] == '.') {
+				end++
+			}
+			emit(i, end, tokenNumber)
+			i = end
+		case isIdentChar(c):
+			var end = i + 1
+			for end < len(code) && isIdentChar(code[end]) {
+				end++
+			}
+			if lineComment && jsKeywords[code[i:end]] && (i == 0 || code[i-1] != '.') {
+				emit(i, end, tokenKeyword)
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+	if plain < len(code) {
+		tokens = append(tokens, token{text: code[plain:]})
+	}
+
+	return tokens
+}
+
+// markupTokens splits the html or wxml code into the tokens, the tags, the attributes and their values.
+func markupTokens(code string) []token {
+	var tokens []token
+	var plain = 0
+	var emit = func(start, end int, class string) {
+		if plain < start {
+			tokens = append(tokens, token{text: code[plain:start]})
+		}
+		tokens = append(tokens, token{class: class, text: code[start:end]})
+		plain = end
+	}
+
+	var i = 0
+	for i < len(code) {
+		if strings.HasPrefix(code[i:], "<!--") {
+			var end = strings.Index(code[i+4:], "-->")
+			if end < 0 {
+				end = len(code) - i
+			} else {
+				end += 7
+			}
+			emit(i, i+end, tokenComment)
+			i += end
+			continue
+		}
+		if code[i] != '<' {
+			i++
+			continue
+		}
+
+		// the tag name, like '<view' or '</view'
+		var end = i + 1
+		if end < len(code) && (code[end] == '/' || code[end] == '!' || code[end] == '?') {
+			end++
+		}
+		for end < len(code) && (isIdentChar(code[end]) || code[end] == '-' || code[end] == ':') {
+			end++
+		}
+		emit(i, end, tokenTag)
+		i = end
+
+		// the attributes until '>'
+		for i < len(code) && code[i] != '>' && code[i] != '<' {
+			var c = code[i]
+			switch {
+			case c == '"' || c == '\'':
+				end = quotedEnd(code, i)
+				emit(i, end, tokenString)
+				i = end
+			case isIdentChar(c) || c == '-' || c == ':' || c == '@':
+				end = i + 1
+				for end < len(code) && (isIdentChar(code[end]) || code[end] == '-' || code[end] == ':' || code[end] == '.') {
+					end++
+				}
+				emit(i, end, tokenAttr)
+				i = end
+			default:
+				i++
+			}
+		}
+		if i < len(code) && code[i] == '>' {
+			var start = i
+			if start > 0 && code[start-1] == '/' {
+				start--
+			}
+			emit(start, i+1, tokenTag)
+			i++
+		}
+	}
+	if plain < len(code) {
+		tokens = append(tokens, token{text: code[plain:]})
+	}
+
+	return tokens
+}
+
+// quotedEnd returns the end of the string quoted by code[start], after the closing quote.
+func quotedEnd(code string, start int) int {
+	var quote = code[start]
+	for i := start + 1; i < len(code); i++ {
+		switch code[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		case '\n':
+			if quote != '`' {
+				return i
+			}
+		}
+	}
+
+	return len(code)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
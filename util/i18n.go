@@ -0,0 +1,487 @@
+package util
+
+import (
+	"os"
+	"strings"
+)
+
+// Lang is the language of the messages, 'en' or 'zh'. It's detected from the `--lang` flag,
+// the `WXAPKG_LANG` and `LANG` environment variables in order.
+var Lang = detectLang()
+
+func detectLang() string {
+	for i, arg := range os.Args {
+		if strings.HasPrefix(arg, "--lang=") {
+			return normalizeLang(strings.TrimPrefix(arg, "--lang="))
+		}
+		if arg == "--lang" && i+1 < len(os.Args) {
+			return normalizeLang(os.Args[i+1])
+		}
+	}
+
+	for _, env := range []string{"WXAPKG_LANG", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			return normalizeLang(v)
+		}
+	}
+
+	return "en"
+}
+
+func normalizeLang(lang string) string {
+	if strings.HasPrefix(strings.ToLower(lang), "zh") {
+		return "zh"
+	}
+
+	return "en"
+}
+
+// english -> chinese
+var zhMessages = map[string]string{
+	// commands and flags
+	"A tool to scan and decrypt wechat mini program":                              "扫描和解密微信小程序的工具",
+	"disable js,html,json beautify":                                               "禁用 js、html、json 代码美化",
+	"the language of the messages, 'en' or 'zh'":                                  "提示信息的语言，'en' 或 'zh'",
+	"Decrypt wechat mini program":                                                 "解密微信小程序",
+	"Scan the wechat mini program":                                                "扫描微信小程序",
+	"Export the module dependency graph of the unpacked mini program":             "导出已解包小程序的模块依赖关系图",
+	"Map the pages of the unpacked mini program to the wx APIs and urls they use": "统计已解包小程序每个页面使用的 wx 接口和网络地址",
+	"Generate the route map of the unpacked mini program from 'app-config.json'":  "根据 'app-config.json' 生成已解包小程序的路由表",
+	"Restore the source structure of the unpacked mini program":                   "还原已解包小程序的源码结构",
+	"the mini progress path you want to decrypt, see: ":                           "需要解密的小程序路径，例如：",
+	"the mini app path": "小程序路径",
+	"the output path to save result, '{date}', '{time}' and '{run-id}' in it are replaced, like 'unpack/{date}'": "保存结果的路径，其中的 '{date}'、'{time}' 和 '{run-id}' 会被替换，例如 'unpack/{date}'",
+	"the thread number, 0 for auto":                                         "线程数，0 表示自动",
+	"the number of packages unpacked in parallel, 0 for the number of CPUs": "并行解包的包数量，0 表示 CPU 核数",
+	"the unpacked path": "已解包的路径",
+	"place known libraries (weui, vant, lodash, dayjs) under 'vendor/'":   "将已知的第三方库（weui、vant、lodash、dayjs）放到 'vendor/' 目录",
+	"detect license headers and save a license report to 'licenses.json'": "识别许可证并保存到 'licenses.json'",
+	"open the output path in the file manager when finished":              "完成后在文件管理器中打开输出路径",
+
+	"the graph format, 'dot' or 'json'":                  "依赖关系图的格式，'dot' 或 'json'",
+	"the routes format, 'json', 'md' or 'mermaid'":       "路由表的格式，'json'、'md' 或 'mermaid'",
+	"open the restored project in wechat devtools":       "在微信开发者工具中打开还原后的项目",
+	"the path of wechat devtools cli, detected if empty": "微信开发者工具命令行的路径，为空时自动查找",
+
+	// unpack
+	"[+] unpack root '%s' with %d threads\n":       "[+] 使用 %[2]d 个线程解包 '%[1]s'\n",
+	"[+] unpacked %5d files from '%s'":             "[+] 从 '%[2]s' 解包了 %5[1]d 个文件",
+	"[+] all %d files saved to '%s'\n":             "[+] 共 %d 个文件保存到 '%s'\n",
+	"[+] mini program detail info saved to '%s'\n": "[+] 小程序详细信息保存到 '%s'\n",
+	"[+] extension statistics:\n":                  "[+] 文件后缀统计：\n",
+	"%d/%d packages, %s/%s":                        "%d/%d 个包，%s/%s",
+	"[!] skip '%s', it's held by the running wechat, try again after closing the mini program\n": "[!] 跳过 '%s'，文件被正在运行的微信占用，请关闭小程序后重试\n",
+	"[!] %d packages skipped because wechat holds them:\n":                                       "[!] %d 个包被微信占用而跳过：\n",
+	"failed to unpack, it's not a valid wxapkg file":                                             "解包失败，不是有效的 wxapkg 文件",
+	"invalid decrypted wxapkg file":                                                              "解密后的 wxapkg 文件无效",
+	"no '.wxapkg' file found in '%s'":                                                            "在 '%s' 中没有找到 '.wxapkg' 文件",
+	"failed to decrypt '%s', the file is too small":                                              "解密 '%s' 失败，文件太小",
+	"failed to decrypt '%s', please check the wxid":                                              "解密 '%s' 失败，请检查 wxid",
+	"the path is not a mini program path":                                                        "该路径不是小程序路径",
+	"wechat devtools cli not found, use '--devtools-cli' to specify it":                          "没有找到微信开发者工具命令行，请使用 '--devtools-cli' 参数指定",
+
+	// applet
+	"the mini app path, discovered if empty": "小程序路径，为空时自动查找",
+
+	"the retry times of the failed writes": "写入失败时的重试次数",
+
+	// memory
+	"the memory budget like '512MB', the large packages are streamed instead of loaded": "内存限制，例如 '512MB'，超出限制的大文件会以流式方式处理",
+	"invalid size '%s'": "无效的大小 '%s'",
+
+	// stats
+	"Show the statistics of the unpacked mini programs":             "统计已解包小程序的文件信息",
+	"[+] %d files, %s in %d unpacked folders of %d mini programs\n": "[+] %[4]d 个小程序的 %[3]d 个解包目录中共有 %[1]d 个文件，%[2]s\n",
+	"[+] mini program statistics:\n":                                "[+] 小程序统计：\n",
+	"[+] the largest %d files:\n":                                   "[+] 最大的 %d 个文件：\n",
+	"the number of the largest files to show":                       "显示最大文件的数量",
+
+	// verify
+	"Verify the unpacked files with the checksums in the manifest": "使用 manifest 中的校验和校验解包后的文件",
+	"%d of %d files are modified or missing":                       "%[2]d 个文件中有 %[1]d 个被修改或缺失",
+	"[+] all %d files in %d unpacked folders are verified\n":       "[+] %[2]d 个解包目录中的 %[1]d 个文件校验通过\n",
+	"%s: no checksum in the manifest":                              "%s：manifest 中没有校验和",
+	"%s: missing":                                                  "%s：缺失",
+	"%s: modified":                                                 "%s：已被修改",
+
+	"Verify the unpacked files with the checksums in the manifest, or the wxapkg files without extraction": "使用 manifest 中的校验和校验解包后的文件，或者不解包直接校验 wxapkg 文件",
+	"[+] '%s' is valid\n":                          "[+] '%s' 校验通过\n",
+	"[-] '%s' is invalid:\n":                       "[-] '%s' 校验失败：\n",
+	"%d of %d wxapkg files are invalid":            "%[2]d 个 wxapkg 文件中有 %[1]d 个无效",
+	"the index length is %d, but %d in the header": "索引长度为 %d，但文件头中为 %d",
+	"the body length is %d, but %d in the header":  "数据长度为 %d，但文件头中为 %d",
+	"%s: duplicate file":                           "%s：文件重复",
+	"%s: out of range [%d:%d]":                     "%s：超出范围 [%d:%d]",
+	"%s: overlaps with %s":                         "%s：与 %s 重叠",
+	"the wxid to decrypt the wxapkg files, detected from the path if empty": "用于解密 wxapkg 文件的 wxid，为空时从路径中获取",
+
+	// diffdir
+	"Compare two unpacked folders, show the added, removed and modified files": "比较两个解包目录，显示新增、删除和修改的文件",
+	"only list the changed files, without the text diffs":                      "只列出有变化的文件，不显示文本差异",
+	"Binary files a/%[1]s and b/%[1]s differ\n":                                "二进制文件 a/%[1]s 和 b/%[1]s 不同\n",
+	"[+] %d added, %d removed, %d modified\n":                                  "[+] 新增 %d 个，删除 %d 个，修改 %d 个\n",
+
+	// timeline
+	"Show how the versions of the unpacked mini programs evolve": "显示已解包小程序各个版本的变化",
+	"[+] timeline of %d mini programs saved to '%s'\n":           "[+] %d 个小程序的版本时间线保存到 '%s'\n",
+
+	// beautify
+	"only beautify the given types, like 'js,json'":         "只美化指定类型的文件，例如 'js,json'",
+	"do not beautify the given types, like 'html'":          "不美化指定类型的文件，例如 'html'",
+	"unknown beautify type '%s', supported: js, json, html": "未知的美化类型 '%s'，支持：js、json、html",
+
+	"the indent width of the beautified json":                    "美化后 json 的缩进宽度",
+	"sort the keys of the beautified json, for the stable diffs": "对美化后 json 的键排序，便于比较差异",
+
+	"transcode the text files in GBK to UTF-8, the original charset is recorded in the manifest": "将 GBK 编码的文本文件转换为 UTF-8，原始编码记录在 manifest 中",
+
+	"the line endings of the text files, 'lf', 'crlf' or 'keep'": "文本文件的换行符，'lf'、'crlf' 或 'keep'",
+	"invalid line ending '%s', 'lf', 'crlf' or 'keep' expected":  "无效的换行符 '%s'，应为 'lf'、'crlf' 或 'keep'",
+
+	"strip, add or keep the UTF-8 BOM of the text files, 'strip', 'add' or 'keep'": "去除、添加或保留文本文件的 UTF-8 BOM，'strip'、'add' 或 'keep'",
+	"invalid bom option '%s', 'strip', 'add' or 'keep' expected":                   "无效的 BOM 选项 '%s'，应为 'strip'、'add' 或 'keep'",
+
+	// output
+	"upload to the object storage like 's3://bucket/prefix', 'oss://', 'cos://', or the WebDAV server like 'webdavs://host/path' instead of the output path": "上传到对象存储，例如 's3://bucket/prefix'、'oss://'、'cos://'，或者 WebDAV 服务器，例如 'webdavs://host/path'，而不是保存到输出路径",
+	"unsupported destination '%s'":                      "不支持的目标 '%s'",
+	"no bucket in '%s'":                                 "'%s' 中没有存储桶",
+	"the credentials or the region of '%s' are not set": "没有设置 '%s' 的密钥或地域",
+
+	// archive
+	"the output format, 'dir' or the archive formats: ":                    "输出格式，'dir' 或者压缩包格式：",
+	"the compression level of the archive, the default level is used if 0": "压缩包的压缩级别，为 0 时使用默认级别",
+	"unsupported archive format '%s', supported: %s":                       "不支持的压缩包格式 '%s'，支持：%s",
+	"'zstd' is not found, it's required by the 'tar.zst' format":           "没有找到 'zstd' 命令，'tar.zst' 格式需要该命令",
+	"the archive formats can't be used with '--dest'":                      "压缩包格式不能和 '--dest' 参数一起使用",
+
+	"'7z' is not found, it's required by the '7z' format": "没有找到 '7z' 命令，'7z' 格式需要该命令",
+
+	"encrypt the zip archive with AES-256, read from the 'WXAPKG_PASSWORD' environment variable if empty": "使用 AES-256 加密 zip 压缩包，为空时从 'WXAPKG_PASSWORD' 环境变量读取",
+	"the password is only supported by the 'zip' format":                                                  "只有 'zip' 格式支持密码",
+
+	"invalid layout '%s', 'merged', 'package' or 'flat' expected": "无效的目录布局 '%s'，应为 'merged'、'package' 或 'flat'",
+
+	"the same as '--layout flat'": "等同于 '--layout flat'",
+
+	"csv file mapping the loose wxapkg file names to the wxids, like 'name.wxapkg,wx1234567890abcdef'": "将散落的 wxapkg 文件名映射到 wxid 的 csv 文件，例如 'name.wxapkg,wx1234567890abcdef'",
+	"invalid wxid map '%s': %v": "无效的 wxid 映射文件 '%s': %v",
+	"[!] skip '%s', no wxid found in the file name, the wxid map or the package\n": "[!] 跳过 '%s'，文件名、wxid 映射文件和包中都没有找到 wxid\n",
+
+	"no wxid found for the wxapkg files in '%s'": "'%s' 中的 wxapkg 文件都没有找到 wxid",
+
+	"[!] wxid '%s' is recovered from '%s'\n": "[!] 从 '%[2]s' 中恢复出 wxid '%[1]s'\n",
+
+	"unknown profile '%s', 'auto' or %s expected":                                          "未知的解密配置 '%s'，应为 'auto' 或 %s",
+	"the decryption profile of the WeChat build, 'auto' detects it from the packages, or ": "微信版本的解密配置，'auto' 根据包自动识别，或者 ",
+
+	"the external command to decrypt the packages in the unknown formats, it reads the encrypted data from stdin and writes the decrypted data to stdout, '{wxid}' in it is replaced with the wxid": "解密未知格式的包的外部命令，从标准输入读取加密数据并将解密后的数据写入标准输出，其中的 '{wxid}' 会被替换为 wxid",
+	"failed to decrypt '%s' with the decryptor: %v":              "使用外部解密命令解密 '%s' 失败: %v",
+	"the output of the decryptor is not a decrypted wxapkg file": "外部解密命令的输出不是解密后的 wxapkg 文件",
+
+	"invalid decrypted wxapkg file, the info1 0x%08x in the header is unknown, the format may be changed":              "无效的解密后的 wxapkg 文件，文件头中的 info1 0x%08x 未知，格式可能已变化",
+	"[!] '%s' has an unknown info1 0x%08x in the header, the format may be changed, please check the unpacked files\n": "[!] '%s' 文件头中的 info1 0x%08x 未知，格式可能已变化，请检查解包后的文件\n",
+	"  - info1 0x%08x, %d files, the index %d bytes, the body %d bytes\n":                                              "  - info1 0x%08x，%d 个文件，索引 %d 字节，数据 %d 字节\n",
+	"the info1 0x%08x in the header is unknown, the format may be changed":                                             "文件头中的 info1 0x%08x 未知，格式可能已变化",
+
+	"[!] the index of '%s' is broken, %d files are carved to 'carved/'\n":                 "[!] '%s' 的索引已损坏，从中恢复出 %d 个文件，保存到 'carved/'\n",
+	"invalid decrypted wxapkg file, no file is carved":                                    "无效的解密后的 wxapkg 文件，没有恢复出任何文件",
+	"carve the files by their signatures from the damaged packages with the broken index": "根据文件特征从索引损坏的包中恢复文件",
+
+	"[+] skip '%s', it's unpacked already, use '--force' to unpack again\n":                        "[+] 跳过 '%s'，已经解包过，使用 '--force' 参数重新解包\n",
+	"unpack the folders again even if their wxapkg files are not changed since the last unpacking": "即使 wxapkg 文件在上次解包后没有变化也重新解包",
+
+	"the output path '%s' is being written by another process, try again later: %v": "输出目录 '%s' 正在被另一个进程写入，请稍后重试: %v",
+
+	"print the packages, the errors with the stable codes like 'ERR_DECRYPT' and the summary in JSON lines": "以 JSON Lines 格式输出包、带有固定错误码（例如 'ERR_DECRYPT'）的错误和汇总信息",
+
+	"invalid progress mode '%s', 'bar', 'plain' or 'none' expected":                                                         "无效的进度模式 '%s'，应为 'bar'、'plain' 或 'none'",
+	"the progress mode, 'bar' updates one line in place, 'plain' prints one line every interval for the CI logs, or 'none'": "进度显示模式，'bar' 在同一行刷新，'plain' 每隔一段时间输出一行（适合 CI 日志），或 'none'",
+	"the minimum interval between the progress updates like '1s', 100ms for 'bar' and 5s for 'plain' by default":            "进度刷新的最小间隔，例如 '1s'，'bar' 默认 100ms，'plain' 默认 5s",
+
+	", %[3]s written, %[4]d beautified, %[5]d failed in %[6]s": "，写入 %[3]s，美化 %[4]d 个，失败 %[5]d 个，耗时 %[6]s",
+
+	"replace the files identical to the previous version of the mini program with the symlinks, the previous versions must be kept": "将与小程序上一个版本相同的文件替换为符号链接，需要保留之前的版本",
+	"'--link-versions' only works with the local output path":                                                                       "'--link-versions' 只能用于本地输出目录",
+	"[+] %d files identical to the previous versions are linked, %s saved\n":                                                        "[+] %d 个与上一个版本相同的文件已替换为链接，节省了 %s\n",
+
+	"[+] %d duplicate files are hard linked, %s saved\n": "[+] %d 个重复的文件已硬链接，节省了 %s\n",
+
+	"'--cas' only works with the local output path":                             "'--cas' 只能用于本地输出路径",
+	"[+] %d new files stored to '%s', %d files linked to the store, %s saved\n": "[+] %[1]d 个新文件已存入 '%[2]s'，%[3]d 个文件链接到存储，节省 %[4]s\n",
+	"store the bodies of the files once in the content-addressable store folder and link the unpacked files to them, for the archives of many versions, the linked files are read-only": "将文件内容按哈希只存一份到内容寻址存储目录，并将解包文件链接到其中，适合归档大量版本，链接的文件是只读的",
+
+	"Export the unpacked mini programs as a static html site": "将解包的小程序导出为静态 html 网站",
+	"[+] %d files of '%s' exported\n":                         "[+] 已导出 '%[2]s' 的 %[1]d 个文件\n",
+	"[+] the site of %d unpacked folders saved to '%s'\n":     "[+] %d 个解包目录的网站已保存到 '%s'\n",
+	"the folder to save the site":                             "保存网站的目录",
+
+	"Build the full-text index of the unpacked mini programs for the search command": "为 search 命令建立已解包小程序的全文索引",
+	"[+] %d files and %d terms indexed to '%s'\n":                                    "[+] 已索引 %d 个文件、%d 个词到 '%s'\n",
+	"Search the unpacked mini programs with the index built by the index command":    "使用 index 命令建立的索引搜索已解包的小程序",
+	"no index in '%s', run '%s index -o %s' first":                                   "'%s' 中没有索引，请先运行 '%s index -o %s'",
+	"[!] the index is outdated, run the index command again: %v\n":                   "[!] 索引已过期，请重新运行 index 命令：%v\n",
+	"[+] %d matches in %d mini programs\n":                                           "[+] 在 %[2]d 个小程序中找到 %[1]d 处匹配\n",
+	"the maximum number of the matched lines":                                        "最多显示的匹配行数",
+
+	"Find in which versions of the mini programs the regex first appears or disappears": "查找正则表达式在小程序的哪个版本中首次出现或消失",
+	"invalid regex '%s': %v":                         "无效的正则表达式 '%s'：%v",
+	"no unpacked folder of '%s' found in '%s'":       "在 '%[2]s' 中没有找到 '%[1]s' 的解包目录",
+	"  + %s  appeared, %d matches, first at %s:%d\n": "  + %s  出现，%d 处匹配，首次位于 %s:%d\n",
+	"  - %s  disappeared\n":                          "  - %s  消失\n",
+	"    %s  %d matches\n":                           "    %s  %d 处匹配\n",
+	"    %s  not found\n":                            "    %s  未找到\n",
+	"only hunt in the versions of the mini program, all the mini programs by default": "只查找该小程序的各个版本，默认查找所有小程序",
+	"match the regex case-insensitively":                                              "匹配正则表达式时忽略大小写",
+
+	"no unpacked folder found in '%s'": "在 '%s' 中没有找到解包目录",
+
+	"Extract the printable strings from the binary files in the wxapkg files, like the images and wasm modules": "从 wxapkg 文件中的二进制文件（例如图片和 wasm 模块）中提取可打印的字符串",
+	"invalid encoding '%s', 'ascii', 'utf-8' or 'utf-16le' expected":                                            "无效的编码 '%s'，应为 'ascii'、'utf-8' 或 'utf-16le'",
+	"[+] %d strings found\n":                                        "[+] 找到 %d 个字符串\n",
+	"the minimum length of the strings":                             "字符串的最小长度",
+	"only show the strings matching the regex":                      "只显示匹配正则表达式的字符串",
+	"the encodings of the strings, 'ascii', 'utf-8' and 'utf-16le'": "字符串的编码，'ascii'、'utf-8' 和 'utf-16le'",
+	"extract the strings from the text files as well":               "同时从文本文件中提取字符串",
+
+	"Find the high entropy regions in the unpacked files, like the embedded encrypted data or packed code": "查找解包文件中的高熵区域，例如内嵌的加密数据或加壳代码",
+	"  - %s  entropy %.2f, %d high entropy regions\n":                                                      "  - %s  熵 %.2f，%d 个高熵区域\n",
+	"      line %d, 0x%x-0x%x, %d bytes, entropy %.2f\n":                                                   "      第 %d 行，0x%x-0x%x，%d 字节，熵 %.2f\n",
+	"      0x%x-0x%x, %d bytes, entropy %.2f\n":                                                            "      0x%x-0x%x，%d 字节，熵 %.2f\n",
+	"[+] %d files with high entropy regions saved to '%s'\n":                                               "[+] %d 个含高熵区域的文件已保存到 '%s'\n",
+	"the size of the sliding window in bytes":                                                              "滑动窗口的大小（字节）",
+	"the entropy threshold of the text files in bits per byte, the base64 of the random data is about 6":   "文本文件的熵阈值（比特/字节），随机数据的 base64 约为 6",
+	"the entropy threshold of the binary files in bits per byte":                                           "二进制文件的熵阈值（比特/字节）",
+
+	"Check the unpacked mini programs for the risky code and the leaked credentials, like eval and the app secrets": "检查已解包小程序中的风险代码和泄露的凭据，例如 eval 和 app secret",
+	"[+] %d findings saved to '%s'\n":          "[+] %d 个发现已保存到 '%s'\n",
+	"[+] %d findings in %d unpacked folders\n": "[+] %[2]d 个解包目录中共 %[1]d 个发现\n",
+
+	"[!] %d credentials found, check them first\n": "[!] 发现 %d 个凭据，请优先检查\n",
+
+	"Show the mini programs built by the third-party platform, with their templates and tenants": "显示由第三方平台构建的小程序及其模板和租户",
+	"[+] %s is built by the third-party platform\n":                                              "[+] %s 由第三方平台构建\n",
+	"  - template ids: %s\n":               "  - 模板 id：%s\n",
+	"  - ext config: %s\n":                 "  - ext 配置：%s\n",
+	"[+] template %s is used by %s\n":      "[+] 模板 %s 被 %s 使用\n",
+	"[+] the same code %s is used by %s\n": "[+] 相同的代码 %s 被 %s 使用\n",
+	"[+] %d mini programs built by the third-party platform saved to '%s'\n": "[+] %d 个由第三方平台构建的小程序已保存到 '%s'\n",
+
+	"[!] failed to parse the app config of '%s': %v\n": "[!] 解析 '%s' 的 app 配置失败：%v\n",
+
+	"'--root' is required unless '--all-accounts' is set": "未指定 '--all-accounts' 时必须指定 '--root'",
+	"no applet root of the wechat accounts found":         "没有找到微信账号的小程序目录",
+	"unpack the mini programs of all the wechat accounts logged in on this machine, saved to '<account>/<wxid>/', the same as '--root' with the 'WeChat Files' folder": "解包本机所有已登录微信账号的小程序，保存到 '<账号>/<wxid>/'，与使用 'WeChat Files' 目录作为 '--root' 相同",
+	"[!] skip '%s': %v\n":                                      "[!] 跳过 '%s'：%v\n",
+	"[+] found %d mini programs in '%s'\n":                     "[+] 在 '%[2]s' 中找到 %[1]d 个小程序\n",
+	"[+] found %d mini programs of the account '%s' in '%s'\n": "[+] 在 '%[3]s' 中找到账号 '%[2]s' 的 %[1]d 个小程序\n",
+
+	"invalid normalization form '%s', 'nfc', 'nfd' or 'keep' expected":                                                          "无效的规范化形式 '%s'，应为 'nfc'、'nfd' 或 'keep'",
+	"the unicode normalization form of the file names, 'nfc', 'nfd' or 'keep', the original names are recorded in the manifest": "文件名的 Unicode 规范化形式，'nfc'、'nfd' 或 'keep'，原始文件名记录在清单中",
+
+	"%s: unexpected panic at %s: %v": "%s：在 %s 发生意外的 panic：%v",
+
+	"invalid thread number %d, 0 for auto or 1 to %d expected":                   "无效的线程数 %d，应为 0（自动）或 1 到 %d",
+	"invalid number of the parallel packages %d, 0 for auto or 1 to %d expected": "无效的并行包数量 %d，应为 0（自动）或 1 到 %d",
+
+	"invalid version filter '%s', like '12', '10-15' or '>=12' expected":                                                   "无效的版本过滤条件 '%s'，应类似 '12'、'10-15' 或 '>=12'",
+	"no version in '%s' is selected by the version filter":                                                                 "'%s' 中没有符合版本过滤条件的版本",
+	"only unpack the version folders selected by the names or the ranges, like '12', '10-15', '>=12', separated by commas": "只解包名称或范围匹配的版本目录，例如 '12'、'10-15'、'>=12'，使用逗号分隔",
+
+	"invalid time '%s', like '2023-06-01', '2023-06-01 12:00:00' or '24h' expected":                                                                                  "无效的时间 '%s'，应类似 '2023-06-01'、'2023-06-01 12:00:00' 或 '24h'",
+	"'--newer-than last' only works with the local output path":                                                                                                      "'--newer-than last' 只能用于本地输出目录",
+	"[+] %d of %d folders have the wxapkg files modified after %s\n":                                                                                                 "[+] %[2]d 个目录中有 %[1]d 个目录的 wxapkg 文件在 %[3]s 之后修改过\n",
+	"only unpack the folders with the wxapkg files modified after the time like '2023-06-01', '24h' ago, or 'last' for the start of the last run in the output path": "只解包 wxapkg 文件在指定时间之后修改过的目录，例如 '2023-06-01'、'24h'（24 小时内），或 'last' 表示输出目录中上次运行的开始时间",
+
+	"Report the files identical across the main package and the subpackages, like the shared utils copied into every package": "报告主包和分包之间内容相同的文件，例如复制到每个包中的公共工具代码",
+	"  ... %d more\n":                        "  ... 还有 %d 个\n",
+	"  - %s x%d in %d packages, %s wasted\n": "  - %s 共 %d 份，分布在 %d 个包中，浪费 %s\n",
+	"[+] %d files are duplicated across the packages, %s wasted, saved to '%s'\n": "[+] %d 个文件在多个包中重复，浪费 %s，已保存到 '%s'\n",
+	"the number of the duplicate groups to show, 0 for all":                       "显示的重复文件组数量，0 表示全部",
+
+	"Find the vendor chunks shared by many different mini programs and tag them in the manifests": "查找被多个不同小程序共用的第三方代码块，并在清单中标记",
+	"  - %s  %s, in %d mini programs, %d files\n":                                                 "  - %s  %s，出现在 %d 个小程序中，共 %d 个文件\n",
+	"[+] %d shared vendor chunks found in %d unpacked folders, saved to '%s'\n":                   "[+] 在 %[2]d 个解包目录中找到 %[1]d 个共用的第三方代码块，已保存到 '%[3]s'\n",
+	"[+] %d files tagged as '%s' in the manifests, they are skipped by the audit\n":               "[+] 已在清单中将 %d 个文件标记为 '%s'，审计时会跳过这些文件\n",
+	"the minimum number of the different mini programs sharing the chunk":                         "共用代码块的不同小程序的最小数量",
+	"the minimum size of the chunks, the tiny files are shared by chance":                         "代码块的最小大小，很小的文件可能只是碰巧相同",
+	"only report the chunks, do not tag them in the manifests":                                    "只报告代码块，不在清单中标记",
+
+	"restore the original sources like the TypeScript files from the inline source maps with 'sourcesContent' to 'src/'": "从带有 'sourcesContent' 的内联 source map 中还原 TypeScript 等原始源码到 'src/'",
+
+	"save the inline source maps to the sibling '.map' files and reference them in the scripts, for the devtools": "将内联 source map 保存为同目录的 '.map' 文件并在脚本中引用，方便开发者工具加载",
+
+	"Count the blank, comment and code lines of the unpacked files by the languages, like cloc": "按语言统计解包文件的空行、注释行和代码行，类似 cloc",
+	"language":                            "语言",
+	"files":                               "文件",
+	"blank":                               "空行",
+	"comment":                             "注释",
+	"code":                                "代码",
+	"[+] the line counts saved to '%s'\n": "[+] 代码行数统计已保存到 '%s'\n",
+	"count the known libraries and the shared vendor chunks too": "同时统计已知的第三方库和共用的第三方代码块",
+
+	"Report the largest and most complex modules, which deserve the manual review first": "报告最大、最复杂的模块，优先人工审查这些模块",
+	"invalid sort '%s', 'complexity', 'size' or 'statements' expected":                   "无效的排序方式 '%s'，应为 'complexity'、'size' 或 'statements'",
+	"complexity":                     "复杂度",
+	"statements":                     "语句",
+	"functions":                      "函数",
+	"size":                           "大小",
+	"module":                         "模块",
+	"[+] %d modules saved to '%s'\n": "[+] %d 个模块已保存到 '%s'\n",
+	"the number of the modules to show, 0 for all":             "显示的模块数量，0 表示全部",
+	"sort the modules by 'complexity', 'size' or 'statements'": "模块的排序方式，'complexity'、'size' 或 'statements'",
+
+	"Generate the draft OpenAPI document from the wx.request calls of the unpacked mini program":        "根据解包小程序中的 wx.request 调用生成 OpenAPI 文档草稿",
+	"generated from the wx.request calls statically, the dynamic parts are named after the expressions": "根据 wx.request 调用静态生成，动态部分以表达式命名",
+	"[+] %d request calls of '%s' mapped to %d paths, saved to '%s'\n":                                  "[+] '%[2]s' 中的 %[1]d 个请求调用对应 %[3]d 个路径，已保存到 '%[4]s'\n",
+
+	"Export the requests found in the unpacked mini program as a HAR file for Burp, ZAP or Insomnia": "将解包小程序中发现的请求导出为 HAR 文件，可导入 Burp、ZAP 或 Insomnia",
+	"[+] %d requests of '%s' saved to '%s'\n":                                                        "[+] '%[2]s' 中的 %[1]d 个请求已保存到 '%[3]s'\n",
+	"the base url of the requests whose host is dynamic, like 'https://api.example.com'":             "主机地址为动态值的请求使用的基础地址，例如 'https://api.example.com'",
+
+	"Export the requests found in the unpacked mini program as a Postman collection grouped by the modules": "将解包小程序中发现的请求导出为按模块分组的 Postman 集合",
+	"[+] %d requests of '%s' in %d modules saved to '%s'\n":                                                 "[+] '%[2]s' 中 %[3]d 个模块的 %[1]d 个请求已保存到 '%[4]s'\n",
+
+	"Generate the shell script of the curl commands of the requests found in the unpacked mini program": "为解包小程序中发现的请求生成 curl 命令的 shell 脚本",
+	"[+] %d curl commands of '%s' saved to '%s'\n":                                                      "[+] '%[2]s' 的 %[1]d 条 curl 命令已保存到 '%[3]s'\n",
+
+	"invalid domain '%s' at line %d of '%s'":                                                                            "'%[3]s' 第 %[2]d 行的域名 '%[1]s' 无效",
+	"List the domains in the unpacked mini programs and report the ones not in the allowlist":                           "列出解包小程序中的域名，并报告不在白名单中的域名",
+	"[+] %d domains, %d not in the allowlist, saved to '%s'\n":                                                          "[+] 共 %d 个域名，其中 %d 个不在白名单中，已保存到 '%s'\n",
+	"[+] %d domains saved to '%s'\n":                                                                                    "[+] %d 个域名已保存到 '%s'\n",
+	"[+] %d domains not in the allowlist in %d unpacked folders\n":                                                      "[+] %[2]d 个解包目录中共有 %[1]d 个域名不在白名单中\n",
+	"the file of the approved domains, one per line, like 'api.example.com', 'wss://ws.example.com' or '*.example.com'": "已备案的合法域名文件，每行一个，例如 'api.example.com'、'wss://ws.example.com' 或 '*.example.com'",
+
+	"invalid map local format '%s', 'whistle' or 'mitmproxy' expected":                                         "无效的 map local 格式 '%s'，应为 'whistle' 或 'mitmproxy'",
+	"Generate the whistle or mitmproxy map local rules serving the unpacked files in place of the remote ones": "生成 whistle 或 mitmproxy 的 map local 规则，用解包出的本地文件替换远程文件",
+	"[+] %d map local rules of '%s' saved to '%s'\n":                                                           "[+] '%[2]s' 的 %[1]d 条 map local 规则已保存到 '%[3]s'\n",
+	"the format of the rules, 'whistle' or 'mitmproxy'":                                                        "规则的格式，'whistle' 或 'mitmproxy'",
+	"map all the unpacked files under the url prefix, like 'https://cdn.example.com/app'":                      "将所有解包文件映射到该地址前缀下，例如 'https://cdn.example.com/app'",
+
+	"Rebuild the wxapkg file from the modified unpacked folder, keeping the entry order and the layout of the original": "从修改后的解包目录重新打包 wxapkg 文件，保持原始文件的条目顺序和布局",
+	"[+] %d entries, %d changed, repacked to '%s' (%s)\n":                                                               "[+] 共 %d 个条目，%d 个已修改，已重新打包到 '%s'（%s）\n",
+	"%d files of the original package are missing in '%s': %s":                                                          "原始包中的 %[1]d 个文件在 '%[2]s' 中缺失：%[3]s",
+	"the modified unpacked folder":                                                                                      "修改后的解包目录",
+	"the repacked wxapkg file":                                                                                          "重新打包生成的 wxapkg 文件",
+	"the original wxapkg file whose entry order and layout are kept":                                                    "原始 wxapkg 文件，保持其条目顺序和布局",
+	"the wxid to decrypt the original wxapkg file, detected from the path by default":                                   "用于解密原始 wxapkg 文件的 wxid，默认从路径中识别",
+
+	"the wxid is required to encrypt the repacked file, set it by '--wxid' or '--plain' to skip the encryption": "加密重新打包的文件需要 wxid，请通过 '--wxid' 设置，或使用 '--plain' 跳过加密",
+	"[+] %d entries, %d changed, repacked and encrypted with the profile '%s' to '%s' (%s)\n":                   "[+] 共 %d 个条目，%d 个已修改，已重新打包并使用配置 '%s' 加密到 '%s'（%s）\n",
+	"write the decrypted wxapkg file even if the original is encrypted":                                         "即使原始文件是加密的，也输出解密的 wxapkg 文件",
+
+	"Check the environment, like the WeChat client, the applet folders, the permissions, the disk space and the decryption profile": "检查运行环境，包括 WeChat 客户端、小程序目录、权限、磁盘空间和解密配置",
+	"%d problems found, see the advice above": "发现 %d 个问题，请参考上面的建议",
+	"[+] no problem found\n":                  "[+] 没有发现问题\n",
+	"install the WeChat client, or copy the wxapkg files from another machine and unpack them with '-r'": "请安装 WeChat 客户端，或从其他机器复制 wxapkg 文件后使用 '-r' 解包",
+	"the WeChat client is not found on %s":                                                                   "在 %s 上没有找到 WeChat 客户端",
+	"the decryption profile is detected from the packages, set '--profile' if it fails":                      "将根据包文件自动识别解密配置，如果失败请设置 '--profile'",
+	"the WeChat client is found at '%s', but the version is unknown":                                         "在 '%s' 找到 WeChat 客户端，但版本未知",
+	"the WeChat client %s is found at '%s'":                                                                  "在 '%[2]s' 找到 WeChat 客户端 %[1]s",
+	"open a mini program in WeChat so that it's downloaded, or set the applet folder by '-r' when unpacking": "请在 WeChat 中打开小程序使其被下载，或在解包时通过 '-r' 指定小程序目录",
+	"no applet folder is found, the default one is '%s'":                                                     "没有找到小程序目录，默认目录为 '%s'",
+	"run as the user of WeChat, or grant the read permission of the folder":                                  "请以 WeChat 所属的用户运行，或授予该目录的读取权限",
+	"the applet folder '%s' can't be read: %v":                                                               "无法读取小程序目录 '%s'：%v",
+	"the applet folder '%s' of the account '%s' has %d mini programs":                                        "账号 '%[2]s' 的小程序目录 '%[1]s' 中有 %[3]d 个小程序",
+	"the applet folder '%s' has %d mini programs":                                                            "小程序目录 '%s' 中有 %d 个小程序",
+	"run as the user of WeChat, or close WeChat if the files are locked":                                     "请以 WeChat 所属的用户运行，如果文件被占用请关闭 WeChat",
+	"%d of %d wxapkg files can't be read":                                                                    "%[2]d 个 wxapkg 文件中有 %[1]d 个无法读取",
+	"all %d wxapkg files can be read":                                                                        "全部 %d 个 wxapkg 文件均可读取",
+	"choose another output path by '-o', or grant the write permission of the folder":                        "请通过 '-o' 选择其他输出路径，或授予该目录的写入权限",
+	"the output path '%s' can't be written: %v":                                                              "无法写入输出路径 '%s'：%v",
+	"the output path '%s' can be written":                                                                    "输出路径 '%s' 可写入",
+	"make sure the disk has enough space":                                                                    "请确保磁盘有足够的空间",
+	"the free space of '%s' is unknown: %v":                                                                  "无法获取 '%s' 的可用空间：%v",
+	"free up the disk, or choose another output path by '-o'":                                                "请清理磁盘，或通过 '-o' 选择其他输出路径",
+	"%s free on the disk of the output path, but about %s is needed to unpack all the mini programs":         "输出路径所在磁盘的可用空间为 %s，但解包全部小程序大约需要 %s",
+	"%s free on the disk of the output path":                                                                 "输出路径所在磁盘的可用空间为 %s",
+	"the format may be changed, try '--decryptor' or update wxapkg if the packages fail to decrypt":          "格式可能已变化，如果解密失败请尝试 '--decryptor' 或更新 wxapkg",
+	"no known decryption profile for the WeChat client %s":                                                   "WeChat 客户端 %s 没有已知的解密配置",
+	"the WeChat client %s uses the decryption profile '%s'":                                                  "WeChat 客户端 %s 使用解密配置 '%s'",
+	"set the profile by '--profile', or decrypt them with the external command by '--decryptor'":             "请通过 '--profile' 设置解密配置，或通过 '--decryptor' 使用外部命令解密",
+	"%d of %d sampled wxapkg files can't be decrypted with the known profiles, like '%s'":                    "抽样的 %[2]d 个 wxapkg 文件中有 %[1]d 个无法使用已知的解密配置解密，例如 '%[3]s'",
+	"%d sampled wxapkg files are decrypted with the profiles %s":                                             "抽样的 %d 个 wxapkg 文件已使用解密配置 %s 解密",
+
+	"    decrypt %s, parse %s, extract %s, beautify %s (summed over %d threads)\n":                                "    解密 %s，解析 %s，提取 %s，美化 %s（%d 个线程累计）\n",
+	"    beautifying takes most of the extraction, '--disable-beautify' or '--no-beautify' speeds it up\n":        "    美化占用了大部分提取时间，使用 '--disable-beautify' 或 '--no-beautify' 可以加快速度\n",
+	"    writing the files takes most of the extraction, try more threads by '-n'\n":                              "    写入文件占用了大部分提取时间，可以尝试通过 '-n' 增加线程数\n",
+	"print how long the decryption, the index parsing, the extraction and the beautifying took for every package": "打印每个包的解密、索引解析、提取和美化耗时",
+
+	"invalid write rate '%s', like '200/s' or '5MB/s' expected":                                                                                                   "无效的写入速率 '%s'，应为类似 '200/s' 或 '5MB/s' 的格式",
+	"[!] the output path is synced by %s, the writes are limited to %d files per second, set '--max-write-rate 0' to disable it\n":                                "[!] 输出路径由 %s 同步，写入速率限制为每秒 %d 个文件，设置 '--max-write-rate 0' 可取消限制\n",
+	"the maximum write rate like '200/s' for the files or '5MB/s' for the bytes per second, limited for the cloud-synced output path by default, 0 for unlimited": "最大写入速率，例如每秒文件数 '200/s' 或每秒字节数 '5MB/s'，输出到云同步目录时默认限速，0 表示不限制",
+
+	"%s: placeholder entry without a body, offset %d, size %d":            "%s：没有内容的占位条目，偏移 %d，大小 %d",
+	"[!] %d placeholder entries without a body in '%s' are skipped: %s\n": "[!] 已跳过 '%[2]s' 中 %[1]d 个没有内容的占位条目：%[3]s\n",
+
+	"decode the unicode escapes like '\\u4e2d' in the strings of the js and json files, so that the chinese text can be searched": "将 js 和 json 文件字符串中的 '\\u4e2d' 等 unicode 转义解码为字符，便于搜索中文文本",
+
+	"[+] %d asset references in the styles and the templates rewritten to the local files\n": "[+] 样式和模板中的 %d 个资源引用已改写为本地文件\n",
+	"rewrite the absolute and the remote references of the extracted assets in the raw wxss, css, wxml and html files, like url() and src, to the relative local paths, so that the images are rendered when they are opened locally": "将 raw 目录下 wxss、css、wxml、html 文件中指向已解包资源的绝对引用和远程引用（如 url() 和 src）改写为本地相对路径，使本地打开时能正常显示图片",
+
+	"[+] %d of %d pages previewed, the others have no wxml\n":                                                                           "[+] 已预览 %d/%d 个页面，其余页面没有 wxml\n",
+	"render the recovered wxml and wxss of every page to html and css under 'preview/', a rough preview for mapping the code to the ui": "将每个页面恢复出的 wxml 和 wxss 渲染为 'preview/' 下的 html 和 css，粗略预览页面，便于对照代码与界面",
+
+	"transform %d: %w": "第 %d 个转换：%w",
+	"transform %d: unknown type '%s', 'replace', 'strip-console' or 'redact-tokens' expected": "第 %d 个转换：未知类型 '%s'，应为 'replace'、'strip-console' 或 'redact-tokens'",
+	"json file with the transforms applied to the matching text files in order, like [{\"type\": \"strip-console\", \"files\": [\"*.js\"]}], the types are 'replace' with 'pattern' and 'replacement', 'strip-console' and 'redact-tokens'": "按顺序对匹配的文本文件应用转换的 json 文件，例如 [{\"type\": \"strip-console\", \"files\": [\"*.js\"]}]，类型为 'replace'（配合 'pattern' 和 'replacement'）、'strip-console' 和 'redact-tokens'",
+
+	"[!] %d modified files were beautified, normalized or transformed when unpacking, they are packed as they are: %s\n": "[!] %d 个修改过的文件在解包时被美化、规范化或转换过，将按现状打包：%s\n",
+
+	"[!] failed to unpack '%s': %v\n":                                "[!] 解包 '%s' 失败：%v\n",
+	"%d packages and %d files failed to unpack, %d packages skipped": "%d 个包和 %d 个文件解包失败，跳过了 %d 个包",
+
+	"hard link the identical files unpacked in the same run, like the shared libraries and fonts, editing a linked file changes all its copies": "将同一次解包中内容相同的文件（例如共用的第三方库、字体）硬链接，注意修改其中一个文件会同时修改它的所有副本",
+
+	"[!] subpackage '%s' is not downloaded, %d routes are unavailable: %s\n":        "[!] 分包 '%s' 未下载，%d 个路由不可用：%s\n",
+	"[+] %d modules restored from the bundles in '%s'\n":                            "[+] 从 '%[2]s' 的打包文件中还原了 %[1]d 个模块\n",
+	"[+] %d components restored in '%s'\n":                                          "[+] 在 '%[2]s' 中还原了 %[1]d 个组件\n",
+	"[+] 'app.json' and %d page configs restored in '%s'\n":                         "[+] 在 '%[2]s' 中还原了 'app.json' 和 %[1]d 个页面配置\n",
+	"[!] plugin '%s' (%s) is not unpacked, the references to it are not resolved\n": "[!] 插件 '%s'（%s）未解包，无法解析对它的引用\n",
+	"[+] plugin '%s' copied from '%s' to '%s'\n":                                    "[+] 插件 '%s' 已从 '%s' 复制到 '%s'\n",
+	"[+] %d project files created in '%s'\n":                                        "[+] 在 '%[2]s' 中创建了 %[1]d 个项目文件\n",
+	"[+] independent subpackage '%s' restored as the project '%s'\n":                "[+] 独立分包 '%s' 已还原为项目 '%s'\n",
+	"[+] open '%s' in wechat devtools\n":                                            "[+] 在微信开发者工具中打开 '%s'\n",
+	"unknown routes format '%s', only 'json', 'md' and 'mermaid' are supported":     "未知的路由格式 '%s'，仅支持 'json'、'md' 和 'mermaid'",
+	"[+] %d pages, %d tabs, %d subpackages, %d navigations saved to '%s'\n":         "[+] %d 个页面、%d 个标签、%d 个分包、%d 个跳转已保存到 '%s'\n",
+	"  - apis: %s\n":               "  - 接口：%s\n",
+	"  - urls: %s\n":               "  - 链接：%s\n",
+	"[+] %d pages saved to '%s'\n": "[+] %d 个页面已保存到 '%s'\n",
+	"unknown graph format '%s', only 'dot' and 'json' are supported": "未知的图格式 '%s'，仅支持 'dot' 和 'json'",
+	"[+] %d modules, %d dependencies saved to '%s'\n":                "[+] %d 个模块、%d 个依赖已保存到 '%s'\n",
+	"  - extAppid: %s\n":                        "  - 第三方平台 appid：%s\n",
+	"  - %s  %-6s %6d files (%+d)  %10s (%s)\n": "  - %s  %-6s %6d 个文件 (%+d)  %10s (%s)\n",
+
+	"%d versions are found in '%s': %s, select one with '--version-filter' to unpack in the flat layout": "在 '%[2]s' 中找到 %[1]d 个版本：%[3]s，flat 布局需要使用 '--version-filter' 选择一个版本",
+	"'merged' merges the packages of a version into one folder, 'package' saves every package to the folder named after its role like 'main/' and 'sub_pages_shop/', 'flat' merges the packages of the only version, or the one selected by '--version-filter', into the output path directly": "'merged' 将同一版本的所有包合并到一个目录，'package' 将每个包保存到以其角色命名的目录，如 'main/'、'sub_pages_shop/'，'flat' 将唯一版本或 '--version-filter' 选择的版本的所有包直接合并到输出目录",
+
+	"invalid archive member name '%s'": "无效的压缩包成员名称 '%s'",
+
+	"the free disk space is not supported on this platform": "当前平台不支持获取磁盘剩余空间",
+
+	"json file with the sha256 hashes of the library builds, like {\"<sha256>\": \"<library>\"}, the libraries are only detected by the paths and the banners without it": "第三方库构建文件的 sha256 哈希 json 文件，格式为 {\"<sha256>\": \"<library>\"}，未指定时只根据路径和版权横幅识别第三方库",
+
+	"Serve the unpacked mini programs as a html site with the rough previews of the pages": "将已解包的小程序作为 html 站点提供访问，并附带页面的粗略预览",
+	"[+] serving the site at 'http://%s/', press Ctrl+C to stop\n":                         "[+] 站点已在 'http://%s/' 提供访问，按 Ctrl+C 停止\n",
+	"the address to listen on":                      "监听的地址",
+	"do not render the rough previews of the pages": "不渲染页面的粗略预览",
+
+	// scan
+	"Name":        "名称",
+	"Developer":   "开发者",
+	"Description": "描述",
+	"unpack":      "解包",
+	"move up":     "上移",
+	"move down":   "下移",
+	"exit":        "退出",
+}
+
+// T translates the message to the current language, the message itself is returned if not translated.
+func T(message string) string {
+	if Lang == "zh" {
+		if translated, ok := zhMessages[message]; ok {
+			return translated
+		}
+	}
+
+	return message
+}
@@ -0,0 +1,183 @@
+package util
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// IndexName is the file name of the full-text index saved in the unpacked path, see BuildIndex.
+const IndexName = ".wxapkg-index.json.gz"
+
+// the terms shorter or longer are not indexed
+const (
+	minTermLength = 2
+	maxTermLength = 64
+)
+
+// IndexLocation is where an indexed file is unpacked.
+type IndexLocation struct {
+	Wxid string `json:"wxid"`
+	Dir  string `json:"dir"`  // the unpacked folder relative to the unpacked path
+	Path string `json:"path"` // the slash path relative to the unpacked folder
+}
+
+// IndexDoc is a file content in the index, the identical files in the apps and the versions are indexed once.
+type IndexDoc struct {
+	Sha256    string          `json:"sha256"`
+	Locations []IndexLocation `json:"locations"`
+}
+
+// Index is the inverted index of the text files in the unpacked folders, the terms are the lower case
+// words, like the parts of the urls and the identifiers.
+type Index struct {
+	Docs  []IndexDoc       `json:"docs"`
+	Terms map[string][]int `json:"terms"` // term -> the sorted doc ids
+}
+
+// BuildIndex indexes the text files of all the unpacked folders in root.
+func BuildIndex(root string) (*Index, error) {
+	dirs, err := FindUnpackedDirs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var index = &Index{Terms: make(map[string][]int)}
+	var docs = make(map[string]int) // sha256 -> doc id
+	for _, dir := range dirs {
+		manifest, err := ReadManifest(dir)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, f := range manifest.Files {
+			var location = IndexLocation{Wxid: manifest.Wxid, Dir: filepath.ToSlash(rel), Path: f.Path}
+			if id, ok := docs[f.Sha256]; ok && f.Sha256 != "" {
+				index.Docs[id].Locations = append(index.Docs[id].Locations, location)
+				continue
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+			if err != nil {
+				return nil, err
+			}
+			if !IsTextFile(f.Path, data) {
+				continue
+			}
+
+			var id = len(index.Docs)
+			docs[f.Sha256] = id
+			index.Docs = append(index.Docs, IndexDoc{Sha256: f.Sha256, Locations: []IndexLocation{location}})
+			for _, term := range IndexTerms(string(data)) {
+				index.Terms[term] = append(index.Terms[term], id)
+			}
+		}
+	}
+
+	return index, nil
+}
+
+// IndexTerms returns the unique lower case terms of the text.
+func IndexTerms(text string) []string {
+	var seen = make(map[string]bool)
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) {
+		if len(word) < minTermLength || len(word) > maxTermLength || seen[word] {
+			continue
+		}
+		seen[word] = true
+		terms = append(terms, word)
+	}
+
+	return terms
+}
+
+// Save saves the index to the unpacked path root.
+func (x *Index) Save(root string) error {
+	var buf bytes.Buffer
+	var w = gzip.NewWriter(&buf)
+	if err := json.NewEncoder(w).Encode(x); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(root, IndexName), buf.Bytes(), 0600)
+}
+
+// ReadIndex loads the index saved in the unpacked path root.
+func ReadIndex(root string) (*Index, error) {
+	f, err := os.Open(filepath.Join(root, IndexName))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+
+	var x Index
+	if err := json.NewDecoder(r).Decode(&x); err != nil {
+		return nil, err
+	}
+
+	return &x, nil
+}
+
+// Candidates returns the docs containing all the terms of the query, the query without any term matches
+// all the docs. The docs must be checked for the exact query.
+func (x *Index) Candidates(query string) []IndexDoc {
+	var ids []int
+	var terms = IndexTerms(query)
+	if len(terms) == 0 {
+		ids = make([]int, len(x.Docs))
+		for i := range ids {
+			ids[i] = i
+		}
+	}
+
+	// intersect from the rarest term
+	sort.Slice(terms, func(i, j int) bool {
+		return len(x.Terms[terms[i]]) < len(x.Terms[terms[j]])
+	})
+	for i, term := range terms {
+		var postings = x.Terms[term]
+		if i == 0 {
+			ids = append(ids, postings...)
+			continue
+		}
+
+		var kept = ids[:0]
+		var j = 0
+		for _, id := range ids {
+			for j < len(postings) && postings[j] < id {
+				j++
+			}
+			if j < len(postings) && postings[j] == id {
+				kept = append(kept, id)
+			}
+		}
+		ids = kept
+	}
+
+	var result = make([]IndexDoc, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, x.Docs[id])
+	}
+
+	return result
+}
@@ -0,0 +1,92 @@
+package util
+
+import (
+	"path"
+	"regexp"
+	"sort"
+)
+
+var regBlockComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+var regLicenseHint = regexp.MustCompile(`(?i)licen[cs]e|copyright|\(c\)`)
+
+var licensePatterns = []struct {
+	id  string
+	reg *regexp.Regexp
+}{
+	{"MIT", regexp.MustCompile(`(?i)\bMIT\b`)},
+	{"ISC", regexp.MustCompile(`\bISC\b`)},
+	{"Apache-2.0", regexp.MustCompile(`(?i)\bApache(-2\.0|\s+License,?\s+Version\s+2\.0)`)},
+	{"BSD-2-Clause", regexp.MustCompile(`(?i)\bBSD-2-Clause\b`)},
+	{"BSD-3-Clause", regexp.MustCompile(`(?i)\bBSD-3-Clause\b|\bBSD\s+license\b`)},
+	{"GPL-2.0", regexp.MustCompile(`(?i)\bGPL-?v?2(\.0)?\b|GNU General Public License,?\s+version 2`)},
+	{"GPL-3.0", regexp.MustCompile(`(?i)\bGPL-?v?3(\.0)?\b|GNU General Public License,?\s+version 3`)},
+	{"LGPL", regexp.MustCompile(`(?i)\bLGPL\b|GNU Lesser General Public License`)},
+	{"MPL-2.0", regexp.MustCompile(`(?i)\bMPL-2\.0\b|Mozilla Public License,?\s+v(ersion)?\.?\s*2\.0`)},
+}
+
+// the license of the known libraries, used when the banner is stripped by the compiler
+var libraryLicenses = map[string]string{
+	"weui":   "MIT",
+	"vant":   "MIT",
+	"lodash": "MIT",
+	"dayjs":  "MIT",
+}
+
+// DetectLicenses finds the licenses declared in the comment headers of js/wxss code,
+// the license of the known library is used as a fallback.
+func DetectLicenses(library string, data []byte) []string {
+	var found = make(map[string]bool)
+	for _, comment := range regBlockComment.FindAll(data, -1) {
+		if !regLicenseHint.Match(comment) {
+			continue
+		}
+		for _, p := range licensePatterns {
+			if p.reg.Match(comment) {
+				found[p.id] = true
+			}
+		}
+	}
+
+	if len(found) == 0 && libraryLicenses[library] != "" {
+		found[libraryLicenses[library]] = true
+	}
+
+	var result = make([]string, 0, len(found))
+	for id := range found {
+		result = append(result, id)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+type LicenseReport struct {
+	Wxid      string              `json:"wxid"`
+	Licenses  map[string][]string `json:"licenses"`  // license -> files
+	Libraries map[string][]string `json:"libraries"` // library -> licenses
+}
+
+// NewLicenseReport summarizes the licenses recorded in the manifest.
+func NewLicenseReport(m *Manifest) *LicenseReport {
+	var report = &LicenseReport{
+		Wxid:      m.Wxid,
+		Licenses:  make(map[string][]string),
+		Libraries: make(map[string][]string),
+	}
+
+	for _, f := range m.Files {
+		for _, license := range f.Licenses {
+			report.Licenses[license] = append(report.Licenses[license], f.Name)
+		}
+		if f.Library != "" && report.Libraries[f.Library] == nil {
+			report.Libraries[f.Library] = f.Licenses
+		}
+	}
+
+	return report
+}
+
+// Save saves the report to the folder dir in the output.
+func (r *LicenseReport) Save(out Output, dir string) error {
+	return WriteJson(out, path.Join(dir, "licenses.json"), r)
+}
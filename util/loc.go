@@ -0,0 +1,124 @@
+package util
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// the comment syntax of a language, the empty markers are not supported by the language
+type commentSyntax struct {
+	line       string
+	blockStart string
+	blockEnd   string
+}
+
+type language struct {
+	name   string
+	syntax commentSyntax
+}
+
+var (
+	cComments      = commentSyntax{line: "//", blockStart: "/*", blockEnd: "*/"}
+	cssComments    = commentSyntax{blockStart: "/*", blockEnd: "*/"}
+	markupComments = commentSyntax{blockStart: "<!--", blockEnd: "-->"}
+)
+
+// the languages of the extensions counted by CountLines
+var languages = map[string]language{
+	".js":   {"JavaScript", cComments},
+	".mjs":  {"JavaScript", cComments},
+	".ts":   {"TypeScript", cComments},
+	".tsx":  {"TypeScript", cComments},
+	".jsx":  {"JavaScript", cComments},
+	".vue":  {"Vue", markupComments},
+	".wxs":  {"WXS", cComments},
+	".wxml": {"WXML", markupComments},
+	".wxss": {"WXSS", cssComments},
+	".css":  {"CSS", cssComments},
+	".less": {"Less", cComments},
+	".scss": {"SCSS", cComments},
+	".html": {"HTML", markupComments},
+	".xml":  {"XML", markupComments},
+	".svg":  {"SVG", markupComments},
+	".json": {"JSON", commentSyntax{}},
+	".md":   {"Markdown", commentSyntax{}},
+}
+
+// LineCount is the lines of the code in a language.
+type LineCount struct {
+	Language string `json:"language"`
+	Files    int    `json:"files"`
+	Blank    int    `json:"blank"`
+	Comment  int    `json:"comment"`
+	Code     int    `json:"code"`
+}
+
+// Add adds the lines of other.
+func (c *LineCount) Add(other LineCount) {
+	c.Files += other.Files
+	c.Blank += other.Blank
+	c.Comment += other.Comment
+	c.Code += other.Code
+}
+
+// CountLines counts the blank, comment and code lines of the file like cloc, the line with both the code and
+// the comment is a code line. It reports false if the language of the file is unknown. The comment markers in
+// the strings are not recognized, the result is an estimate.
+func CountLines(name string, data []byte) (LineCount, bool) {
+	lang, ok := languages[strings.ToLower(filepath.Ext(name))]
+	if !ok {
+		return LineCount{}, false
+	}
+
+	var count = LineCount{Language: lang.name, Files: 1}
+	var syntax = lang.syntax
+	var inBlock = false
+	for _, line := range bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) {
+		var t = strings.TrimSpace(string(line))
+		if t == "" {
+			count.Blank++
+			continue
+		}
+
+		var code = false
+		for t != "" {
+			if inBlock {
+				var end = strings.Index(t, syntax.blockEnd)
+				if end < 0 {
+					break
+				}
+				t = strings.TrimSpace(t[end+len(syntax.blockEnd):])
+				inBlock = false
+				continue
+			}
+			if syntax.line != "" && strings.HasPrefix(t, syntax.line) {
+				break
+			}
+			if syntax.blockStart != "" && strings.HasPrefix(t, syntax.blockStart) {
+				t = t[len(syntax.blockStart):]
+				inBlock = true
+				continue
+			}
+
+			// the code, maybe followed by a block comment
+			code = true
+			if syntax.blockStart == "" {
+				break
+			}
+			var start = strings.Index(t, syntax.blockStart)
+			if start < 0 {
+				break
+			}
+			t = t[start:]
+		}
+
+		if code {
+			count.Code++
+		} else {
+			count.Comment++
+		}
+	}
+
+	return count, true
+}
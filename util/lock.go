@@ -0,0 +1,69 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// LockName is the lock file in the output path, see LockDir.
+const LockName = ".wxapkg.lock"
+
+// ErrLocked is returned when the lock is taken by another process.
+var ErrLocked = errors.New("locked by another process")
+
+// DirLock is the lock of a folder, so that two processes don't write to the same folder at the same time.
+type DirLock struct {
+	f    *os.File
+	once sync.Once
+}
+
+// LockDir takes the lock of the folder without waiting, ErrLocked is returned with the pid of the owner if
+// it's taken by another process. The lock is released by the system if the process exits without Unlock.
+func LockDir(dir string) (*DirLock, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	var name = filepath.Join(dir, LockName)
+	f, err := openLockFile(name)
+	if err == nil {
+		err = lockFile(f)
+	}
+	if errors.Is(err, ErrLocked) {
+		if f != nil {
+			_ = f.Close()
+		}
+		if pid, _ := os.ReadFile(name); len(pid) > 0 {
+			return nil, fmt.Errorf("%w (pid %s)", ErrLocked, strings.TrimSpace(string(pid)))
+		}
+		return nil, ErrLocked
+	}
+	if err != nil {
+		if f != nil {
+			_ = f.Close()
+		}
+		return nil, err
+	}
+
+	// the pid is for the messages only
+	_ = f.Truncate(0)
+	_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+
+	return &DirLock{f: f}, nil
+}
+
+// Unlock releases the lock, the lock file is kept because another process may be waiting on it. It's safe
+// to call Unlock more than once.
+func (l *DirLock) Unlock() error {
+	var err error
+	l.once.Do(func() {
+		err = l.f.Close()
+	})
+
+	return err
+}
@@ -0,0 +1,14 @@
+//go:build !windows && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+
+package util
+
+import "os"
+
+func openLockFile(name string) (*os.File, error) {
+	return os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0600)
+}
+
+// lockFile does nothing, the folders are not locked on this platform.
+func lockFile(*os.File) error {
+	return nil
+}
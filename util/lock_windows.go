@@ -0,0 +1,29 @@
+package util
+
+import (
+	"os"
+	"syscall"
+)
+
+// openLockFile opens the lock file without sharing, so that it's locked until closed.
+func openLockFile(name string) (*os.File, error) {
+	path, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+
+	handle, err := syscall.CreateFile(path, syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0,
+		nil, syscall.OPEN_ALWAYS, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if IsLockedError(err) {
+		return nil, ErrLocked
+	}
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return os.NewFile(uintptr(handle), name), nil
+}
+
+func lockFile(*os.File) error {
+	return nil
+}
@@ -0,0 +1,111 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// ManifestName is the file name of the manifest saved in every unpacked folder.
+const ManifestName = "manifest.json"
+
+type ManifestFile struct {
+	Name       string   `json:"name"`
+	Original   string   `json:"original_name,omitempty"` // the name in the package if it's changed by the unicode normalization
+	Path       string   `json:"path"`                    // the saved path relative to the unpacked folder
+	Package    string   `json:"package"`
+	Size       uint32   `json:"size"`
+	Sha256     string   `json:"sha256"` // the checksum of the saved file
+	Library    string   `json:"library,omitempty"`
+	Licenses   []string `json:"licenses,omitempty"`
+	Charset    string   `json:"charset,omitempty"`    // the original charset of the text not in UTF-8
+	Transcoded bool     `json:"transcoded,omitempty"` // the text is transcoded from the charset to UTF-8
+	Bom        bool     `json:"bom,omitempty"`        // the original text has the UTF-8 BOM
+	Source     string   `json:"source,omitempty"`     // the bundle the original source or the source map is extracted from, see SourceMap
+
+	Reformatted []string `json:"reformatted,omitempty"` // the changes like ReformatBeautify which can't be undone
+	Transforms  []string `json:"transforms,omitempty"`  // the transforms which changed the file, see Transform
+}
+
+// the changes of the unpacked files which can't be undone when repacking
+const (
+	ReformatBeautify    = "beautify"
+	ReformatEol         = "eol"
+	ReformatUnescape    = "unescape-unicode"
+	ReformatExtractMaps = "extract-maps"
+)
+
+// Restorable reports whether the original content can be restored from the unpacked file, it's not
+// reformatted or transformed.
+func (f ManifestFile) Restorable() bool {
+	return len(f.Reformatted) == 0 && len(f.Transforms) == 0
+}
+
+// PackageStats is the statistics of the files unpacked from a wxapkg file.
+type PackageStats struct {
+	Package    string         `json:"package"`
+	Files      int            `json:"files"`
+	Size       int64          `json:"size"`
+	Extensions map[string]int `json:"extensions"`
+}
+
+func NewPackageStats(pkg string, files []ManifestFile) PackageStats {
+	var stats = PackageStats{Package: pkg, Files: len(files), Extensions: make(map[string]int)}
+	for _, f := range files {
+		stats.Size += int64(f.Size)
+		stats.Extensions[filepath.Ext(f.Name)]++
+	}
+
+	return stats
+}
+
+type Manifest struct {
+	Wxid     string         `json:"wxid"`
+	Time     time.Time      `json:"time"` // the latest modification time of the wxapkg files, when wechat downloaded them
+	Packages []PackageStats `json:"packages"`
+	Files    []ManifestFile `json:"files"`
+}
+
+// Save saves the manifest to the folder dir in the output.
+func (m *Manifest) Save(out Output, dir string) error {
+	return WriteJson(out, path.Join(dir, ManifestName), m)
+}
+
+// SaveJson saves v as indented json, the parent folders are created if not exist.
+func SaveJson(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+// Sha256 returns the hex encoded sha256 checksum of the data.
+func Sha256(data []byte) string {
+	var sum = sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ReadManifest loads the manifest saved in the unpacked folder dir.
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
@@ -0,0 +1,61 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// the formats of the map local rules
+const (
+	MapLocalWhistle   = "whistle"
+	MapLocalMitmproxy = "mitmproxy"
+)
+
+// MapLocalRule serves the local file in place of the remote url via the proxy.
+type MapLocalRule struct {
+	Url  string `json:"url"`
+	Path string `json:"path"` // the absolute path of the local file
+}
+
+// SortMapLocalRules sorts the rules by the urls.
+func SortMapLocalRules(rules []MapLocalRule) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Url < rules[j].Url
+	})
+}
+
+// FormatMapLocalRules formats the rules as the whistle rules or the mitmproxy config, the urls with the
+// queries or not are all matched.
+func FormatMapLocalRules(rules []MapLocalRule, format string) ([]byte, error) {
+	var b strings.Builder
+	switch format {
+	case MapLocalWhistle:
+		b.WriteString("# the whistle rules serving the unpacked files, paste them to the rules of whistle\n")
+		for _, r := range rules {
+			b.WriteString(fmt.Sprintf("%s file://%s\n", r.Url, whistlePath(r.Path)))
+		}
+	case MapLocalMitmproxy:
+		b.WriteString("# the mitmproxy options serving the unpacked files, run 'mitmproxy --set confdir=<the folder of this file>'\n")
+		b.WriteString("map_local:\n")
+		for _, r := range rules {
+			var rule = "|" + regexp.QuoteMeta(r.Url) + `(\?.*)?$|` + r.Path
+			b.WriteString("  - '" + strings.ReplaceAll(rule, "'", "''") + "'\n")
+		}
+	default:
+		return nil, fmt.Errorf(T("invalid map local format '%s', 'whistle' or 'mitmproxy' expected"), format)
+	}
+
+	return []byte(b.String()), nil
+}
+
+// whistlePath converts the path for the whistle file protocol, the windows paths use the slashes.
+func whistlePath(path string) string {
+	path = strings.ReplaceAll(path, `\`, "/")
+	if strings.ContainsAny(path, " ()") {
+		return "(" + path + ")"
+	}
+
+	return path
+}
@@ -0,0 +1,84 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// MarkerName is the file name of the completion marker saved in the unpacked folder, after all the
+// packages of the folder are unpacked without errors.
+const MarkerName = ".wxapkg-done"
+
+// Marker records the wxapkg files unpacked to the folder, the folder is skipped if they are not changed.
+type Marker struct {
+	Packages map[string]string `json:"packages"` // package -> sha256 of the wxapkg file
+}
+
+// NewMarker returns the marker of the wxapkg files, package name -> file path.
+func NewMarker(packages map[string]string) (*Marker, error) {
+	var m = &Marker{Packages: make(map[string]string, len(packages))}
+	for pkg, file := range packages {
+		sum, err := Sha256File(file)
+		if err != nil {
+			return nil, err
+		}
+		m.Packages[pkg] = sum
+	}
+
+	return m, nil
+}
+
+// ReadMarker loads the marker saved in the unpacked folder dir.
+func ReadMarker(dir string) (*Marker, error) {
+	data, err := os.ReadFile(filepath.Join(dir, MarkerName))
+	if err != nil {
+		return nil, err
+	}
+
+	var m Marker
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// Save saves the marker to the folder dir in the output.
+func (m *Marker) Save(out Output, dir string) error {
+	return WriteJson(out, path.Join(dir, MarkerName), m)
+}
+
+// Equal reports whether the markers record the same wxapkg files.
+func (m *Marker) Equal(other *Marker) bool {
+	if len(m.Packages) != len(other.Packages) {
+		return false
+	}
+	for pkg, sum := range m.Packages {
+		if other.Packages[pkg] != sum {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Sha256File returns the hex encoded sha256 checksum of the file, which may be held by the running wechat.
+func Sha256File(name string) (string, error) {
+	f, err := OpenSharedFile(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var h = sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// OpenFolder reveals the folder in the file manager of the platform.
+func OpenFolder(dir string) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("explorer", dir)
+	case "darwin":
+		cmd = exec.Command("open", dir)
+	default:
+		cmd = exec.Command("xdg-open", dir)
+	}
+
+	// explorer exits with 1 even if it succeeds
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() { _ = cmd.Wait() }()
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package util
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// OpenApi is the draft OpenAPI 3 document of the requests found in the code, see FindRequests.
+type OpenApi struct {
+	OpenApi string                                  `json:"openapi"`
+	Info    OpenApiInfo                             `json:"info"`
+	Servers []OpenApiServer                         `json:"servers,omitempty"`
+	Paths   map[string]map[string]*OpenApiOperation `json:"paths"` // path -> method -> operation
+}
+
+type OpenApiInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenApiServer struct {
+	Url       string                     `json:"url"`
+	Variables map[string]OpenApiVariable `json:"variables,omitempty"`
+}
+
+type OpenApiVariable struct {
+	Default string `json:"default"`
+}
+
+type OpenApiOperation struct {
+	Parameters  []OpenApiParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenApiRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenApiResponse `json:"responses"`
+	Sources     []string                   `json:"x-wxapkg-sources"` // the call sites like 'pages/index/index.js:12'
+}
+
+type OpenApiParameter struct {
+	Name     string        `json:"name"`
+	In       string        `json:"in"` // path, query or header
+	Required bool          `json:"required,omitempty"`
+	Example  string        `json:"example,omitempty"`
+	Schema   OpenApiSchema `json:"schema"`
+}
+
+type OpenApiSchema struct {
+	Type       string                   `json:"type,omitempty"`
+	Properties map[string]OpenApiSchema `json:"properties,omitempty"`
+}
+
+type OpenApiRequestBody struct {
+	Content map[string]OpenApiMediaType `json:"content"` // content type -> schema
+}
+
+type OpenApiMediaType struct {
+	Schema OpenApiSchema `json:"schema"`
+}
+
+type OpenApiResponse struct {
+	Description string `json:"description"`
+}
+
+// NewOpenApi returns the empty document.
+func NewOpenApi(title, version, description string) *OpenApi {
+	return &OpenApi{
+		OpenApi: "3.0.3",
+		Info:    OpenApiInfo{Title: title, Version: version, Description: description},
+		Paths:   make(map[string]map[string]*OpenApiOperation),
+	}
+}
+
+// SplitRequestUrl splits the url template of the request to the server, the path and the query, the server is
+// like 'https://host' or the template of the base url like '{baseUrl}'.
+func SplitRequestUrl(u string) (server, path, query string) {
+	if i := strings.Index(u, "#"); i >= 0 {
+		u = u[:i]
+	}
+	if i := strings.Index(u, "?"); i >= 0 {
+		u, query = u[:i], u[i+1:]
+	}
+
+	if i := strings.Index(u, "://"); i >= 0 && !strings.Contains(u[:i], "/") {
+		var end = strings.IndexByte(u[i+3:], '/')
+		if end < 0 {
+			return u, "/", query
+		}
+		return u[:i+3+end], u[i+3+end:], query
+	}
+	if strings.HasPrefix(u, "{") {
+		if end := strings.IndexByte(u, '}'); end > 0 && !strings.HasPrefix(u[end+1:], "}") {
+			server, u = u[:end+1], u[end+1:]
+		}
+	}
+	if !strings.HasPrefix(u, "/") {
+		u = "/" + u
+	}
+
+	return server, u, query
+}
+
+// AddRequest adds the request call to the document, source is the location of the call.
+func (a *OpenApi) AddRequest(call RequestCall, source string) {
+	server, path, query := SplitRequestUrl(call.Url)
+	if server != "" {
+		a.addServer(server)
+	}
+
+	var method = strings.ToLower(call.Method)
+	if a.Paths[path] == nil {
+		a.Paths[path] = make(map[string]*OpenApiOperation)
+	}
+	var op = a.Paths[path][method]
+	if op == nil {
+		op = &OpenApiOperation{Responses: map[string]OpenApiResponse{"200": {Description: "OK"}}}
+		a.Paths[path][method] = op
+	}
+	op.Sources = append(op.Sources, source)
+
+	for _, name := range TemplateParams(path) {
+		op.addParameter(OpenApiParameter{Name: name, In: "path", Required: true, Schema: OpenApiSchema{Type: "string"}})
+	}
+	if values, err := url.ParseQuery(query); err == nil {
+		for name, v := range values {
+			op.addParameter(OpenApiParameter{Name: name, In: "query", Example: v[0], Schema: OpenApiSchema{Type: "string"}})
+		}
+	}
+
+	var contentType = "application/json"
+	for name, value := range call.Header {
+		if strings.EqualFold(name, "content-type") {
+			if !strings.Contains(value, "{") {
+				contentType = value
+			}
+			continue
+		}
+		op.addParameter(OpenApiParameter{Name: name, In: "header", Example: value, Schema: OpenApiSchema{Type: "string"}})
+	}
+	if call.Api == "uploadFile" {
+		contentType = "multipart/form-data"
+	}
+
+	if len(call.Data) == 0 {
+		return
+	}
+	if method == "get" || method == "delete" || method == "head" {
+		for name, t := range call.Data {
+			op.addParameter(OpenApiParameter{Name: name, In: "query", Schema: OpenApiSchema{Type: t}})
+		}
+		return
+	}
+	if op.RequestBody == nil {
+		op.RequestBody = &OpenApiRequestBody{Content: make(map[string]OpenApiMediaType)}
+	}
+	var schema = op.RequestBody.Content[contentType].Schema
+	if schema.Properties == nil {
+		schema = OpenApiSchema{Type: "object", Properties: make(map[string]OpenApiSchema)}
+	}
+	for name, t := range call.Data {
+		schema.Properties[name] = OpenApiSchema{Type: t}
+	}
+	op.RequestBody.Content[contentType] = OpenApiMediaType{Schema: schema}
+}
+
+func (a *OpenApi) addServer(u string) {
+	for _, s := range a.Servers {
+		if s.Url == u {
+			return
+		}
+	}
+
+	var server = OpenApiServer{Url: u}
+	for _, name := range TemplateParams(u) {
+		if server.Variables == nil {
+			server.Variables = make(map[string]OpenApiVariable)
+		}
+		server.Variables[name] = OpenApiVariable{Default: name}
+	}
+	a.Servers = append(a.Servers, server)
+	sort.Slice(a.Servers, func(i, j int) bool {
+		return a.Servers[i].Url < a.Servers[j].Url
+	})
+}
+
+var parameterOrder = map[string]int{"path": 0, "query": 1, "header": 2}
+
+// addParameter adds the parameter unless it's added already.
+func (op *OpenApiOperation) addParameter(p OpenApiParameter) {
+	for _, old := range op.Parameters {
+		if old.Name == p.Name && old.In == p.In {
+			return
+		}
+	}
+
+	op.Parameters = append(op.Parameters, p)
+	sort.SliceStable(op.Parameters, func(i, j int) bool {
+		if op.Parameters[i].In != op.Parameters[j].In {
+			return parameterOrder[op.Parameters[i].In] < parameterOrder[op.Parameters[j].In]
+		}
+		return op.Parameters[i].Name < op.Parameters[j].Name
+	})
+}
@@ -0,0 +1,100 @@
+package util
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Output is where the unpacked files are saved, a local folder or a remote storage.
+type Output interface {
+	// WriteFile saves the file, the name is the slash path relative to the output.
+	WriteFile(name string, data []byte) error
+	// String returns the location of the output to show.
+	String() string
+}
+
+// DirOutput saves the files to the local folder.
+type DirOutput string
+
+func (d DirOutput) WriteFile(name string, data []byte) error {
+	var path = filepath.Join(string(d), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	// never write through the links to the other files, see LinkVersions, LinkDuplicates and StoreContent
+	if info, err := os.Lstat(path); err == nil && !info.IsDir() {
+		if err := os.Remove(path); err != nil {
+			// the read-only files like the links to the store can't be removed on windows
+			if info.Mode().Perm()&0200 != 0 || os.Chmod(path, 0600) != nil {
+				return err
+			}
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+func (d DirOutput) String() string {
+	return string(d)
+}
+
+// NewRunId returns the random id of the run, like '3f9a0c1e'.
+func NewRunId() string {
+	var b = make([]byte, 4)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// ExpandOutputPath replaces the placeholders in the output path, '{date}' like '2024-01-02', '{time}' like
+// '150405' and '{run-id}' the id of the run, so that the scheduled runs land in their own folders.
+func ExpandOutputPath(path string, t time.Time, runId string) string {
+	return strings.NewReplacer(
+		"{date}", t.Format("2006-01-02"),
+		"{time}", t.Format("150405"),
+		"{run-id}", runId,
+	).Replace(path)
+}
+
+// NewOutput returns the output of the destination, a local folder or an url like 's3://bucket/prefix'
+// and 'webdav://host/path', see NewObjectStorage and NewWebDAV.
+func NewOutput(dest string) (Output, error) {
+	if !strings.Contains(dest, "://") {
+		return DirOutput(dest), nil
+	}
+
+	u, err := url.Parse(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "s3", "oss", "cos":
+		return NewObjectStorage(u)
+	case "webdav", "webdavs":
+		return NewWebDAV(u)
+	default:
+		return nil, fmt.Errorf(T("unsupported destination '%s'"), dest)
+	}
+}
+
+// WriteJson saves v as indented json to the output.
+func WriteJson(out Output, name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return out.WriteFile(name, data)
+}
@@ -0,0 +1,185 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PostmanCollection is the Postman collection v2.1 of the requests found in the code, the requests are grouped
+// in the folders of the modules, and the dynamic parts like the host and the tokens are the collection
+// variables. See FindRequests.
+type PostmanCollection struct {
+	Info     PostmanInfo       `json:"info"`
+	Item     []*PostmanFolder  `json:"item"`
+	Variable []PostmanVariable `json:"variable"`
+
+	folders   map[string]*PostmanFolder
+	variables map[string]bool
+}
+
+type PostmanInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+type PostmanFolder struct {
+	Name string        `json:"name"`
+	Item []PostmanItem `json:"item"`
+}
+
+type PostmanItem struct {
+	Name    string         `json:"name"`
+	Request PostmanRequest `json:"request"`
+}
+
+type PostmanRequest struct {
+	Method      string            `json:"method"`
+	Header      []PostmanVariable `json:"header"`
+	Body        *PostmanBody      `json:"body,omitempty"`
+	Url         string            `json:"url"`
+	Description string            `json:"description,omitempty"` // the call site like 'pages/index/index.js:12'
+}
+
+type PostmanVariable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type PostmanBody struct {
+	Mode       string            `json:"mode"` // raw, urlencoded or formdata
+	Raw        string            `json:"raw,omitempty"`
+	Urlencoded []PostmanVariable `json:"urlencoded,omitempty"`
+	Formdata   []PostmanVariable `json:"formdata,omitempty"`
+	Options    interface{}       `json:"options,omitempty"`
+}
+
+// PostmanBaseUrl is the variable of the host of the requests without the fixed host.
+const PostmanBaseUrl = "baseUrl"
+
+// NewPostmanCollection returns the empty collection, baseUrl is the value of the host variable.
+func NewPostmanCollection(name, description, baseUrl string) *PostmanCollection {
+	var c = &PostmanCollection{
+		Info: PostmanInfo{
+			Name:        name,
+			Description: description,
+			Schema:      "https://schema.getpostman.com/json/collection/v2.1.0/collection.json",
+		},
+		Item:      []*PostmanFolder{},
+		folders:   make(map[string]*PostmanFolder),
+		variables: make(map[string]bool),
+	}
+	c.addVariable(PostmanBaseUrl, strings.TrimSuffix(baseUrl, "/"))
+
+	return c
+}
+
+// AddRequest adds the request call to the folder of the module, source is the location of the call. The
+// templates like '{token}' are converted to the variables like '{{token}}'.
+func (c *PostmanCollection) AddRequest(call RequestCall, module, source string) {
+	server, path, query := SplitRequestUrl(call.Url)
+	if server == "" {
+		server = "{" + PostmanBaseUrl + "}"
+	}
+	if strings.HasPrefix(server, "{") {
+		// the templates of the base urls default to the host variable
+		for _, name := range TemplateParams(server) {
+			c.addVariable(name, "{{"+PostmanBaseUrl+"}}")
+		}
+	}
+
+	var u = server + path
+	if query != "" {
+		u += "?" + query
+	}
+	var req = PostmanRequest{
+		Method:      call.Method,
+		Header:      []PostmanVariable{},
+		Url:         c.variableTemplate(u),
+		Description: source,
+	}
+	for _, name := range sortedStringKeys(call.Header) {
+		req.Header = append(req.Header, PostmanVariable{Key: name, Value: c.variableTemplate(call.Header[name])})
+	}
+
+	var method = strings.ToUpper(call.Method)
+	if len(call.Data) > 0 && (method == "GET" || method == "DELETE" || method == "HEAD") {
+		var params []string
+		for _, name := range sortedStringKeys(call.Data) {
+			params = append(params, name+"="+c.dataVariable(name, call.Data[name]))
+		}
+		if query == "" {
+			req.Url += "?"
+		} else {
+			req.Url += "&"
+		}
+		req.Url += strings.Join(params, "&")
+	} else if len(call.Data) > 0 {
+		req.Body = c.body(call)
+	}
+
+	var folder = c.folders[module]
+	if folder == nil {
+		folder = &PostmanFolder{Name: module}
+		c.folders[module] = folder
+		c.Item = append(c.Item, folder)
+		sort.SliceStable(c.Item, func(i, j int) bool {
+			return c.Item[i].Name < c.Item[j].Name
+		})
+	}
+	folder.Item = append(folder.Item, PostmanItem{Name: method + " " + path, Request: req})
+}
+
+// body returns the body of the data in the content type of the call.
+func (c *PostmanCollection) body(call RequestCall) *PostmanBody {
+	var contentType = call.contentType()
+	if strings.Contains(contentType, "json") {
+		for name, t := range call.Data {
+			if t != "object" && t != "array" {
+				c.dataVariable(name, t)
+			}
+		}
+		return &PostmanBody{
+			Mode:    "raw",
+			Raw:     dataBody(call.Data, contentType, "{{%s}}"),
+			Options: map[string]interface{}{"raw": map[string]string{"language": "json"}},
+		}
+	}
+
+	var values []PostmanVariable
+	for _, name := range sortedStringKeys(call.Data) {
+		values = append(values, PostmanVariable{Key: name, Value: c.dataVariable(name, call.Data[name])})
+	}
+	if strings.Contains(contentType, "multipart") {
+		return &PostmanBody{Mode: "formdata", Formdata: values}
+	}
+	return &PostmanBody{Mode: "urlencoded", Urlencoded: values}
+}
+
+// dataVariable returns the placeholder of the data value, the values of the unknown types are the variables.
+func (c *PostmanCollection) dataVariable(name, t string) string {
+	var value = dataPlaceholder(name, t, "{{%s}}")
+	if value == fmt.Sprintf("{{%s}}", name) {
+		c.addVariable(name, "")
+	}
+
+	return value
+}
+
+// variableTemplate converts the templates in the text to the variables.
+func (c *PostmanCollection) variableTemplate(text string) string {
+	return regTemplateParam.ReplaceAllStringFunc(text, func(match string) string {
+		var name = match[1 : len(match)-1]
+		c.addVariable(name, "")
+		return "{{" + name + "}}"
+	})
+}
+
+func (c *PostmanCollection) addVariable(key, value string) {
+	if c.variables[key] {
+		return
+	}
+	c.variables[key] = true
+	c.Variable = append(c.Variable, PostmanVariable{Key: key, Value: value})
+}
@@ -0,0 +1,267 @@
+package util
+
+import (
+	"fmt"
+	"html"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PreviewCss is the base style of the built-in components in the page previews, the pages are 375px wide
+// like the phones, see WxssToCss.
+const PreviewCss = `html{background:#eee}body{max-width:375px;min-height:100vh;margin:0 auto;background:#fff;` +
+	`font-family:-apple-system,"PingFang SC","Microsoft YaHei",sans-serif;font-size:16px}page{display:block}` +
+	`view,scroll-view,swiper,swiper-item,movable-area,movable-view,cover-view,form,navigator,rich-text,editor,` +
+	`map,video,canvas,web-view,camera,live-player,ad{display:block}block{display:contents}` +
+	`scroll-view{overflow:auto}swiper{height:150px;overflow:hidden}navigator{cursor:pointer}` +
+	`image,cover-image{display:inline-block;width:320px;height:240px;overflow:hidden;background:#f3f3f3}` +
+	`image>img,cover-image>img{display:block;width:100%;height:100%}image[mode=aspectFill]>img{object-fit:cover}` +
+	`image[mode=aspectFit]>img{object-fit:contain}image[mode=widthFix],image[mode=widthFix]>img{height:auto}` +
+	`button{display:block;box-sizing:border-box;width:100%;margin:0 auto;padding:0 14px;font-size:18px;` +
+	`line-height:2.55;border:1px solid rgba(0,0,0,.2);border-radius:5px;background:#f8f8f8}` +
+	`icon{display:inline-block;width:23px;height:23px;border-radius:50%;background:#09bb07}` +
+	`map,video,canvas,web-view,camera,live-player{min-height:150px;background:#ddd}`
+
+var regRpx = regexp.MustCompile(`(-?\d*\.?\d+)rpx\b`)
+var regWxssImport = regexp.MustCompile(`@import\s+["']([^"']+)["']\s*;?`)
+var regMustache = regexp.MustCompile(`\{\{[^}]*\}\}`)
+
+// WxssToCss converts the wxss to the css of the 375px wide page, 750rpx is 375px.
+func WxssToCss(wxss []byte) []byte {
+	return regRpx.ReplaceAllFunc(wxss, func(match []byte) []byte {
+		v, err := strconv.ParseFloat(string(match[:len(match)-3]), 64)
+		if err != nil {
+			return match
+		}
+		return []byte(strconv.FormatFloat(v/2, 'f', -1, 64) + "px")
+	})
+}
+
+// maxPreviewDepth limits the nesting of the components, the includes and the templates.
+const maxPreviewDepth = 16
+
+// PreviewRenderer renders the wxml and the wxss of the pages to the plain html and css, for a rough visual
+// preview. The built-in components keep their tags styled by PreviewCss so that the wxss selectors still
+// match, the custom components, the includes and the static templates are expanded, the data bindings are
+// shown as they are and 'wx:for' renders the items once.
+type PreviewRenderer struct {
+	ReadFile   func(name string) ([]byte, error)   // reads the file by the slash path in the unpacked folder
+	Asset      func(from, ref string) string       // the link of the asset referenced in the file, "" if it's not extracted
+	Components func(from string) map[string]string // the custom components used by the page or the component, tag -> the path
+}
+
+// previewTemplate is the template defined by '<template name>' in the file.
+type previewTemplate struct {
+	file string
+	node *WxmlNode
+}
+
+// previewContext is the file the nodes are rendered from.
+type previewContext struct {
+	file       string
+	templates  map[string]previewTemplate
+	components map[string]string
+	slot       func() // renders the children of the component in the page
+	depth      int
+}
+
+type previewPage struct {
+	r      *PreviewRenderer
+	css    strings.Builder
+	styled map[string]bool
+	body   strings.Builder
+}
+
+// Render returns the html of the page like 'pages/index/index', whose wxml is 'pages/index/index.wxml'.
+func (r *PreviewRenderer) Render(page string) ([]byte, error) {
+	data, err := r.ReadFile(page + ".wxml")
+	if err != nil {
+		return nil, err
+	}
+
+	var p = &previewPage{r: r, styled: make(map[string]bool)}
+	p.addStyle("app.wxss", 0)
+	p.addStyle(page+".wxss", 0)
+	var nodes = ParseWxml(data)
+	p.renderNodes(nodes, p.context(page+".wxml", nodes, page, nil, 0))
+
+	return []byte(fmt.Sprintf("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\">"+
+		"<meta name=\"viewport\" content=\"width=device-width,initial-scale=1\"><title>%s</title>\n"+
+		"<style>%s</style>\n<style>\n%s</style></head>\n<body><page>\n%s</page></body></html>\n",
+		html.EscapeString(page), PreviewCss, p.css.String(), p.body.String())), nil
+}
+
+// context returns the context of the file with the templates defined in it and in the imported files.
+func (p *previewPage) context(file string, nodes []*WxmlNode, owner string, slot func(), depth int) previewContext {
+	var ctx = previewContext{file: file, templates: make(map[string]previewTemplate), slot: slot, depth: depth}
+	if p.r.Components != nil && owner != "" {
+		ctx.components = p.r.Components(owner)
+	}
+
+	var collect func(file string, nodes []*WxmlNode, imports bool)
+	collect = func(file string, nodes []*WxmlNode, imports bool) {
+		for _, n := range nodes {
+			switch {
+			case n.Tag == "template" && n.Attr("name") != "":
+				ctx.templates[n.Attr("name")] = previewTemplate{file: file, node: n}
+			case n.Tag == "import" && imports:
+				var name = resolveWxmlPath(file, n.Attr("src"), ".wxml")
+				if data, err := p.r.ReadFile(name); err == nil {
+					collect(name, ParseWxml(data), false) // the imports are not transitive
+				}
+			}
+		}
+	}
+	collect(file, nodes, true)
+
+	return ctx
+}
+
+// addStyle adds the wxss file and the files it imports to the css once.
+func (p *previewPage) addStyle(file string, depth int) {
+	if p.styled[file] || depth > maxPreviewDepth {
+		return
+	}
+	p.styled[file] = true
+	data, err := p.r.ReadFile(file)
+	if err != nil {
+		return
+	}
+
+	data = regWxssImport.ReplaceAllFunc(data, func(match []byte) []byte {
+		p.addStyle(resolveWxmlPath(file, string(regWxssImport.FindSubmatch(match)[1]), ".wxss"), depth+1)
+		return nil
+	})
+	data = regCssUrl.ReplaceAllFunc(WxssToCss(data), func(match []byte) []byte {
+		var groups = regCssUrl.FindSubmatch(match)
+		if link := p.r.Asset(file, string(groups[3])); link != "" {
+			return []byte(string(groups[1]) + string(groups[2]) + link + string(groups[4]))
+		}
+		return match
+	})
+
+	p.css.WriteString("/* " + file + " */\n")
+	p.css.Write(data)
+	p.css.WriteString("\n")
+}
+
+func (p *previewPage) renderNodes(nodes []*WxmlNode, ctx previewContext) {
+	for _, n := range nodes {
+		p.renderNode(n, ctx)
+	}
+}
+
+func (p *previewPage) renderNode(n *WxmlNode, ctx previewContext) {
+	if n.Tag == "" {
+		p.body.WriteString(strings.ReplaceAll(n.Text, "<", "&lt;"))
+		return
+	}
+	if ctx.depth > maxPreviewDepth {
+		return
+	}
+
+	switch n.Tag {
+	case "wxs", "import":
+		return
+	case "template":
+		var t, ok = ctx.templates[n.Attr("is")]
+		if n.Attr("name") != "" || !ok {
+			return // the definitions, and the dynamic templates
+		}
+		var tctx = ctx
+		tctx.file, tctx.depth = t.file, ctx.depth+1
+		p.renderNodes(t.node.Children, tctx)
+		return
+	case "include":
+		var name = resolveWxmlPath(ctx.file, n.Attr("src"), ".wxml")
+		data, err := p.r.ReadFile(name)
+		if err != nil {
+			return
+		}
+		var nodes = ParseWxml(data)
+		var ictx = p.context(name, nodes, "", ctx.slot, ctx.depth+1)
+		ictx.components = ctx.components // the included file is a part of the page
+		for _, child := range nodes {
+			if child.Tag != "template" {
+				p.renderNode(child, ictx)
+			}
+		}
+		return
+	case "slot":
+		if ctx.slot != nil {
+			ctx.slot()
+		}
+		return
+	}
+
+	p.body.WriteString("<" + n.Tag)
+	for _, a := range n.Attrs {
+		if !previewAttr(a) {
+			continue
+		}
+		var value = a.Value
+		if a.Name == "class" {
+			value = strings.Join(strings.Fields(regMustache.ReplaceAllString(value, " ")), " ")
+		}
+		p.body.WriteString(" " + a.Name + `="` + html.EscapeString(value) + `"`)
+	}
+	p.body.WriteString(fmt.Sprintf(` data-wxml="%s:%d">`, html.EscapeString(ctx.file), n.Line))
+
+	if n.Tag == "image" || n.Tag == "cover-image" {
+		if link := p.r.Asset(ctx.file, n.Attr("src")); link != "" {
+			p.body.WriteString(`<img src="` + html.EscapeString(link) + `" alt="">`)
+		}
+	}
+
+	if component, ok := ctx.components[n.Tag]; ok && component != "" {
+		p.renderComponent(component, n, ctx)
+	} else {
+		p.renderNodes(n.Children, ctx)
+	}
+	p.body.WriteString("</" + n.Tag + ">")
+}
+
+// renderComponent renders the custom component used by the node, the slot renders the children of the node.
+func (p *previewPage) renderComponent(component string, n *WxmlNode, ctx previewContext) {
+	data, err := p.r.ReadFile(component + ".wxml")
+	if err != nil {
+		p.renderNodes(n.Children, ctx)
+		return
+	}
+	p.addStyle(component+".wxss", 0)
+
+	var nodes = ParseWxml(data)
+	var slot = func() {
+		p.renderNodes(n.Children, ctx)
+	}
+	p.renderNodes(nodes, p.context(component+".wxml", nodes, component, slot, ctx.depth+1))
+}
+
+// previewAttr reports whether the attribute is kept in the preview, the event handlers and the directives like
+// 'wx:if' are dropped, and so are the dynamic boolean attributes like 'hidden="{{!show}}"'.
+func previewAttr(a WxmlAttr) bool {
+	switch a.Name {
+	case "id", "class", "style", "mode", "type", "placeholder", "value":
+		return true
+	case "hidden", "disabled":
+		return !strings.Contains(a.Value, "{{")
+	default:
+		return strings.HasPrefix(a.Name, "data-")
+	}
+}
+
+// resolveWxmlPath resolves the src of the import, the include or the wxss import in the file, the extension
+// is added if it's omitted.
+func resolveWxmlPath(file, src, ext string) string {
+	var p = path.Join(path.Dir("/"+file), src)
+	if strings.HasPrefix(src, "/") {
+		p = path.Clean(src)
+	}
+	if path.Ext(p) == "" {
+		p += ext
+	}
+
+	return strings.TrimPrefix(p, "/")
+}
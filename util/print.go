@@ -1,15 +1,57 @@
 package util
 
 import (
-	"github.com/fatih/color"
+	"encoding/json"
 	"os"
+	"sync"
+
+	"github.com/fatih/color"
 )
 
+// JsonOutput prints the records in JSON lines to stdout instead of the colored messages, see PrintJson.
+var JsonOutput = false
+
+var printLocker sync.Mutex
+
+// PrintJson prints the record in one line to stdout.
+func PrintJson(record interface{}) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+
+	printLocker.Lock()
+	defer printLocker.Unlock()
+	_, _ = os.Stdout.Write(append(data, '\n'))
+}
+
+var cleanups []func()
+var cleanupLocker sync.Mutex
+
+// OnFatal registers the cleanup run by Fatal before exiting, since the deferred calls are skipped by os.Exit.
+// The cleanups are run in the reverse order, like the deferred calls.
+func OnFatal(cleanup func()) {
+	cleanupLocker.Lock()
+	defer cleanupLocker.Unlock()
+	cleanups = append(cleanups, cleanup)
+}
+
+// Fatal prints the error and exits with the exit code attached to it, see ExitCode.
 func Fatal(err error) {
 	if err == nil {
 		return
 	}
 
-	color.Red("%v", err)
-	os.Exit(0)
+	if JsonOutput {
+		PrintJson(NewErrorRecord(err))
+	} else {
+		color.Red("%v", err)
+	}
+
+	cleanupLocker.Lock()
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+	cleanupLocker.Unlock()
+	os.Exit(ExitCode(err))
 }
@@ -0,0 +1,364 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var regRequestCall = regexp.MustCompile(`\bwx\.(request|uploadFile|downloadFile)\s*\(\s*\{`)
+var regIdentifier = regexp.MustCompile(`[A-Za-z_$][\w$]*`)
+var regStringConst = regexp.MustCompile(`\b(?:var|let|const)\s+([A-Za-z_$][\w$]*)\s*=\s*("[^"\\\n]*"|'[^'\\\n]*')`)
+var regNumber = regexp.MustCompile(`^-?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?$`)
+
+// RequestCall is the call of wx.request, wx.uploadFile or wx.downloadFile found in the code statically, the
+// dynamic parts of the url and the headers are the templates like '{id}' named after the expressions.
+type RequestCall struct {
+	Api    string            `json:"api"` // request, uploadFile or downloadFile
+	Url    string            `json:"url"`
+	Method string            `json:"method"`
+	Header map[string]string `json:"header,omitempty"`
+	Data   map[string]string `json:"data,omitempty"` // the keys of the data object -> the inferred json types
+	Line   int               `json:"line"`
+}
+
+// FindRequests returns the request calls with the object literal options in the code, the calls without a
+// url like the wrappers taking the options from the arguments are ignored. The string constants declared in
+// the code, like the base url, are resolved.
+func FindRequests(code []byte) []RequestCall {
+	var consts = make(map[string]string)
+	for _, match := range regStringConst.FindAllSubmatch(code, -1) {
+		if s, ok := stringLiteral(string(match[2])); ok {
+			consts[string(match[1])] = s
+		}
+	}
+
+	var result []RequestCall
+	for _, loc := range regRequestCall.FindAllSubmatchIndex(code, -1) {
+		var open = loc[1] - 1
+		var end = matchBrace(code, open)
+		if end < 0 {
+			continue
+		}
+
+		var props = objectProperties(string(code[open+1 : end]))
+		url, ok := props["url"]
+		if !ok {
+			continue
+		}
+
+		var line, _ = LineAt(code, loc[0])
+		var call = RequestCall{Api: string(code[loc[2]:loc[3]]), Url: exprTemplate(url, consts), Method: "GET", Line: line}
+		if call.Api == "uploadFile" {
+			call.Method = "POST"
+		}
+		if !strings.ContainsAny(regTemplateParam.ReplaceAllString(call.Url, ""), "/.") {
+			continue // the whole url is dynamic
+		}
+		if method, ok := stringLiteral(props["method"]); ok && method != "" {
+			call.Method = strings.ToUpper(method)
+		}
+
+		if header, ok := props["header"]; ok && strings.HasPrefix(header, "{") {
+			call.Header = make(map[string]string)
+			for k, v := range objectProperties(strings.TrimSuffix(strings.TrimPrefix(header, "{"), "}")) {
+				call.Header[k] = exprTemplate(v, consts)
+			}
+		}
+
+		var data = props["data"]
+		if call.Api == "uploadFile" {
+			data = props["formData"]
+		}
+		if strings.HasPrefix(data, "{") {
+			call.Data = make(map[string]string)
+			for k, v := range objectProperties(strings.TrimSuffix(strings.TrimPrefix(data, "{"), "}")) {
+				call.Data[k] = inferJsonType(v)
+			}
+		}
+
+		result = append(result, call)
+	}
+
+	return result
+}
+
+// contentType returns the content type of the request body, json by default.
+func (c RequestCall) contentType() string {
+	if c.Api == "uploadFile" {
+		return "multipart/form-data"
+	}
+	for name, value := range c.Header {
+		if strings.EqualFold(name, "content-type") && !strings.Contains(value, "{") {
+			return value
+		}
+	}
+
+	return "application/json"
+}
+
+var regTemplateParam = regexp.MustCompile(`\{[^{}]*\}`)
+
+// TemplateParams returns the names of the templates like '{id}' in the text.
+func TemplateParams(text string) []string {
+	var result []string
+	for _, match := range regTemplateParam.FindAllString(text, -1) {
+		result = append(result, match[1:len(match)-1])
+	}
+
+	return result
+}
+
+// ExprTemplate converts the js string expression to the template, the string literals are kept and the other
+// parts are replaced with the templates like '{id}', e.g. `host + "/user/" + id` is '{host}/user/{id}'.
+func ExprTemplate(expr string) string {
+	return exprTemplate(expr, nil)
+}
+
+// exprTemplate is ExprTemplate resolving the identifiers of the string constants, name -> value.
+func exprTemplate(expr string, consts map[string]string) string {
+	var b strings.Builder
+	for _, term := range splitTopLevel(expr, '+') {
+		term = strings.TrimSpace(term)
+		if s, ok := stringLiteral(term); ok {
+			b.WriteString(s)
+			continue
+		}
+		if strings.HasPrefix(term, "`") && strings.HasSuffix(term, "`") && len(term) >= 2 {
+			b.WriteString(templateLiteral(term[1 : len(term)-1]))
+			continue
+		}
+		if strings.HasPrefix(term, "(") && strings.HasSuffix(term, ")") {
+			b.WriteString(exprTemplate(term[1:len(term)-1], consts))
+			continue
+		}
+		if s, ok := consts[term]; ok {
+			b.WriteString(s)
+			continue
+		}
+		if term != "" {
+			b.WriteString("{" + paramName(term) + "}")
+		}
+	}
+
+	return b.String()
+}
+
+// templateLiteral converts the body of the template literal like 'a/${b}' to 'a/{b}'.
+func templateLiteral(body string) string {
+	var b strings.Builder
+	for {
+		var start = strings.Index(body, "${")
+		if start < 0 {
+			b.WriteString(body)
+			return b.String()
+		}
+		b.WriteString(body[:start])
+
+		var depth, end = 0, -1
+		for i := start + 1; i < len(body) && end < 0; i++ {
+			switch body[i] {
+			case '{':
+				depth++
+			case '}':
+				if depth--; depth == 0 {
+					end = i
+				}
+			}
+		}
+		if end < 0 {
+			b.WriteString(body[start:])
+			return b.String()
+		}
+		b.WriteString("{" + paramName(body[start+2:end]) + "}")
+		body = body[end+1:]
+	}
+}
+
+// paramName names the dynamic part after its last identifier, like 'id' for 'this.data.id'.
+func paramName(expr string) string {
+	var names = regIdentifier.FindAllString(expr, -1)
+	for i := len(names) - 1; i >= 0; i-- {
+		if !jsKeywords[names[i]] {
+			return names[i]
+		}
+	}
+
+	return "param"
+}
+
+// stringLiteral returns the content of the js string literal, the escapes are simply unescaped.
+func stringLiteral(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != s[len(s)-1] || (s[0] != '"' && s[0] != '\'' && s[0] != '`') {
+		return "", false
+	}
+	if s[0] == '`' && strings.Contains(s, "${") {
+		return "", false
+	}
+
+	var b strings.Builder
+	var body = s[1 : len(s)-1]
+	for i := 0; i < len(body); i++ {
+		switch {
+		case body[i] == '\\' && i+1 < len(body):
+			i++
+			b.WriteByte(body[i])
+		case body[i] == s[0]:
+			return "", false // like '"a" + "b"'
+		default:
+			b.WriteByte(body[i])
+		}
+	}
+
+	return b.String(), true
+}
+
+// inferJsonType infers the json type of the js expression, "" if unknown.
+func inferJsonType(expr string) string {
+	expr = strings.TrimSpace(expr)
+	switch {
+	case expr == "":
+		return ""
+	case expr[0] == '"' || expr[0] == '\'' || expr[0] == '`':
+		return "string"
+	case regNumber.MatchString(expr):
+		return "number"
+	case expr == "true" || expr == "false" || expr == "!0" || expr == "!1":
+		return "boolean"
+	case expr[0] == '{':
+		return "object"
+	case expr[0] == '[':
+		return "array"
+	default:
+		return ""
+	}
+}
+
+// objectProperties returns the top level properties of the body of the js object literal, key -> the value
+// expression. The shorthand properties like '{url}' are the identifiers themselves, the spreads are ignored.
+func objectProperties(body string) map[string]string {
+	var result = make(map[string]string)
+	for _, part := range splitTopLevel(body, ',') {
+		part = strings.TrimSpace(part)
+		if part == "" || strings.HasPrefix(part, "...") {
+			continue
+		}
+
+		var parts = splitTopLevel(part, ':')
+		if len(parts) == 1 {
+			if regIdentifier.FindString(part) == part {
+				result[part] = part
+			}
+			continue
+		}
+
+		var key = strings.TrimSpace(parts[0])
+		if s, ok := stringLiteral(key); ok {
+			key = s
+		}
+		result[key] = strings.TrimSpace(part[len(parts[0])+1:])
+	}
+
+	return result
+}
+
+// splitTopLevel splits the js expression by sep out of the brackets, the strings and the comments.
+func splitTopLevel(s string, sep byte) []string {
+	var result []string
+	var depth, start = 0, 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case '"', '\'', '`':
+			for i++; i < len(s) && s[i] != c; i++ {
+				if s[i] == '\\' {
+					i++
+				}
+			}
+		case '/':
+			if strings.HasPrefix(s[i:], "//") {
+				if end := strings.IndexByte(s[i:], '\n'); end >= 0 {
+					i += end
+				} else {
+					i = len(s)
+				}
+			} else if strings.HasPrefix(s[i:], "/*") {
+				if end := strings.Index(s[i+2:], "*/"); end >= 0 {
+					i += end + 3
+				} else {
+					i = len(s)
+				}
+			}
+		default:
+			if c == sep && depth == 0 {
+				result = append(result, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(result, s[start:])
+}
+
+// SortRequests sorts the calls by the urls and the methods.
+func SortRequests(calls []RequestCall) {
+	sort.SliceStable(calls, func(i, j int) bool {
+		if calls[i].Url != calls[j].Url {
+			return calls[i].Url < calls[j].Url
+		}
+		return calls[i].Method < calls[j].Method
+	})
+}
+
+// dataPlaceholder returns the placeholder of the data value in the json type, the values of the unknown types
+// are the templates of the names in format, like '{%s}'.
+func dataPlaceholder(name, t, format string) string {
+	switch t {
+	case "number":
+		return "0"
+	case "boolean":
+		return "false"
+	case "string":
+		return ""
+	default:
+		return fmt.Sprintf(format, name)
+	}
+}
+
+// dataBody returns the request body of the data, key -> the json type, in the content type, the json object
+// for the json types and the form otherwise.
+func dataBody(data map[string]string, contentType, format string) string {
+	if !strings.Contains(contentType, "json") {
+		var values = url.Values{}
+		for name, t := range data {
+			values.Set(name, dataPlaceholder(name, t, format))
+		}
+		return values.Encode()
+	}
+
+	var body = make(map[string]interface{}, len(data))
+	for name, t := range data {
+		switch t {
+		case "number":
+			body[name] = 0
+		case "boolean":
+			body[name] = false
+		case "object":
+			body[name] = map[string]interface{}{}
+		case "array":
+			body[name] = []interface{}{}
+		default:
+			body[name] = dataPlaceholder(name, t, format)
+		}
+	}
+	text, _ := json.Marshal(body)
+
+	return string(text)
+}
@@ -0,0 +1,48 @@
+package util
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+)
+
+// RetryDelay is the delay before the first retry, doubled after every retry.
+var RetryDelay = 100 * time.Millisecond
+
+// the errors retrying doesn't fix, like the denied permission and the invalid or too long names
+var permanentErrors = []error{
+	os.ErrPermission,
+	os.ErrInvalid,
+	os.ErrExist,
+	syscall.ENAMETOOLONG,
+	syscall.ENOTDIR,
+	syscall.EISDIR,
+}
+
+// IsTransient reports whether the error may go away when retrying, like the timeouts of the network shares
+// and the files briefly locked by the antivirus or the cloud sync.
+func IsTransient(err error) bool {
+	for _, permanent := range permanentErrors {
+		if errors.Is(err, permanent) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Retry calls fn until it succeeds or has been retried `retries` times, the last error is returned. The
+// permanent errors are returned immediately, see IsTransient.
+func Retry(retries int, fn func() error) error {
+	var delay = RetryDelay
+	var err error
+	for i := 0; ; i++ {
+		if err = fn(); err == nil || i >= retries || !IsTransient(err) {
+			return err
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
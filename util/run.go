@@ -0,0 +1,77 @@
+package util
+
+import (
+	"sort"
+	"time"
+)
+
+// RunName is the file name of the summary of the last run saved in the output path.
+const RunName = "run.json"
+
+// RunSummary is the summary of an unpack run, the inputs, the timings and the errors, so that the performance
+// and the reliability can be tracked over time.
+type RunSummary struct {
+	RunId    string            `json:"run_id"`
+	Args     []string          `json:"args"`
+	Flags    map[string]string `json:"flags"` // the flags set explicitly, the secrets are redacted
+	Root     string            `json:"root"`
+	Output   string            `json:"output"`
+	Started  time.Time         `json:"started"`
+	Finished time.Time         `json:"finished"`
+	Elapsed  int64             `json:"elapsed_ms"`
+	Packages []RunPackage      `json:"packages"`
+	Totals   RunTotals         `json:"totals"`
+	Errors   []string          `json:"errors"`
+}
+
+// RunPackage is the timings of an unpacked package. The beautify time is summed over the file workers, so
+// it may be longer than the extract time.
+type RunPackage struct {
+	Package    string `json:"package"`
+	Output     string `json:"output"`
+	Size       int64  `json:"size"`
+	Files      int    `json:"files"`
+	Written    int64  `json:"written"`
+	Beautified int    `json:"beautified"`
+	Failures   int    `json:"failures"`
+	Decrypt    int64  `json:"decrypt_ms"`
+	Parse      int64  `json:"parse_ms"`
+	Extract    int64  `json:"extract_ms"`
+	Beautify   int64  `json:"beautify_ms"`
+	Elapsed    int64  `json:"elapsed_ms"`
+
+	Placeholders []string `json:"placeholders,omitempty"` // the entries without a body, skipped
+}
+
+type RunTotals struct {
+	Packages   int   `json:"packages"`
+	Skipped    int   `json:"skipped"` // the packages held by wechat
+	Files      int   `json:"files"`
+	Size       int64 `json:"size"` // the bytes of the wxapkg files
+	Written    int64 `json:"written"`
+	Beautified int   `json:"beautified"`
+	Failures   int   `json:"failures"`
+}
+
+// Finish sorts the packages, sums up the totals and saves the summary to the output.
+func (r *RunSummary) Finish(out Output, finished time.Time) error {
+	r.Finished = finished
+	r.Elapsed = finished.Sub(r.Started).Milliseconds()
+
+	sort.Slice(r.Packages, func(i, j int) bool {
+		return r.Packages[i].Package < r.Packages[j].Package
+	})
+	r.Totals.Packages = len(r.Packages)
+	for _, p := range r.Packages {
+		r.Totals.Files += p.Files
+		r.Totals.Size += p.Size
+		r.Totals.Written += p.Written
+		r.Totals.Beautified += p.Beautified
+		r.Totals.Failures += p.Failures
+	}
+	if r.Errors == nil {
+		r.Errors = []string{}
+	}
+
+	return WriteJson(out, RunName, r)
+}
@@ -0,0 +1,172 @@
+package util
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// ObjectStorage uploads the files to the S3 compatible object storage, including Aliyun OSS and
+// Tencent COS, with the AWS signature version 4.
+type ObjectStorage struct {
+	scheme    string
+	bucket    string
+	prefix    string
+	endpoint  string // the base url of the bucket
+	region    string
+	accessKey string
+	secretKey string
+	token     string
+	client    *http.Client
+}
+
+// NewObjectStorage returns the storage of the url like 's3://bucket/prefix', the credentials and
+// the region are read from the environment variables:
+//
+//   - s3://  AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN, AWS_REGION, and
+//     AWS_ENDPOINT_URL for the other S3 compatible storages like MinIO
+//   - oss:// OSS_ACCESS_KEY_ID, OSS_ACCESS_KEY_SECRET, OSS_REGION like 'cn-hangzhou'
+//   - cos:// COS_SECRET_ID, COS_SECRET_KEY, COS_REGION like 'ap-guangzhou'
+func NewObjectStorage(u *url.URL) (*ObjectStorage, error) {
+	var s = &ObjectStorage{
+		scheme: u.Scheme,
+		bucket: u.Host,
+		prefix: strings.Trim(u.Path, "/"),
+		client: &http.Client{Timeout: 5 * time.Minute},
+	}
+	if s.bucket == "" {
+		return nil, fmt.Errorf(T("no bucket in '%s'"), u.String())
+	}
+
+	switch u.Scheme {
+	case "s3":
+		s.accessKey, s.secretKey = os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+		s.token = os.Getenv("AWS_SESSION_TOKEN")
+		s.region = os.Getenv("AWS_REGION")
+		if s.region == "" {
+			s.region = "us-east-1"
+		}
+		if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+			s.endpoint = strings.TrimSuffix(endpoint, "/") + "/" + s.bucket // path style
+		} else {
+			s.endpoint = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", s.bucket, s.region)
+		}
+	case "oss":
+		s.accessKey, s.secretKey = os.Getenv("OSS_ACCESS_KEY_ID"), os.Getenv("OSS_ACCESS_KEY_SECRET")
+		s.region = os.Getenv("OSS_REGION")
+		s.endpoint = fmt.Sprintf("https://%s.oss-%s.aliyuncs.com", s.bucket, s.region)
+	case "cos":
+		s.accessKey, s.secretKey = os.Getenv("COS_SECRET_ID"), os.Getenv("COS_SECRET_KEY")
+		s.region = os.Getenv("COS_REGION")
+		s.endpoint = fmt.Sprintf("https://%s.cos.%s.myqcloud.com", s.bucket, s.region)
+	}
+
+	if s.accessKey == "" || s.secretKey == "" || s.region == "" {
+		return nil, fmt.Errorf(T("the credentials or the region of '%s' are not set"), u.Scheme)
+	}
+
+	return s, nil
+}
+
+func (s *ObjectStorage) String() string {
+	return s.scheme + "://" + path.Join(s.bucket, s.prefix)
+}
+
+func (s *ObjectStorage) WriteFile(name string, data []byte) error {
+	var key = path.Join(s.prefix, name)
+	var u = s.endpoint + "/" + escapeKey(key)
+
+	req, err := http.NewRequest(http.MethodPut, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	s.sign(req, data, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("put '%s': %s %s", key, resp.Status, bytes.TrimSpace(body))
+	}
+
+	return nil
+}
+
+// sign signs the request with the AWS signature version 4.
+func (s *ObjectStorage) sign(req *http.Request, payload []byte, now time.Time) {
+	var date = now.Format("20060102")
+	var datetime = now.Format("20060102T150405Z")
+	var payloadHash = Sha256(payload)
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", datetime)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.token != "" {
+		req.Header.Set("X-Amz-Security-Token", s.token)
+	}
+
+	var headers = []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if s.token != "" {
+		headers = append(headers, "x-amz-security-token")
+	}
+	var canonicalHeaders strings.Builder
+	for _, h := range headers {
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(req.Header.Get(h)) + "\n")
+	}
+	var signedHeaders = strings.Join(headers, ";")
+
+	var canonicalRequest = strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	var scope = date + "/" + s.region + "/s3/aws4_request"
+	var stringToSign = strings.Join([]string{"AWS4-HMAC-SHA256", datetime, scope, Sha256([]byte(canonicalRequest))}, "\n")
+
+	var key = hmacSha256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSha256(key, s.region)
+	key = hmacSha256(key, "s3")
+	key = hmacSha256(key, "aws4_request")
+	var signature = hex.EncodeToString(hmacSha256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+// escapeKey escapes the object key like the AWS uri encoding, only the unreserved characters and '/'
+// are kept.
+func escapeKey(key string) string {
+	var result strings.Builder
+	for _, b := range []byte(key) {
+		if 'A' <= b && b <= 'Z' || 'a' <= b && b <= 'z' || '0' <= b && b <= '9' || strings.IndexByte("-_.~/", b) >= 0 {
+			result.WriteByte(b)
+		} else {
+			result.WriteString(fmt.Sprintf("%%%02X", b))
+		}
+	}
+
+	return result.String()
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	var h = hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
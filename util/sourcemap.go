@@ -0,0 +1,106 @@
+package util
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// the inline source map at the end of the bundle, like '//# sourceMappingURL=data:application/json;base64,...'
+var regInlineSourceMap = regexp.MustCompile(`(?m)^[ \t]*//[#@][ \t]*sourceMappingURL=data:application/json(?:;charset=[\w-]+)?(;base64)?,([^\s]+)[ \t]*\r?$`)
+
+// SourceMap is the source map v3, only the fields to restore the sources.
+type SourceMap struct {
+	Version        int       `json:"version"`
+	File           string    `json:"file,omitempty"`
+	SourceRoot     string    `json:"sourceRoot,omitempty"`
+	Sources        []string  `json:"sources"`
+	SourcesContent []*string `json:"sourcesContent,omitempty"`
+}
+
+// FindInlineSourceMap returns the decoded inline source map of the script, and the location of the comment.
+func FindInlineSourceMap(data []byte) (mapData []byte, start, end int, ok bool) {
+	var loc = regInlineSourceMap.FindSubmatchIndex(data)
+	if loc == nil {
+		return nil, 0, 0, false
+	}
+
+	var payload = string(data[loc[4]:loc[5]])
+	if loc[2] >= 0 {
+		decoded, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			if decoded, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(payload, "=")); err != nil {
+				return nil, 0, 0, false
+			}
+		}
+		mapData = decoded
+	} else {
+		decoded, err := url.PathUnescape(payload)
+		if err != nil {
+			return nil, 0, 0, false
+		}
+		mapData = []byte(decoded)
+	}
+
+	return mapData, loc[0], loc[1], true
+}
+
+// ParseSourceMap parses the source map.
+func ParseSourceMap(data []byte) (*SourceMap, error) {
+	var m SourceMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// SourceFiles returns the original sources embedded in the source map, the cleaned slash path -> content.
+// The sources without the content, or out of the project like the webpack runtime, are ignored.
+func (m *SourceMap) SourceFiles() map[string]string {
+	var result = make(map[string]string)
+	for i, source := range m.Sources {
+		if i >= len(m.SourcesContent) || m.SourcesContent[i] == nil {
+			continue
+		}
+		if name := CleanSourcePath(m.SourceRoot, source); name != "" {
+			result[name] = *m.SourcesContent[i]
+		}
+	}
+
+	return result
+}
+
+// CleanSourcePath converts the source in the source map like 'webpack:///./src/app.ts' to the relative slash
+// path like 'src/app.ts', the parent references are removed so that it stays in the folder. It returns "" for
+// the generated sources like 'webpack/bootstrap'.
+func CleanSourcePath(root, source string) string {
+	if !strings.Contains(source, "://") && root != "" {
+		source = strings.TrimSuffix(root, "/") + "/" + source
+	}
+	if i := strings.Index(source, "://"); i >= 0 {
+		source = source[i+3:]
+		// the namespace of webpack, like 'webpack://my-app/./src/app.ts'
+		if j := strings.Index(source, "/"); j > 0 && !strings.HasPrefix(source, ".") {
+			source = source[j:]
+		}
+	}
+	if i := strings.IndexAny(source, "?#"); i >= 0 {
+		source = source[:i]
+	}
+
+	var parts []string
+	for _, part := range strings.Split(path.Clean("/"+strings.ReplaceAll(source, "\\", "/")), "/") {
+		if part != "" && part != "." && part != ".." {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 || parts[0] == "webpack" || parts[0] == "(webpack)" {
+		return ""
+	}
+
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// StateName is the file name of the state saved in the output path after every run, see State.
+const StateName = ".wxapkg-state.json"
+
+// State is the state of the last run in the output path, for the scheduled jobs.
+type State struct {
+	LastRun time.Time `json:"last_run"`          // when the last run without the failures started
+	Pending []string  `json:"pending,omitempty"` // the absolute paths of the wxapkg files to unpack again
+}
+
+// ReadState loads the state saved in the output path root.
+func ReadState(root string) (*State, error) {
+	data, err := os.ReadFile(filepath.Join(root, StateName))
+	if err != nil {
+		return nil, err
+	}
+
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// Save saves the state to the output path root.
+func (s *State) Save(root string) error {
+	return SaveJson(filepath.Join(root, StateName), s)
+}
+
+// ParseTime parses the time like '2023-06-01', '2023-06-01 12:00:00', RFC 3339, or the duration before now
+// like '24h'.
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf(T("invalid time '%s', like '2023-06-01', '2023-06-01 12:00:00' or '24h' expected"), s)
+}
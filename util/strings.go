@@ -0,0 +1,121 @@
+package util
+
+import (
+	"encoding/binary"
+	"sort"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// the encodings of the extracted strings
+const (
+	EncodingAscii   = "ascii"
+	EncodingUtf8    = "utf-8"
+	EncodingUtf16le = "utf-16le"
+)
+
+// FoundString is a string found in the binary data.
+type FoundString struct {
+	Offset   int    `json:"offset"`
+	Encoding string `json:"encoding"`
+	Text     string `json:"text"`
+}
+
+// ExtractStrings finds the printable strings of at least min characters in the binary data, in the given
+// encodings, like the strings command. They are sorted by the offsets.
+func ExtractStrings(data []byte, min int, encodings []string) []FoundString {
+	var enabled = make(map[string]bool)
+	for _, encoding := range encodings {
+		enabled[encoding] = true
+	}
+
+	var result []FoundString
+	if enabled[EncodingAscii] || enabled[EncodingUtf8] {
+		result = append(result, extractUtf8(data, min, enabled[EncodingAscii], enabled[EncodingUtf8])...)
+	}
+	if enabled[EncodingUtf16le] {
+		result = append(result, extractUtf16le(data, min, 0)...)
+		result = append(result, extractUtf16le(data, min, 1)...)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Offset < result[j].Offset
+	})
+
+	return result
+}
+
+// extractUtf8 finds the strings of the printable characters, they are ascii if all the characters are
+// ascii, or utf-8. The non-ascii characters end the strings if utf-8 is not enabled.
+func extractUtf8(data []byte, min int, ascii, multibyte bool) []FoundString {
+	var result []FoundString
+	var start, count = 0, 0
+	var onlyAscii = true
+	var flush = func(end int) {
+		if count >= min && onlyAscii && ascii {
+			result = append(result, FoundString{Offset: start, Encoding: EncodingAscii, Text: string(data[start:end])})
+		} else if count >= min && !onlyAscii {
+			result = append(result, FoundString{Offset: start, Encoding: EncodingUtf8, Text: string(data[start:end])})
+		}
+		count = 0
+		onlyAscii = true
+	}
+
+	var i = 0
+	for i < len(data) {
+		r, size := utf8.DecodeRune(data[i:])
+		var ok = r != utf8.RuneError && (r == '\t' || unicode.IsPrint(r))
+		if r >= utf8.RuneSelf && !multibyte {
+			ok = false
+		}
+		if !ok {
+			flush(i)
+			i += size
+			continue
+		}
+
+		if count == 0 {
+			start = i
+		}
+		if r >= utf8.RuneSelf {
+			onlyAscii = false
+		}
+		count++
+		i += size
+	}
+	flush(len(data))
+
+	return result
+}
+
+// extractUtf16le finds the utf-16le strings from the offset align, only the ascii characters are accepted,
+// the pairs of the ascii bytes are decoded as the han characters, which are mostly the noise.
+func extractUtf16le(data []byte, min int, align int) []FoundString {
+	var result []FoundString
+	var units []uint16
+	var start = align
+	var flush = func() {
+		if len(units) >= min {
+			result = append(result, FoundString{Offset: start, Encoding: EncodingUtf16le, Text: string(utf16.Decode(units))})
+		}
+		units = units[:0]
+	}
+
+	for i := align; i+1 < len(data); i += 2 {
+		var u = binary.LittleEndian.Uint16(data[i:])
+		var r = rune(u)
+		if r != '\t' && (r < 0x20 || r >= 0x7f) {
+			flush()
+			continue
+		}
+
+		if len(units) == 0 {
+			start = i
+		}
+		units = append(units, u)
+	}
+	flush()
+
+	return result
+}
@@ -0,0 +1,120 @@
+package util
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/unicode/norm"
+)
+
+var textExtensions = map[string]bool{
+	".js": true, ".json": true, ".html": true, ".wxml": true, ".wxss": true, ".wxs": true,
+	".css": true, ".txt": true, ".md": true, ".xml": true, ".svg": true,
+}
+
+// IsTextFile reports whether the file is a text file by the extension and the content.
+func IsTextFile(name string, data []byte) bool {
+	return textExtensions[strings.ToLower(filepath.Ext(name))] && IsText(data)
+}
+
+const (
+	CharsetUtf8 = "utf-8"
+	CharsetGbk  = "gbk"
+)
+
+// DetectCharset returns the charset of the text, CharsetUtf8, CharsetGbk or "" if unknown.
+func DetectCharset(data []byte) string {
+	if utf8.Valid(data) {
+		return CharsetUtf8
+	}
+
+	decoded, err := simplifiedchinese.GBK.NewDecoder().Bytes(data)
+	if err == nil && !bytes.ContainsRune(decoded, utf8.RuneError) {
+		return CharsetGbk
+	}
+
+	return ""
+}
+
+// ToUtf8 transcodes the text in the charset detected by DetectCharset to UTF-8.
+func ToUtf8(data []byte, charset string) ([]byte, error) {
+	if charset == CharsetGbk {
+		return simplifiedchinese.GBK.NewDecoder().Bytes(data)
+	}
+
+	return data, nil
+}
+
+// FromUtf8 transcodes the UTF-8 text back to the charset, the inverse of ToUtf8.
+func FromUtf8(data []byte, charset string) ([]byte, error) {
+	if charset == CharsetGbk {
+		return simplifiedchinese.GBK.NewEncoder().Bytes(data)
+	}
+
+	return data, nil
+}
+
+const (
+	EolLf   = "lf"
+	EolCrlf = "crlf"
+	EolKeep = "keep"
+)
+
+// NormalizeEol converts the line endings of the text to eol, EolLf or EolCrlf, EolKeep keeps them.
+func NormalizeEol(data []byte, eol string) []byte {
+	if eol != EolLf && eol != EolCrlf {
+		return data
+	}
+
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if eol == EolCrlf {
+		data = bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n"))
+	}
+
+	return data
+}
+
+const (
+	BomStrip = "strip"
+	BomAdd   = "add"
+	BomKeep  = "keep"
+)
+
+var utf8Bom = []byte{0xEF, 0xBB, 0xBF}
+
+// TrimBom removes the UTF-8 BOM of the text, reports whether it has one.
+func TrimBom(data []byte) ([]byte, bool) {
+	if bytes.HasPrefix(data, utf8Bom) {
+		return data[len(utf8Bom):], true
+	}
+
+	return data, false
+}
+
+// AddBom adds the UTF-8 BOM to the text.
+func AddBom(data []byte) []byte {
+	return append(append([]byte{}, utf8Bom...), data...)
+}
+
+const (
+	NormNfc  = "nfc"
+	NormNfd  = "nfd"
+	NormKeep = "keep"
+)
+
+// NormalizeName converts the file name to the unicode normalization form, NormNfc or NormNfd, NormKeep keeps
+// it. The names of the same characters in different forms, like the ones stored by HFS+ in NFD, are the same
+// after the normalization.
+func NormalizeName(name, form string) string {
+	switch form {
+	case NormNfc:
+		return norm.NFC.String(name)
+	case NormNfd:
+		return norm.NFD.String(name)
+	default:
+		return name
+	}
+}
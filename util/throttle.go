@@ -0,0 +1,121 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSyncedWriteRate is the files per second written to the cloud-synced folders if the rate is not set,
+// the sync clients fall behind or throttle on the bursts of the small files.
+const DefaultSyncedWriteRate = 100
+
+// ParseWriteRate parses the write rate like '200/s' for the files per second or '5MB/s' for the bytes per
+// second, the '/s' is optional. 0 means unlimited.
+func ParseWriteRate(s string) (files float64, bytes int64, err error) {
+	var v = strings.ToUpper(strings.TrimSpace(s))
+	v = strings.TrimSpace(strings.TrimSuffix(v, "/S"))
+	if v == "" {
+		return 0, 0, nil
+	}
+
+	if strings.ContainsAny(v, "BKMG") {
+		if bytes, err = ParseSize(v); err != nil {
+			return 0, 0, fmt.Errorf(T("invalid write rate '%s', like '200/s' or '5MB/s' expected"), s)
+		}
+		return 0, bytes, nil
+	}
+	files, err = strconv.ParseFloat(v, 64)
+	if err != nil || files < 0 {
+		return 0, 0, fmt.Errorf(T("invalid write rate '%s', like '200/s' or '5MB/s' expected"), s)
+	}
+
+	return files, 0, nil
+}
+
+// ThrottledOutput paces the writes to the output, for the network shares and the cloud-synced folders.
+type ThrottledOutput struct {
+	Output
+	files float64 // the files per second, 0 for unlimited
+	bytes int64   // the bytes per second, 0 for unlimited
+
+	mu   sync.Mutex
+	next time.Time // when the next write may start
+}
+
+// NewThrottledOutput returns the output writing at most files files or bytes bytes per second.
+func NewThrottledOutput(out Output, files float64, bytes int64) *ThrottledOutput {
+	return &ThrottledOutput{Output: out, files: files, bytes: bytes}
+}
+
+func (t *ThrottledOutput) WriteFile(name string, data []byte) error {
+	var cost time.Duration
+	if t.files > 0 {
+		cost = time.Duration(float64(time.Second) / t.files)
+	}
+	if t.bytes > 0 {
+		if c := time.Duration(float64(len(data)) / float64(t.bytes) * float64(time.Second)); c > cost {
+			cost = c
+		}
+	}
+
+	t.mu.Lock()
+	var now = time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	var wait = t.next.Sub(now)
+	t.next = t.next.Add(cost)
+	t.mu.Unlock()
+
+	time.Sleep(wait)
+
+	return t.Output.WriteFile(name, data)
+}
+
+// SyncedFolder returns the sync client of the folder, like 'OneDrive' and 'iCloud Drive', or "" if the folder
+// is not synced. The folders of the clients are found by the environment variables and the known locations.
+func SyncedFolder(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	var within = func(root string) bool {
+		if root == "" {
+			return false
+		}
+		rel, err := filepath.Rel(root, abs)
+		return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+	}
+
+	for _, env := range []string{"OneDrive", "OneDriveConsumer", "OneDriveCommercial"} {
+		if within(os.Getenv(env)) {
+			return "OneDrive"
+		}
+	}
+
+	var homeDir, _ = os.UserHomeDir()
+	if homeDir != "" && within(filepath.Join(homeDir, "Library/Mobile Documents")) {
+		return "iCloud Drive"
+	}
+	// the file providers of macOS, like 'OneDrive-Personal' and 'GoogleDrive-name'
+	if homeDir != "" && within(filepath.Join(homeDir, "Library/CloudStorage")) {
+		rel, _ := filepath.Rel(filepath.Join(homeDir, "Library/CloudStorage"), abs)
+		return strings.SplitN(filepath.ToSlash(rel), "/", 2)[0]
+	}
+	for _, part := range strings.Split(filepath.ToSlash(abs), "/") {
+		if part == "OneDrive" || strings.HasPrefix(part, "OneDrive - ") {
+			return "OneDrive"
+		}
+		if part == "iCloudDrive" || part == "iCloud Drive" {
+			return "iCloud Drive"
+		}
+	}
+
+	return ""
+}
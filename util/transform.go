@@ -0,0 +1,230 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// the types of the transforms
+const (
+	TransformReplace      = "replace"
+	TransformStripConsole = "strip-console"
+	TransformRedactTokens = "redact-tokens"
+)
+
+// the console methods stripped by default
+var defaultConsoleMethods = []string{"log", "debug", "info"}
+
+// Redacted replaces the values of the credentials redacted by TransformRedactTokens.
+const Redacted = "REDACTED"
+
+var redactRules = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH )?PRIVATE KEY-----[\s\S]*?-----END (?:RSA |EC |DSA |OPENSSH )?PRIVATE KEY-----`),
+	regexp.MustCompile(`\beyJ[\w-]{8,}\.eyJ[\w-]{8,}\.[\w-]{8,}`), // the json web tokens
+}
+
+// regCredentialValue matches the string values of the credential keys, like 'appSecret: "..."'
+var regCredentialValue = regexp.MustCompile(`(?i)(["']?[\w$]*(?:secret|passw(?:or)?d|token|api_?key|access_?key|private_?key|app_?key|signkey)[\w$]*["']?\s*[:=]\s*)(["'])([^"'\n]{4,})(["'])`)
+
+// Transform is a step of the transform pipeline applied to the matching text files when unpacking, see
+// ReadTransforms.
+type Transform struct {
+	Type        string   `json:"type"`                  // replace, strip-console or redact-tokens
+	Files       []string `json:"files,omitempty"`       // the globs like '*.js' or 'pages/**', all the text files if empty
+	Pattern     string   `json:"pattern,omitempty"`     // the regular expression to replace
+	Replacement string   `json:"replacement,omitempty"` // the replacement of the pattern, '$1' for the groups
+	Methods     []string `json:"methods,omitempty"`     // the console methods to strip, log, debug and info by default
+
+	reg     *regexp.Regexp
+	files   []*regexp.Regexp
+	console *regexp.Regexp
+}
+
+// ReadTransforms reads the json file of the transforms, like
+//
+//	[{"type": "strip-console", "files": ["*.js"]}, {"type": "replace", "pattern": "a", "replacement": "b"}]
+//
+// The transforms are applied in the order. The globs without '/' match the base names, '**' matches any
+// folders, and '*' matches the names in a folder.
+func ReadTransforms(name string) ([]Transform, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var transforms []Transform
+	if err := json.Unmarshal(data, &transforms); err != nil {
+		return nil, err
+	}
+
+	for i := range transforms {
+		var t = &transforms[i]
+		switch t.Type {
+		case TransformReplace:
+			if t.reg, err = regexp.Compile(t.Pattern); err != nil {
+				return nil, fmt.Errorf(T("transform %d: %w"), i+1, err)
+			}
+		case TransformStripConsole:
+			if len(t.Methods) == 0 {
+				t.Methods = defaultConsoleMethods
+			}
+			var methods = make([]string, len(t.Methods))
+			for j, m := range t.Methods {
+				methods[j] = regexp.QuoteMeta(m)
+			}
+			t.console = regexp.MustCompile(`\bconsole\s*\.\s*(?:` + strings.Join(methods, "|") + `)\s*\(`)
+		case TransformRedactTokens:
+		default:
+			return nil, fmt.Errorf(T("transform %d: unknown type '%s', 'replace', 'strip-console' or 'redact-tokens' expected"), i+1, t.Type)
+		}
+
+		for _, glob := range t.Files {
+			t.files = append(t.files, globRegexp(glob))
+		}
+	}
+
+	return transforms, nil
+}
+
+// Match reports whether the transform applies to the file, the slash path relative to the unpacked folder.
+func (t *Transform) Match(name string) bool {
+	if len(t.files) == 0 {
+		return true
+	}
+
+	name = strings.TrimPrefix(name, "/")
+	for _, reg := range t.files {
+		if reg.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Apply applies the transform to the text.
+func (t *Transform) Apply(data []byte) []byte {
+	switch t.Type {
+	case TransformReplace:
+		return t.reg.ReplaceAll(data, []byte(t.Replacement))
+	case TransformStripConsole:
+		return stripConsole(data, t.console)
+	case TransformRedactTokens:
+		for _, reg := range redactRules {
+			data = reg.ReplaceAll(data, []byte(Redacted))
+		}
+		return regCredentialValue.ReplaceAll(data, []byte("${1}${2}"+Redacted+"${4}"))
+	}
+
+	return data
+}
+
+// ApplyTransforms applies the matching transforms to the text file in the order, it returns the transformed
+// text and the types of the transforms which changed it.
+func ApplyTransforms(transforms []Transform, name string, data []byte) ([]byte, []string) {
+	var applied []string
+	for i := range transforms {
+		var t = &transforms[i]
+		if !t.Match(name) {
+			continue
+		}
+		if result := t.Apply(data); !bytes.Equal(result, data) {
+			data = result
+			applied = append(applied, t.Type)
+		}
+	}
+
+	return data, applied
+}
+
+// stripConsole replaces the console calls matched by reg with 'void 0', so that the code like
+// 'a && console.log(a)' is still valid.
+func stripConsole(code []byte, reg *regexp.Regexp) []byte {
+	var out bytes.Buffer
+	var last = 0
+	for _, loc := range reg.FindAllIndex(code, -1) {
+		if loc[0] < last || (loc[0] > 0 && code[loc[0]-1] == '.') {
+			continue // in the stripped call, or a property like 'a.console.log'
+		}
+		var end = matchParen(code, loc[1]-1)
+		if end < 0 {
+			continue
+		}
+		out.Write(code[last:loc[0]])
+		out.WriteString("void 0")
+		last = end + 1
+	}
+	out.Write(code[last:])
+
+	return out.Bytes()
+}
+
+// matchParen returns the index of the ')' closing the '(' at open out of the strings and the comments, -1 if
+// it's not closed.
+func matchParen(code []byte, open int) int {
+	var depth = 0
+	for i := open; i < len(code); i++ {
+		switch c := code[i]; c {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		case '"', '\'', '`':
+			for i++; i < len(code) && code[i] != c; i++ {
+				if code[i] == '\\' {
+					i++
+				}
+			}
+		case '/':
+			if bytes.HasPrefix(code[i:], []byte("//")) {
+				for i < len(code) && code[i] != '\n' {
+					i++
+				}
+			} else if bytes.HasPrefix(code[i:], []byte("/*")) {
+				var end = bytes.Index(code[i+2:], []byte("*/"))
+				if end < 0 {
+					return -1
+				}
+				i += end + 3
+			}
+		}
+	}
+
+	return -1
+}
+
+// globRegexp converts the glob to the regular expression of the slash paths, see ReadTransforms.
+func globRegexp(glob string) *regexp.Regexp {
+	glob = strings.TrimPrefix(path.Clean("/"+glob), "/")
+	var b strings.Builder
+	b.WriteString("^")
+	if !strings.Contains(glob, "/") {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			b.WriteString("[^/]*")
+		case glob[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+
+	return regexp.MustCompile(b.String())
+}
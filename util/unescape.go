@@ -0,0 +1,221 @@
+package util
+
+import (
+	"bytes"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// the extensions of the code UnescapeUnicode supports
+var unescapeExtensions = map[string]bool{".js": true, ".json": true, ".wxs": true}
+
+// CanUnescape reports whether UnescapeUnicode supports the file by the extension.
+func CanUnescape(name string) bool {
+	return unescapeExtensions[strings.ToLower(filepath.Ext(name))]
+}
+
+// UnescapeUnicode decodes the unicode escapes like '\u4e2d' in the string literals of the UTF-8 js or json
+// code, so that the text like the chinese can be searched. Only the printable non-ascii characters are decoded,
+// the escapes of the quotes, the control characters and the line terminators are kept so the code is still
+// valid, and so are the escapes out of the strings like in the comments and the regular expressions.
+func UnescapeUnicode(code []byte) []byte {
+	if !bytes.Contains(code, []byte(`\u`)) {
+		return code
+	}
+
+	var out = make([]byte, 0, len(code))
+	var templates []int // the brace depths of the open '${' in the template literals
+	var regex = true    // a '/' starts a regular expression rather than a division
+	var i = 0
+	for i < len(code) {
+		var c = code[i]
+		switch {
+		case c == '/' && i+1 < len(code) && code[i+1] == '/':
+			var end = bytes.IndexByte(code[i:], '\n')
+			if end < 0 {
+				end = len(code) - i
+			}
+			out = append(out, code[i:i+end]...)
+			i += end
+		case c == '/' && i+1 < len(code) && code[i+1] == '*':
+			var end = bytes.Index(code[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(code) - i
+			} else {
+				end += 4
+			}
+			out = append(out, code[i:i+end]...)
+			i += end
+		case c == '/' && regex:
+			var end = regexEnd(code, i)
+			out = append(out, code[i:end]...)
+			i, regex = end, false
+		case c == '"' || c == '\'':
+			out, i = unescapeQuoted(code, i+1, out, c)
+			regex = false
+		case c == '`' || (c == '}' && len(templates) > 0 && templates[len(templates)-1] == 0):
+			if c == '}' {
+				templates = templates[:len(templates)-1]
+			}
+			var expr bool
+			out, i, expr = unescapeTemplate(code, i+1, out, c)
+			if expr {
+				templates = append(templates, 0)
+			}
+			regex = expr
+		case isIdentChar(c):
+			var end = i + 1
+			for end < len(code) && isIdentChar(code[end]) {
+				end++
+			}
+			out = append(out, code[i:end]...)
+			// the expressions follow the keywords like 'return /a/.test(s)'
+			regex = jsKeywords[string(code[i:end])] && string(code[i:end]) != "this"
+			i = end
+		default:
+			switch c {
+			case '{':
+				if len(templates) > 0 {
+					templates[len(templates)-1]++
+				}
+			case '}':
+				if len(templates) > 0 {
+					templates[len(templates)-1]--
+				}
+			}
+			if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+				regex = c != ')' && c != ']' && c != '}'
+			}
+			out = append(out, c)
+			i++
+		}
+	}
+
+	return out
+}
+
+// unescapeQuoted copies the string literal quoted by quote from code[start] after the opening quote to out,
+// it returns the position after the closing quote. The unterminated string ends at the line break.
+func unescapeQuoted(code []byte, start int, out []byte, quote byte) ([]byte, int) {
+	out = append(out, quote)
+	var i = start
+	for i < len(code) {
+		switch code[i] {
+		case '\\':
+			out, i = unescapeSequence(code, i, out)
+		case quote:
+			return append(out, quote), i + 1
+		case '\n':
+			return out, i
+		default:
+			out = append(out, code[i])
+			i++
+		}
+	}
+
+	return out, i
+}
+
+// unescapeTemplate copies the text of the template literal from code[start] after '`' or the '}' ending an
+// expression, opening, to out. It returns the position after the closing '`', or after '${' with expr true.
+func unescapeTemplate(code []byte, start int, out []byte, opening byte) ([]byte, int, bool) {
+	out = append(out, opening)
+	var i = start
+	for i < len(code) {
+		switch {
+		case code[i] == '\\':
+			out, i = unescapeSequence(code, i, out)
+		case code[i] == '`':
+			return append(out, '`'), i + 1, false
+		case code[i] == '$' && i+1 < len(code) && code[i+1] == '{':
+			return append(out, "${"...), i + 2, true
+		default:
+			out = append(out, code[i])
+			i++
+		}
+	}
+
+	return out, i, false
+}
+
+// unescapeSequence copies the escape sequence at code[start] to out, decoded if it's the unicode escape of a
+// printable non-ascii character, the surrogate pairs like '\ud83d\ude00' are decoded together.
+func unescapeSequence(code []byte, start int, out []byte) ([]byte, int) {
+	r, end := unicodeEscape(code, start)
+	if r >= 0 && utf16.IsSurrogate(r) {
+		if low, lowEnd := unicodeEscape(code, end); low >= 0 {
+			if pair := utf16.DecodeRune(r, low); pair != utf8.RuneError {
+				r, end = pair, lowEnd
+			}
+		}
+	}
+	if r >= utf8.RuneSelf && unicode.IsGraphic(r) {
+		return utf8.AppendRune(out, r), end
+	}
+
+	// the other escapes are kept, the escaped character is copied with the backslash
+	if start+1 < len(code) {
+		_, size := utf8.DecodeRune(code[start+1:])
+		return append(out, code[start:start+1+size]...), start + 1 + size
+	}
+
+	return append(out, code[start]), start + 1
+}
+
+// unicodeEscape parses the unicode escape like '\u4e2d' or '\u{4e2d}' at code[start], it returns -1 if not.
+func unicodeEscape(code []byte, start int) (rune, int) {
+	if start+1 >= len(code) || code[start] != '\\' || code[start+1] != 'u' {
+		return -1, start
+	}
+
+	var hex []byte
+	var end int
+	if start+2 < len(code) && code[start+2] == '{' {
+		var close = bytes.IndexByte(code[start+3:], '}')
+		if close < 1 || close > 6 {
+			return -1, start
+		}
+		hex, end = code[start+3:start+3+close], start+4+close
+	} else {
+		if start+6 > len(code) {
+			return -1, start
+		}
+		hex, end = code[start+2:start+6], start+6
+	}
+
+	n, err := strconv.ParseUint(string(hex), 16, 32)
+	if err != nil || n > unicode.MaxRune {
+		return -1, start
+	}
+
+	return rune(n), end
+}
+
+// regexEnd returns the end of the regular expression starting at code[start], after the flags. The '/' in
+// the character classes like '[/]' doesn't end it, and neither does the line break.
+func regexEnd(code []byte, start int) int {
+	var class = false
+	var i = start + 1
+	for ; i < len(code) && code[i] != '\n'; i++ {
+		switch code[i] {
+		case '\\':
+			i++
+		case '[':
+			class = true
+		case ']':
+			class = false
+		case '/':
+			if !class {
+				for i++; i < len(code) && isIdentChar(code[i]); i++ {
+				}
+				return i
+			}
+		}
+	}
+
+	return i
+}
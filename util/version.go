@@ -0,0 +1,110 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VersionFilter selects the version folders of the mini programs by the names or the ranges, like '12',
+// '10-15', '>=12' or '<2.1'. The empty filter selects all the versions.
+type VersionFilter []versionRule
+
+type versionRule struct {
+	op      string // '=', '<', '<=', '>', '>=' or '-' for the range
+	version string
+	to      string // the end of the range, inclusive
+}
+
+// ParseVersionFilter parses the version specs, a version is selected if any spec matches it.
+func ParseVersionFilter(specs []string) (VersionFilter, error) {
+	var filter VersionFilter
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+
+		var rule versionRule
+		for _, op := range []string{">=", "<=", ">", "<", "="} {
+			if strings.HasPrefix(spec, op) {
+				rule = versionRule{op: op, version: strings.TrimSpace(spec[len(op):])}
+				break
+			}
+		}
+		if rule.op == "" {
+			if from, to, ok := strings.Cut(spec, "-"); ok {
+				rule = versionRule{op: "-", version: strings.TrimSpace(from), to: strings.TrimSpace(to)}
+			} else {
+				rule = versionRule{op: "=", version: spec}
+			}
+		}
+
+		if rule.version == "" || (rule.op == "-" && rule.to == "") {
+			return nil, fmt.Errorf(T("invalid version filter '%s', like '12', '10-15' or '>=12' expected"), spec)
+		}
+		filter = append(filter, rule)
+	}
+
+	return filter, nil
+}
+
+// Match reports whether the version folder name is selected.
+func (f VersionFilter) Match(name string) bool {
+	if len(f) == 0 {
+		return true
+	}
+
+	for _, rule := range f {
+		var c = CompareVersions(name, rule.version)
+		var ok bool
+		switch rule.op {
+		case "=":
+			ok = name == rule.version || c == 0
+		case "<":
+			ok = c < 0
+		case "<=":
+			ok = c <= 0
+		case ">":
+			ok = c > 0
+		case ">=":
+			ok = c >= 0
+		case "-":
+			ok = c >= 0 && CompareVersions(name, rule.to) <= 0
+		}
+		if ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CompareVersions compares the versions like '12' or '2.19.4' by the dot separated parts, the numeric parts
+// are compared as numbers and the others as strings. It returns -1, 0 or 1.
+func CompareVersions(a, b string) int {
+	var as, bs = strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y = "0", "0"
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+
+		xn, xErr := strconv.ParseUint(x, 10, 64)
+		yn, yErr := strconv.ParseUint(y, 10, 64)
+		switch {
+		case xErr == nil && yErr == nil && xn != yn:
+			if xn < yn {
+				return -1
+			}
+			return 1
+		case (xErr != nil || yErr != nil) && x != y:
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
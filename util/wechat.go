@@ -0,0 +1,69 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// WechatClient is the wechat client installed on this machine.
+type WechatClient struct {
+	Path    string
+	Version string // like '3.9.10.19', empty if unknown
+}
+
+var regClientVersion = regexp.MustCompile(`^\d+(\.\d+){1,3}$`)
+
+// FindWechatClient returns the installed wechat client, ok is false if it's not found.
+func FindWechatClient() (client WechatClient, ok bool) {
+	if runtime.GOOS == "windows" {
+		return findWindowsClient()
+	}
+	if runtime.GOOS != "darwin" {
+		return WechatClient{}, false
+	}
+
+	for _, app := range []string{"/Applications/WeChat.app", "/Applications/微信.app"} {
+		data, err := os.ReadFile(filepath.Join(app, "Contents/Info.plist"))
+		if err != nil {
+			continue
+		}
+		client = WechatClient{Path: app}
+		if match := regPlistVersion.FindSubmatch(data); match != nil {
+			client.Version = string(match[1])
+		}
+		return client, true
+	}
+
+	return WechatClient{}, false
+}
+
+var regPlistVersion = regexp.MustCompile(`<key>CFBundleShortVersionString</key>\s*<string>([^<]+)</string>`)
+
+// Major returns the major version of the client, 0 if unknown.
+func (c WechatClient) Major() int {
+	major, _ := strconv.Atoi(strings.SplitN(c.Version, ".", 2)[0])
+
+	return major
+}
+
+// latestVersionDir returns the name of the latest version folder in dir, like '4.0.1.17', the wechat 4.x
+// installs into them.
+func latestVersionDir(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	var latest = ""
+	for _, e := range entries {
+		if e.IsDir() && regClientVersion.MatchString(e.Name()) && (latest == "" || CompareVersions(e.Name(), latest) > 0) {
+			latest = e.Name()
+		}
+	}
+
+	return latest
+}
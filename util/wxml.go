@@ -0,0 +1,173 @@
+package util
+
+import (
+	"strings"
+)
+
+// WxmlNode is an element or a text of the wxml, the texts have no tag.
+type WxmlNode struct {
+	Tag      string
+	Attrs    []WxmlAttr
+	Text     string
+	Line     int
+	Children []*WxmlNode
+}
+
+type WxmlAttr struct {
+	Name  string
+	Value string
+}
+
+// Attr returns the value of the attribute, "" if it's not set.
+func (n *WxmlNode) Attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name == name {
+			return a.Value
+		}
+	}
+
+	return ""
+}
+
+// the elements without the children, the end tags are optional
+var wxmlVoidTags = map[string]bool{"import": true, "include": true, "input": true, "icon": true}
+
+// ParseWxml parses the wxml leniently, the unclosed elements are closed by the end tags of their ancestors or
+// the end of the file, and the stray end tags are ignored. The '<' in the data bindings like '{{a < b}}' is
+// not a tag, and the content of 'wxs' is kept as the text.
+func ParseWxml(data []byte) []*WxmlNode {
+	var s = string(data)
+	var root = &WxmlNode{}
+	var stack = []*WxmlNode{root}
+	var line, counted = 1, 0
+	var lineAt = func(pos int) int {
+		line += strings.Count(s[counted:pos], "\n")
+		counted = pos
+		return line
+	}
+
+	var i = 0
+	for i < len(s) {
+		var top = stack[len(stack)-1]
+		switch {
+		case strings.HasPrefix(s[i:], "<!--"):
+			var end = strings.Index(s[i+4:], "-->")
+			if end < 0 {
+				return root.Children
+			}
+			i += end + 7
+		case strings.HasPrefix(s[i:], "</"):
+			var end = strings.IndexByte(s[i:], '>')
+			if end < 0 {
+				end = len(s) - i - 1
+			}
+			var tag = strings.TrimSpace(s[i+2 : i+end])
+			for j := len(stack) - 1; j > 0; j-- {
+				if stack[j].Tag == tag {
+					stack = stack[:j]
+					break
+				}
+			}
+			i += end + 1
+		case s[i] == '<' && i+1 < len(s) && isTagChar(s[i+1]):
+			var node = &WxmlNode{Line: lineAt(i)}
+			var selfClosing bool
+			i, selfClosing = parseWxmlTag(s, i+1, node)
+			top.Children = append(top.Children, node)
+			if node.Tag == "wxs" && !selfClosing {
+				var end = strings.Index(s[i:], "</wxs>")
+				if end < 0 {
+					end = len(s) - i
+				}
+				node.Children = []*WxmlNode{{Text: s[i : i+end], Line: lineAt(i)}}
+				i += end
+			} else if !selfClosing && !wxmlVoidTags[node.Tag] {
+				stack = append(stack, node)
+			}
+		default:
+			var end = wxmlTextEnd(s, i)
+			top.Children = append(top.Children, &WxmlNode{Text: s[i:end], Line: lineAt(i)})
+			i = end
+		}
+	}
+
+	return root.Children
+}
+
+// parseWxmlTag parses the tag name and the attributes after '<' at s[start], it returns the position after
+// '>' and whether the tag is closed by '/>'.
+func parseWxmlTag(s string, start int, node *WxmlNode) (int, bool) {
+	var i = start
+	for i < len(s) && isTagChar(s[i]) {
+		i++
+	}
+	node.Tag = s[start:i]
+
+	for i < len(s) {
+		switch c := s[i]; {
+		case c == '>':
+			return i + 1, false
+		case c == '/' && i+1 < len(s) && s[i+1] == '>':
+			return i + 2, true
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '/':
+			i++
+		default:
+			var nameStart = i
+			for i < len(s) && !strings.ContainsRune(" \t\r\n=>/", rune(s[i])) {
+				i++
+			}
+			var attr = WxmlAttr{Name: s[nameStart:i]}
+			for i < len(s) && strings.ContainsRune(" \t\r\n", rune(s[i])) {
+				i++
+			}
+			if i < len(s) && s[i] == '=' {
+				for i++; i < len(s) && strings.ContainsRune(" \t\r\n", rune(s[i])); i++ {
+				}
+				if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+					var end = strings.IndexByte(s[i+1:], s[i])
+					if end < 0 {
+						end = len(s) - i - 1
+					}
+					attr.Value = s[i+1 : i+1+end]
+					i += end + 2
+				} else {
+					var valueStart = i
+					for i < len(s) && !strings.ContainsRune(" \t\r\n>", rune(s[i])) {
+						i++
+					}
+					attr.Value = s[valueStart:i]
+				}
+			}
+			if attr.Name != "" {
+				node.Attrs = append(node.Attrs, attr)
+			}
+		}
+	}
+
+	return len(s), false
+}
+
+// wxmlTextEnd returns the end of the text at s[start], before the next tag out of the data bindings.
+func wxmlTextEnd(s string, start int) int {
+	var i = start
+	for i < len(s) {
+		if strings.HasPrefix(s[i:], "{{") {
+			var end = strings.Index(s[i+2:], "}}")
+			if end < 0 {
+				return len(s)
+			}
+			i += end + 4
+			continue
+		}
+		if s[i] == '<' && i > start {
+			return i
+		}
+		i++
+	}
+
+	return len(s)
+}
+
+func isTagChar(c byte) bool {
+	return isIdentChar(c) && c != '$' || c == '-' || c == ':' || c == '.'
+}
@@ -0,0 +1,76 @@
+package util
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// the WinZip AES encryption, see https://www.winzip.com/en/support/aes-encryption/
+const (
+	zipMethodAes   = 99
+	zipAesKeySize  = 32 // AES-256
+	zipAesSaltSize = 16
+	zipAesMacSize  = 10
+)
+
+// zipAesEncrypt compresses the data with deflate and encrypts it with AES-256 in the AE-2 format,
+// returns the raw data of the entry and the extra field.
+func zipAesEncrypt(password string, data []byte, level int) ([]byte, []byte, error) {
+	var compressed bytes.Buffer
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+	w, err := flate.NewWriter(&compressed, level)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, _ = w.Write(data)
+	if err := w.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	var salt = make([]byte, zipAesSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, err
+	}
+
+	var keys = pbkdf2.Key([]byte(password), salt, 1000, 2*zipAesKeySize+2, sha1.New)
+	var aesKey, macKey, verifier = keys[:zipAesKeySize], keys[zipAesKeySize : 2*zipAesKeySize], keys[2*zipAesKeySize:]
+
+	block, err := aes.NewCipher(aesKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// AES-CTR with the little endian counter starting from 1
+	var encrypted = compressed.Bytes()
+	var counter, stream = make([]byte, aes.BlockSize), make([]byte, aes.BlockSize)
+	for i := 0; i < len(encrypted); i += aes.BlockSize {
+		binary.LittleEndian.PutUint64(counter, uint64(i/aes.BlockSize+1))
+		block.Encrypt(stream, counter)
+		for j := i; j < i+aes.BlockSize && j < len(encrypted); j++ {
+			encrypted[j] ^= stream[j-i]
+		}
+	}
+
+	var mac = hmac.New(sha1.New, macKey)
+	mac.Write(encrypted)
+
+	var raw = make([]byte, 0, len(salt)+len(verifier)+len(encrypted)+zipAesMacSize)
+	raw = append(raw, salt...)
+	raw = append(raw, verifier...)
+	raw = append(raw, encrypted...)
+	raw = append(raw, mac.Sum(nil)[:zipAesMacSize]...)
+
+	// header id, data size, version AE-2, vendor 'AE', strength AES-256, the actual compression method
+	var extra = []byte{0x01, 0x99, 7, 0, 2, 0, 'A', 'E', 3, 8, 0}
+
+	return raw, extra, nil
+}
@@ -0,0 +1,135 @@
+package wxapkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
+
+// minCarvedText is the minimum length of the carved text, the shorter ones are more likely to be garbage.
+const minCarvedText = 32
+
+var (
+	pngMagic  = []byte("\x89PNG\r\n\x1a\n")
+	pngEnd    = []byte("IEND\xae\x42\x60\x82")
+	jpegMagic = []byte("\xff\xd8\xff")
+	jpegEnd   = []byte("\xff\xd9")
+	gifMagic  = [][]byte{[]byte("GIF87a"), []byte("GIF89a")}
+	gifEnd    = []byte("\x00\x3b")
+)
+
+// Carve finds the files in the body of the wxapkg file with the broken index by their signatures, the PNG,
+// JPEG and GIF images, the JSON values and the text like js, html and wxss, from start. The entries have
+// the synthetic names like '/carved/00000e2c.png' with the offset in the data.
+func Carve(data []byte, start int64) []Entry {
+	var entries []Entry
+	var add = func(offset, end int, ext string) {
+		entries = append(entries, Entry{
+			Name:   fmt.Sprintf("/carved/%08x%s", offset, ext),
+			Offset: uint32(offset),
+			Size:   uint32(end - offset),
+		})
+	}
+
+	var pos = int(start)
+	if pos < 0 || pos > len(data) {
+		pos = 0
+	}
+	for pos < len(data) {
+		var rest = data[pos:]
+		if end, ext := carveImage(rest); end > 0 {
+			add(pos, pos+end, ext)
+			pos += end
+			continue
+		}
+		if end := carveJson(rest); end > 0 {
+			add(pos, pos+end, ".json")
+			pos += end
+			continue
+		}
+		if end := carveText(rest); end >= minCarvedText {
+			add(pos, pos+end, textExt(rest[:end]))
+			pos += end
+			continue
+		}
+		pos++
+	}
+
+	return entries
+}
+
+// carveImage returns the length and the extension of the image at the beginning of data, 0 if none.
+func carveImage(data []byte) (int, string) {
+	var find = func(end []byte) int {
+		if i := bytes.Index(data, end); i >= 0 {
+			return i + len(end)
+		}
+		return 0
+	}
+
+	switch {
+	case bytes.HasPrefix(data, pngMagic):
+		return find(pngEnd), ".png"
+	case bytes.HasPrefix(data, jpegMagic):
+		return find(jpegEnd), ".jpg"
+	case bytes.HasPrefix(data, gifMagic[0]), bytes.HasPrefix(data, gifMagic[1]):
+		return find(gifEnd), ".gif"
+	}
+
+	return 0, ""
+}
+
+// carveJson returns the length of the JSON object or array at the beginning of data, 0 if none.
+func carveJson(data []byte) int {
+	if len(data) == 0 || (data[0] != '{' && data[0] != '[') {
+		return 0
+	}
+
+	var decoder = json.NewDecoder(bytes.NewReader(data))
+	var raw json.RawMessage
+	if err := decoder.Decode(&raw); err != nil {
+		return 0
+	}
+
+	return int(decoder.InputOffset())
+}
+
+// carveText returns the length of the text at the beginning of data, it ends at the invalid UTF-8, the
+// control characters or the image signatures.
+func carveText(data []byte) int {
+	var n = 0
+	for n < len(data) {
+		if end, _ := carveImage(data[n:]); end > 0 {
+			break
+		}
+
+		r, size := utf8.DecodeRune(data[n:])
+		if r == utf8.RuneError && size <= 1 {
+			break
+		}
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			break
+		}
+		n += size
+	}
+
+	return n
+}
+
+// textExt guesses the extension of the carved text.
+func textExt(text []byte) string {
+	switch {
+	case bytes.Contains(text, []byte("<!DOCTYPE")) || bytes.Contains(text, []byte("<html")):
+		return ".html"
+	case bytes.Contains(text, []byte("<view")) || bytes.Contains(text, []byte("<template")):
+		return ".wxml"
+	case bytes.Contains(text, []byte("function")) || bytes.Contains(text, []byte("define(")) ||
+		bytes.Contains(text, []byte("var ")):
+		return ".js"
+	case bytes.Contains(text, []byte("{")) && bytes.Contains(text, []byte(":")) && bytes.Contains(text, []byte(";")):
+		return ".wxss"
+	}
+
+	return ".txt"
+}
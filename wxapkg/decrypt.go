@@ -0,0 +1,244 @@
+package wxapkg
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	EncryptedHeadSize = 6 + 1024 // 'V1MMWX' + the AES encrypted 1024 bytes
+	DecryptedHeadSize = 1023     // the last byte of the AES block is padding
+)
+
+var (
+	// ErrTooSmall is returned when the encrypted file is smaller than the encrypted head.
+	ErrTooSmall = errors.New("wxapkg: the encrypted file is too small")
+	// ErrWxid is returned when the file can't be decrypted with the wxid.
+	ErrWxid = errors.New("wxapkg: failed to decrypt, please check the wxid")
+)
+
+var encryptedMark = []byte("V1MMWX")
+
+// IsEncrypted reports whether the data is the head of an encrypted wxapkg file, only the PC wechat on windows
+// encrypts them.
+func IsEncrypted(head []byte) bool {
+	return bytes.HasPrefix(head, encryptedMark)
+}
+
+// Decrypt decrypts the encrypted wxapkg file in memory with the DefaultProfile.
+func Decrypt(wxid string, data []byte) ([]byte, error) {
+	return DefaultProfile.Decrypt(wxid, data)
+}
+
+// Decrypt decrypts the encrypted wxapkg file in memory, the data is returned as is if the profile
+// is not encrypted.
+func (p *Profile) Decrypt(wxid string, data []byte) ([]byte, error) {
+	if !p.Encrypted() {
+		return data, nil
+	}
+
+	var headSize = p.headSize()
+	if len(data) < headSize {
+		return nil, ErrTooSmall
+	}
+
+	originData := make([]byte, DecryptedHeadSize, len(data)-headSize+DecryptedHeadSize)
+	copy(originData, p.decryptHead(wxid, data[len(p.Mark):headSize]))
+
+	var key = xorKey(wxid)
+	for _, b := range data[headSize:] { // from 6 + 1024 byte
+		originData = append(originData, b^key)
+	}
+
+	if originData[0] != 0xBE {
+		return nil, ErrWxid
+	}
+
+	return originData, nil
+}
+
+// Encrypt encrypts the decrypted wxapkg file in memory with the DefaultProfile, the inverse of Decrypt.
+func Encrypt(wxid string, data []byte) ([]byte, error) {
+	return DefaultProfile.Encrypt(wxid, data)
+}
+
+// Encrypt encrypts the decrypted wxapkg file in memory, so that the client of the profile can load it, the
+// data is returned as is if the profile is not encrypted. The files smaller than the encrypted head are
+// padded with zeros.
+func (p *Profile) Encrypt(wxid string, data []byte) ([]byte, error) {
+	if !p.Encrypted() {
+		return data, nil
+	}
+	if len(data) == 0 || data[0] != 0xBE {
+		return nil, ErrFormat
+	}
+
+	var plain = make([]byte, 1024)
+	copy(plain, data)
+	plain[1023] = 1 // the PKCS#7 padding of the 1023 bytes
+	var rest []byte
+	if len(data) > DecryptedHeadSize {
+		rest = data[DecryptedHeadSize:]
+	}
+
+	var result = make([]byte, 0, p.headSize()+len(rest))
+	result = append(result, p.Mark...)
+	result = append(result, p.encryptHead(wxid, plain)...)
+
+	var key = xorKey(wxid)
+	for _, b := range rest {
+		result = append(result, b^key)
+	}
+
+	return result, nil
+}
+
+// DecryptReader returns a reader decrypting the encrypted wxapkg file read from r on the fly with
+// the DefaultProfile.
+func DecryptReader(r io.Reader, wxid string) (io.Reader, error) {
+	return DefaultProfile.DecryptReader(r, wxid)
+}
+
+// DecryptReader returns a reader decrypting the encrypted wxapkg file read from r on the fly, r is
+// returned as is if the profile is not encrypted.
+func (p *Profile) DecryptReader(r io.Reader, wxid string) (io.Reader, error) {
+	if !p.Encrypted() {
+		return r, nil
+	}
+
+	var encrypted = make([]byte, p.headSize())
+	if _, err := io.ReadFull(r, encrypted); err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, ErrTooSmall
+	} else if err != nil {
+		return nil, err
+	}
+
+	var head = p.decryptHead(wxid, encrypted[len(p.Mark):])
+	if head[0] != 0xBE {
+		return nil, ErrWxid
+	}
+
+	return io.MultiReader(bytes.NewReader(head), &xorReader{r: r, key: xorKey(wxid)}), nil
+}
+
+type xorReader struct {
+	r   io.Reader
+	key byte
+}
+
+func (x *xorReader) Read(p []byte) (int, error) {
+	n, err := x.r.Read(p)
+	for i := 0; i < n; i++ {
+		p[i] ^= x.key
+	}
+
+	return n, err
+}
+
+// Decrypter decrypts the encrypted wxapkg file on demand.
+type Decrypter struct {
+	r      io.ReaderAt
+	head   []byte // the decrypted head
+	shift  int64  // the size of the encrypted head minus the decrypted one
+	xorKey byte
+	size   int64
+}
+
+// NewDecrypter returns a Decrypter reading the encrypted wxapkg file from r, which has the given size,
+// with the DefaultProfile.
+func NewDecrypter(r io.ReaderAt, size int64, wxid string) (*Decrypter, error) {
+	return DefaultProfile.NewDecrypter(r, size, wxid)
+}
+
+// NewDecrypter returns a Decrypter reading the encrypted wxapkg file from r, which has the given size.
+// The Decrypter reads r as is if the profile is not encrypted.
+func (p *Profile) NewDecrypter(r io.ReaderAt, size int64, wxid string) (*Decrypter, error) {
+	if !p.Encrypted() {
+		return &Decrypter{r: r, size: size}, nil
+	}
+
+	var headSize = int64(p.headSize())
+	if size < headSize {
+		return nil, ErrTooSmall
+	}
+
+	var encrypted = make([]byte, headSize)
+	if _, err := r.ReadAt(encrypted, 0); err != nil {
+		return nil, err
+	}
+
+	var d = &Decrypter{
+		r:      r,
+		head:   p.decryptHead(wxid, encrypted[len(p.Mark):]),
+		shift:  headSize - DecryptedHeadSize,
+		xorKey: xorKey(wxid),
+		size:   size - headSize + DecryptedHeadSize,
+	}
+	if d.head[0] != 0xBE {
+		return nil, ErrWxid
+	}
+
+	return d, nil
+}
+
+// Size returns the size of the decrypted file.
+func (d *Decrypter) Size() int64 {
+	return d.size
+}
+
+func (d *Decrypter) ReadAt(p []byte, off int64) (int, error) {
+	if off >= d.size {
+		return 0, io.EOF
+	}
+
+	var n = 0
+	if off < int64(len(d.head)) {
+		n = copy(p, d.head[off:])
+		off += int64(n)
+	}
+	if n == len(p) {
+		return n, nil
+	}
+
+	// the rest are xor encrypted, with the offset of the encrypted head
+	m, err := d.r.ReadAt(p[n:], off+d.shift)
+	for i := n; i < n+m; i++ {
+		p[i] ^= d.xorKey
+	}
+
+	return n + m, err
+}
+
+func (p *Profile) decryptHead(wxid string, encrypted []byte) []byte {
+	dk := pbkdf2.Key([]byte(wxid), []byte(p.Salt), p.Iterations, 32, sha1.New)
+	block, _ := aes.NewCipher(dk)
+	blockMode := cipher.NewCBCDecrypter(block, []byte(p.IV))
+	originData := make([]byte, 1024)
+	blockMode.CryptBlocks(originData, encrypted[:1024])
+
+	return originData[:DecryptedHeadSize]
+}
+
+func (p *Profile) encryptHead(wxid string, plain []byte) []byte {
+	dk := pbkdf2.Key([]byte(wxid), []byte(p.Salt), p.Iterations, 32, sha1.New)
+	block, _ := aes.NewCipher(dk)
+	blockMode := cipher.NewCBCEncrypter(block, []byte(p.IV))
+	encrypted := make([]byte, 1024)
+	blockMode.CryptBlocks(encrypted, plain)
+
+	return encrypted
+}
+
+func xorKey(wxid string) byte {
+	if len(wxid) >= 2 {
+		return wxid[len(wxid)-2]
+	}
+
+	return 0x66
+}
@@ -0,0 +1,204 @@
+package wxapkg
+
+import (
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FS is a read-only file system over the entries of a decrypted wxapkg file, the leading '/' of the
+// entry names is removed, e.g. '/app-service.js' is opened as 'app-service.js'.
+type FS struct {
+	files  map[string]*File
+	dirs   map[string][]fs.DirEntry
+	closer io.Closer
+}
+
+var (
+	_ fs.ReadFileFS = (*FS)(nil)
+	_ fs.ReadDirFS  = (*FS)(nil)
+	_ fs.StatFS     = (*FS)(nil)
+)
+
+// NewFS returns a FS reading the decrypted wxapkg file from r, which has the given size.
+func NewFS(r io.ReaderAt, size int64) (*FS, error) {
+	reader, err := NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return newFS(reader), nil
+}
+
+// OpenFS opens the wxapkg file as a FS, it's decrypted with the wxid if encrypted.
+func OpenFS(name, wxid string) (*FS, error) {
+	rc, err := openReader(name, wxid)
+	if err != nil {
+		return nil, err
+	}
+
+	var f = newFS(&rc.Reader)
+	f.closer = rc
+
+	return f, nil
+}
+
+func newFS(r *Reader) *FS {
+	var f = &FS{files: make(map[string]*File), dirs: map[string][]fs.DirEntry{".": nil}}
+	for _, file := range r.File {
+		var name = strings.TrimPrefix(path.Clean("/"+file.Name), "/")
+		if !fs.ValidPath(name) || name == "." {
+			continue
+		}
+		if _, ok := f.files[name]; ok { // the first one wins like the extraction
+			continue
+		}
+		f.files[name] = file
+		f.addDirEntry(name, fs.FileInfoToDirEntry(file.FileInfo()))
+	}
+
+	for _, list := range f.dirs {
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Name() < list[j].Name()
+		})
+	}
+
+	return f
+}
+
+// Close closes the wxapkg file opened by OpenFS.
+func (f *FS) Close() error {
+	if f.closer == nil {
+		return nil
+	}
+
+	return f.closer.Close()
+}
+
+// addDirEntry adds the entry to its parent folder, and the missing parents to theirs.
+func (f *FS) addDirEntry(name string, entry fs.DirEntry) {
+	var dir = path.Dir(name)
+	if _, ok := f.dirs[dir]; !ok {
+		f.dirs[dir] = nil
+		f.addDirEntry(dir, fs.FileInfoToDirEntry(&fileInfo{name: path.Base(dir), dir: true}))
+	}
+	f.dirs[dir] = append(f.dirs[dir], entry)
+}
+
+func (f *FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if e, ok := f.files[name]; ok {
+		r, err := e.section()
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		return &file{SectionReader: r, info: &fileInfo{name: path.Base(name), size: int64(e.Size)}}, nil
+	}
+
+	if entries, ok := f.dirs[name]; ok {
+		return &dir{info: &fileInfo{name: path.Base(name), dir: true}, entries: entries}, nil
+	}
+
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	file, err := f.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if _, ok := file.(*dir); ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+
+	return io.ReadAll(file)
+}
+
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	entries, ok := f.dirs[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return append([]fs.DirEntry{}, entries...), nil
+}
+
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	file, err := f.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return file.Stat()
+}
+
+type fileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i *fileInfo) Name() string       { return i.name }
+func (i *fileInfo) Size() int64        { return i.size }
+func (i *fileInfo) ModTime() time.Time { return time.Time{} }
+func (i *fileInfo) IsDir() bool        { return i.dir }
+func (i *fileInfo) Sys() interface{}   { return nil }
+
+func (i *fileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+
+	return 0444
+}
+
+type file struct {
+	*io.SectionReader
+	info *fileInfo
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *file) Close() error               { return nil }
+
+type dir struct {
+	info    *fileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *dir) Close() error               { return nil }
+
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	var rest = d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return append([]fs.DirEntry{}, rest...), nil
+	}
+
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+
+	return append([]fs.DirEntry{}, rest[:n]...), nil
+}
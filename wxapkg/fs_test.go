@@ -0,0 +1,67 @@
+package wxapkg
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// testFiles are the files of the package built by buildPackage, the large one spans the encrypted head.
+var testFiles = map[string]string{
+	"/app-config.json":        `{"pages":["pages/index/index"]}`,
+	"/app-service.js":         strings.Repeat("var a = 1;\n", 200),
+	"/pages/index/index.wxml": "<view>index</view>",
+	"/pages/index/index.wxss": ".a{color:red}",
+	"/static/empty.txt":       "",
+}
+
+// buildPackage builds the decrypted wxapkg file of the files.
+func buildPackage(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var names = make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var p Packer
+	for _, name := range names {
+		p.AddEntry(name, p.AddBody([]byte(files[name])))
+	}
+
+	var buf bytes.Buffer
+	if _, err := p.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestFS(t *testing.T) {
+	var data = buildPackage(t, testFiles)
+	fsys, err := NewFS(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected []string
+	for name := range testFiles {
+		expected = append(expected, strings.TrimPrefix(name, "/"))
+	}
+	if err := fstest.TestFS(fsys, expected...); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, body := range testFiles {
+		got, err := fsys.ReadFile(strings.TrimPrefix(name, "/"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != body {
+			t.Errorf("%s: got %d bytes, want %d", name, len(got), len(body))
+		}
+	}
+}
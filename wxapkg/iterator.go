@@ -0,0 +1,137 @@
+package wxapkg
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Iterator reads the entries of a decrypted wxapkg file one by one from a stream, like tar.Reader. The
+// entries are returned in the order of their offsets, so only one body is in memory at a time. The entries
+// sharing a body get the same body, and the placeholders without a body are skipped, see Entry.IsPlaceholder.
+type Iterator struct {
+	Header *Header
+
+	r       *bufio.Reader
+	pos     int64 // the offset of r in the wxapkg file
+	entries []Entry
+	next    int
+	body    io.Reader // the body of the current entry
+
+	shared       []byte // the body shared by the next entries at the same offset
+	sharedOffset int64
+}
+
+// NewIterator reads the file index from r and returns an Iterator over the entries.
+func NewIterator(r io.Reader) (*Iterator, error) {
+	var br = bufio.NewReader(r)
+	header, entries, err := readIndex(br, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	var bodyStart = HeaderSize + IndexLength(entries)
+	var bodies = entries[:0]
+	for _, e := range entries {
+		if !e.IsPlaceholder() {
+			bodies = append(bodies, e)
+		}
+	}
+	entries = bodies
+
+	// the largest of the entries sharing a body is read first, the others are its prefixes
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Offset != entries[j].Offset {
+			return entries[i].Offset < entries[j].Offset
+		}
+		return entries[i].Size > entries[j].Size
+	})
+
+	return &Iterator{
+		Header:  header,
+		r:       br,
+		pos:     bodyStart,
+		entries: entries,
+		body:    eofReader{},
+	}, nil
+}
+
+// Next advances to the next entry, the body is read by Read. io.EOF is returned at the end.
+func (it *Iterator) Next() (*Entry, error) {
+	// skip the unread body of the current entry
+	if _, err := io.Copy(io.Discard, it.body); err != nil {
+		return nil, err
+	}
+
+	if it.next >= len(it.entries) {
+		return nil, io.EOF
+	}
+	var e = it.entries[it.next]
+	it.next++
+
+	if int64(e.Offset) < it.pos {
+		if it.shared == nil || int64(e.Offset) != it.sharedOffset || int(e.Size) > len(it.shared) {
+			return nil, fmt.Errorf("%w: %s overlaps with the previous entry", ErrIndex, e.Name)
+		}
+		it.body = bytes.NewReader(it.shared[:e.Size])
+		return &e, nil
+	}
+	if _, err := io.CopyN(io.Discard, it.r, int64(e.Offset)-it.pos); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF // not the end of the entries
+		}
+		return nil, err
+	}
+	it.pos = int64(e.Offset)
+
+	var body = &bodyReader{it: it, remaining: int64(e.Size)}
+	it.shared = nil
+	if it.next < len(it.entries) && it.entries[it.next].Offset == e.Offset {
+		body.keep = make([]byte, 0, e.Size)
+		it.sharedOffset = int64(e.Offset)
+	}
+	it.body = body
+
+	return &e, nil
+}
+
+// Read reads the body of the current entry.
+func (it *Iterator) Read(p []byte) (int, error) {
+	return it.body.Read(p)
+}
+
+type bodyReader struct {
+	it        *Iterator
+	remaining int64
+	keep      []byte // the body read so far if it's shared with the next entries
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+
+	n, err := b.it.r.Read(p)
+	b.remaining -= int64(n)
+	b.it.pos += int64(n)
+	if b.keep != nil {
+		b.keep = append(b.keep, p[:n]...)
+		if b.remaining == 0 {
+			b.it.shared = b.keep
+		}
+	}
+	if err == io.EOF && b.remaining > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return n, err
+}
+
+type eofReader struct{}
+
+func (eofReader) Read([]byte) (int, error) { return 0, io.EOF }
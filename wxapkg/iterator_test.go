@@ -0,0 +1,58 @@
+package wxapkg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+func TestIteratorSharedBody(t *testing.T) {
+	var p Packer
+	var shared = p.AddBody([]byte("shared body"))
+	var empty = p.AddBody(nil)
+	p.AddEntry("/a.js", shared)
+	p.AddEntry("/empty.txt", empty)
+	p.AddEntry("/b.js", shared)
+	p.AddEntry("/c.js", p.AddBody([]byte("c")))
+	p.AddEntry("/dir/", empty)
+
+	var buf bytes.Buffer
+	if _, err := p.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// the folder is the placeholder with the offset 0 and the size 0
+	var data = buf.Bytes()
+	var name = bytes.Index(data, []byte("/dir/"))
+	binary.BigEndian.PutUint32(data[name+len("/dir/"):], 0)
+
+	it, err := NewIterator(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got = make(map[string]string)
+	for {
+		e, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(it)
+		if err != nil {
+			t.Fatalf("%s: %v", e.Name, err)
+		}
+		got[e.Name] = string(data)
+	}
+
+	var want = map[string]string{"/a.js": "shared body", "/b.js": "shared body", "/empty.txt": "", "/c.js": "c"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d: %v", len(got), len(want), got)
+	}
+	for name, body := range want {
+		if got[name] != body {
+			t.Errorf("%s: got %q, want %q", name, got[name], body)
+		}
+	}
+}
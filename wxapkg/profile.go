@@ -0,0 +1,77 @@
+package wxapkg
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrProfile is returned when the profile is not known.
+var ErrProfile = errors.New("wxapkg: unknown profile")
+
+// Profile is how the wxapkg files are encrypted by the WeChat builds. The head of the encrypted file is
+// the Mark and the AES-256-CBC encrypted 1024 bytes, with the key derived from the wxid by PBKDF2-SHA1,
+// and the rest are xor encrypted with the second last byte of the wxid.
+type Profile struct {
+	Name       string
+	Mark       []byte // nil if the files are not encrypted
+	Salt       string
+	IV         string
+	Iterations int
+}
+
+// Profiles are the known profiles, a new format is supported by adding a profile.
+var Profiles = []*Profile{
+	{Name: "windows-3.x", Mark: encryptedMark, Salt: "saltiest", IV: "the iv: 16 bytes", Iterations: 1000},
+	{Name: "mac-3.8"},
+}
+
+// ProfileAliases are the other names of the profiles, alias -> the name of the profile.
+var ProfileAliases = map[string]string{
+	"windows-4.x": "windows-3.x", // WeChat 4.x keeps the format of 3.x
+}
+
+// DefaultProfile is the profile used by Decrypt, DecryptReader and NewDecrypter.
+var DefaultProfile = Profiles[0]
+
+// LookupProfile returns the profile with the name or the alias, see ProfileAliases.
+func LookupProfile(name string) (*Profile, error) {
+	if alias, ok := ProfileAliases[name]; ok {
+		name = alias
+	}
+	for _, p := range Profiles {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+
+	return nil, ErrProfile
+}
+
+// DetectProfile returns the first profile matching the head of the wxapkg file, the profile of the
+// plaintext files if none matches.
+func DetectProfile(head []byte) *Profile {
+	var plain *Profile
+	for _, p := range Profiles {
+		if !p.Encrypted() {
+			if plain == nil {
+				plain = p
+			}
+			continue
+		}
+		if bytes.HasPrefix(head, p.Mark) {
+			return p
+		}
+	}
+
+	return plain
+}
+
+// Encrypted reports whether the files of the profile are encrypted.
+func (p *Profile) Encrypted() bool {
+	return len(p.Mark) > 0
+}
+
+// headSize returns the size of the encrypted head.
+func (p *Profile) headSize() int {
+	return len(p.Mark) + 1024
+}
@@ -0,0 +1,179 @@
+package wxapkg
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sync"
+)
+
+// Reader reads the entries of a decrypted wxapkg file, like zip.Reader.
+type Reader struct {
+	Header *Header
+	File   []*File
+
+	r    io.ReaderAt
+	size int64
+
+	fsOnce sync.Once
+	fs     *FS
+}
+
+// ReadCloser is a Reader that must be closed when no longer needed.
+type ReadCloser struct {
+	Reader
+	f *os.File
+}
+
+// File is an entry in the wxapkg file, the body is read when opened.
+type File struct {
+	Entry
+
+	r    io.ReaderAt
+	size int64 // the size of the wxapkg file
+}
+
+var regWxid = regexp.MustCompile(`wx[0-9a-f]{16}`)
+
+// OpenReader opens the wxapkg file, the encrypted one is decrypted with the wxid in the path, like
+// '.../Applet/wx1234567890abcdef/12/__APP__.wxapkg'.
+func OpenReader(name string) (*ReadCloser, error) {
+	return openReader(name, regWxid.FindString(name))
+}
+
+func openReader(name, wxid string) (*ReadCloser, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	var r io.ReaderAt = f
+	var size = info.Size()
+	var head = make([]byte, len(encryptedMark))
+	if _, err := f.ReadAt(head, 0); err == nil && IsEncrypted(head) {
+		d, err := DetectProfile(head).NewDecrypter(f, size, wxid)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		r, size = d, d.Size()
+	}
+
+	var rc = &ReadCloser{f: f}
+	if err := rc.init(r, size); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return rc, nil
+}
+
+// Close closes the wxapkg file.
+func (rc *ReadCloser) Close() error {
+	return rc.f.Close()
+}
+
+// NewReader returns a Reader reading the decrypted wxapkg file from r, which has the given size.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	var reader = &Reader{}
+	if err := reader.init(r, size); err != nil {
+		return nil, err
+	}
+
+	return reader, nil
+}
+
+func (r *Reader) init(rd io.ReaderAt, size int64) error {
+	header, entries, err := ReadIndex(rd, size)
+	if err != nil {
+		return err
+	}
+
+	r.Header, r.r, r.size = header, rd, size
+	r.File = make([]*File, len(entries))
+	for i, e := range entries {
+		r.File[i] = &File{Entry: e, r: rd, size: size}
+	}
+
+	return nil
+}
+
+// Open opens the named file with the fs.FS semantics, see FS.
+func (r *Reader) Open(name string) (fs.File, error) {
+	r.fsOnce.Do(func() {
+		r.fs = newFS(r)
+	})
+
+	return r.fs.Open(name)
+}
+
+// Open returns a reader of the body.
+func (f *File) Open() (io.ReadCloser, error) {
+	r, err := f.section()
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(r), nil
+}
+
+func (f *File) section() (*io.SectionReader, error) {
+	if int64(f.Offset)+int64(f.Size) > f.size {
+		return nil, ErrIndex
+	}
+
+	return io.NewSectionReader(f.r, int64(f.Offset), int64(f.Size)), nil
+}
+
+// FileInfo returns the fs.FileInfo of the file.
+func (f *File) FileInfo() fs.FileInfo {
+	return &fileInfo{name: path.Base(f.Name), size: int64(f.Size)}
+}
+
+// Walk reads the files into memory one by one in the index order and calls fn with the body, the
+// walk stops at the first error returned by fn.
+func (r *Reader) Walk(fn func(f *File, data []byte) error) error {
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+
+		if err := fn(f, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadAll reads all the files into memory without writing to disk, name -> body. The first one
+// wins if the names are duplicate.
+func (r *Reader) ReadAll() (map[string][]byte, error) {
+	var files = make(map[string][]byte, len(r.File))
+	err := r.Walk(func(f *File, data []byte) error {
+		if _, ok := files[f.Name]; !ok {
+			files[f.Name] = data
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
@@ -0,0 +1,76 @@
+package wxapkg
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testWxid = "wx0123456789abcdef"
+
+func TestEncryptRoundTrip(t *testing.T) {
+	var data = buildPackage(t, testFiles)
+	encrypted, err := Encrypt(testWxid, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(encrypted) {
+		t.Fatal("the encrypted package has no mark")
+	}
+
+	decrypted, err := Decrypt(testWxid, encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, data) {
+		t.Fatal("Decrypt doesn't restore the encrypted package")
+	}
+
+	stream, err := DecryptReader(bytes.NewReader(encrypted), testWxid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if streamed, err := io.ReadAll(stream); err != nil || !bytes.Equal(streamed, data) {
+		t.Fatalf("DecryptReader doesn't restore the encrypted package: %v", err)
+	}
+
+	if _, err := Decrypt("wxfedcba9876543210", encrypted); err != ErrWxid {
+		t.Fatalf("got %v with the wrong wxid, want ErrWxid", err)
+	}
+}
+
+func TestOpenReaderEncrypted(t *testing.T) {
+	encrypted, err := Encrypt(testWxid, buildPackage(t, testFiles))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var name = filepath.Join(t.TempDir(), testWxid, "1", "__APP__.wxapkg")
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, encrypted, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the wxid is detected from the path
+	rc, err := OpenReader(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+
+	files, err := rc.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(testFiles) {
+		t.Fatalf("got %d files, want %d", len(files), len(testFiles))
+	}
+	for name, body := range testFiles {
+		if string(files[name]) != body {
+			t.Errorf("%s: got %d bytes, want %d", name, len(files[name]), len(body))
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package wxapkg
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// ErrTooLarge is returned when the packed file exceeds the 4 GB limit of the 32-bit offsets.
+var ErrTooLarge = errors.New("wxapkg: the packed file is too large")
+
+// Packer builds a decrypted wxapkg file, the entries are indexed in the order added and the bodies are laid
+// out in the order added, so the layout of an existing file can be kept. The entries may share a body.
+type Packer struct {
+	Info1 uint32
+
+	names  []string
+	refs   []int // the body of every entry
+	bodies [][]byte
+}
+
+// AddBody adds the body to the end of the layout, returns the id of the body for AddEntry.
+func (p *Packer) AddBody(data []byte) int {
+	p.bodies = append(p.bodies, data)
+
+	return len(p.bodies) - 1
+}
+
+// AddEntry adds the entry of the body to the end of the index, the name is an absolute path like
+// '/app-service.js'.
+func (p *Packer) AddEntry(name string, body int) {
+	p.names = append(p.names, name)
+	p.refs = append(p.refs, body)
+}
+
+// Entries returns the entries in the index with the offsets and the sizes in the packed file.
+func (p *Packer) Entries() ([]Entry, error) {
+	var entries = make([]Entry, len(p.names))
+	for i, name := range p.names {
+		entries[i].Name = name
+	}
+
+	var offset = int64(HeaderSize) + IndexLength(entries)
+	var offsets = make([]int64, len(p.bodies))
+	for i, body := range p.bodies {
+		offsets[i] = offset
+		offset += int64(len(body))
+	}
+	if offset > math.MaxUint32 {
+		return nil, ErrTooLarge
+	}
+
+	for i, ref := range p.refs {
+		entries[i].Offset = uint32(offsets[ref])
+		entries[i].Size = uint32(len(p.bodies[ref]))
+	}
+
+	return entries, nil
+}
+
+// WriteTo writes the decrypted wxapkg file to w.
+func (p *Packer) WriteTo(w io.Writer) (int64, error) {
+	entries, err := p.Entries()
+	if err != nil {
+		return 0, err
+	}
+
+	var bodyLength int64
+	for _, body := range p.bodies {
+		bodyLength += int64(len(body))
+	}
+
+	var cw = &countWriter{w: w}
+	var bw = bufio.NewWriter(cw)
+	var write = func(v interface{}) {
+		_ = binary.Write(bw, binary.BigEndian, v)
+	}
+
+	write(uint8(0xBE))
+	write(p.Info1)
+	write(uint32(IndexLength(entries)))
+	write(uint32(bodyLength))
+	write(uint8(0xED))
+	write(uint32(len(entries)))
+	for _, e := range entries {
+		write(uint32(len(e.Name)))
+		_, _ = bw.WriteString(e.Name)
+		write(e.Offset)
+		write(e.Size)
+	}
+	for _, body := range p.bodies {
+		_, _ = bw.Write(body)
+	}
+
+	err = bw.Flush()
+
+	return cw.n, err
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,119 @@
+// Package wxapkg reads the wxapkg files of the wechat mini programs.
+package wxapkg
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+)
+
+// HeaderSize is the size of the header before the file index.
+const HeaderSize = 1 + 4 + 4 + 4 + 1
+
+var (
+	// ErrFormat is returned when the data is not a (decrypted) wxapkg file.
+	ErrFormat = errors.New("wxapkg: not a valid wxapkg file")
+	// ErrIndex is returned when the file index is broken.
+	ErrIndex = errors.New("wxapkg: invalid file index")
+)
+
+type Header struct {
+	Info1           uint32 // 0 in all the known builds, maybe the format version or flags
+	IndexInfoLength uint32
+	BodyInfoLength  uint32
+	FileCount       uint32
+}
+
+// Entry is a file in the wxapkg file, the name is an absolute path like '/app-service.js'.
+type Entry struct {
+	Name   string
+	Offset uint32
+	Size   uint32
+}
+
+// IsPlaceholder reports whether the entry is a placeholder without a body, like the folders and the entries
+// with the offset 0 and the size 0, which point into the header. The other empty entries are empty files.
+func (e Entry) IsPlaceholder() bool {
+	return e.Size == 0 && (e.Offset == 0 || strings.HasSuffix(e.Name, "/"))
+}
+
+// KnownInfo1 reports whether Info1 is the value written by the known builds, an unknown value means the
+// format may be changed.
+func (h *Header) KnownInfo1() bool {
+	return h.Info1 == 0
+}
+
+// ReadIndex reads the header and the file index of the decrypted wxapkg file, the header is returned with
+// ErrIndex if only the index is broken.
+func ReadIndex(r io.ReaderAt, size int64) (*Header, []Entry, error) {
+	return readIndex(bufio.NewReader(io.NewSectionReader(r, 0, size)), size)
+}
+
+// readIndex reads the header and the file index from f, the size is unknown (-1) if f is a stream.
+func readIndex(f io.Reader, size int64) (*Header, []Entry, error) {
+	// Read header
+	var (
+		header    Header
+		firstMark uint8
+		lastMark  uint8
+	)
+	_ = binary.Read(f, binary.BigEndian, &firstMark)
+	_ = binary.Read(f, binary.BigEndian, &header.Info1)
+	_ = binary.Read(f, binary.BigEndian, &header.IndexInfoLength)
+	_ = binary.Read(f, binary.BigEndian, &header.BodyInfoLength)
+	_ = binary.Read(f, binary.BigEndian, &lastMark)
+
+	if firstMark != 0xBE || lastMark != 0xED {
+		return nil, nil, ErrFormat
+	}
+
+	if err := binary.Read(f, binary.BigEndian, &header.FileCount); err != nil {
+		return &header, nil, ErrIndex
+	}
+	if size >= 0 && int64(header.FileCount)*12 > size { // 12 bytes at least for every file
+		return &header, nil, ErrIndex
+	}
+
+	// Read index, the entries are appended in case of a broken count in the stream
+	var entries = make([]Entry, 0, minInt(int(header.FileCount), 1024))
+	for i := uint32(0); i < header.FileCount; i++ {
+		var e Entry
+		var nameLen uint32
+		_ = binary.Read(f, binary.BigEndian, &nameLen)
+
+		if nameLen > 10<<20 { // 10 MB
+			return &header, nil, ErrIndex
+		}
+
+		var name = make([]byte, nameLen)
+		_, _ = io.ReadAtLeast(f, name, int(nameLen))
+		e.Name = string(name)
+		_ = binary.Read(f, binary.BigEndian, &e.Offset)
+		if err := binary.Read(f, binary.BigEndian, &e.Size); err != nil {
+			return &header, nil, ErrIndex
+		}
+
+		entries = append(entries, e)
+	}
+
+	return &header, entries, nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// IndexLength returns the length of the file index, which should be equal to Header.IndexInfoLength.
+func IndexLength(entries []Entry) int64 {
+	var length int64 = 4
+	for _, e := range entries {
+		length += 4 + int64(len(e.Name)) + 4 + 4
+	}
+
+	return length
+}